@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/qs-lzh/movie-reservation/config"
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/grpcapi"
+	"github.com/qs-lzh/movie-reservation/internal/router"
+)
+
+// requests in flight get this long to finish after SIGTERM
+const shutdownTimeout = 15 * time.Second
+
+func main() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+
+	if err := run(logger); err != nil {
+		logger.Fatal("server stopped", zap.Error(err))
+	}
+}
+
+func run(logger *zap.Logger) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	// ctx lives as long as the process, the identity providers use it to fetch their keys
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	application, err := app.New(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer application.Close()
+
+	gin.SetMode(gin.ReleaseMode)
+	addr := cfg.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+	// the seat event streams only end when their clients leave,
+	// so their requests are cancelled when the server shuts down
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           router.New(application),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return requestCtx },
+	}
+	server.RegisterOnShutdown(cancelRequests)
+
+	tls := cfg.CertPath != "" && cfg.KeyPath != ""
+	var grpcOptions []grpc.ServerOption
+	if tls {
+		creds, err := credentials.NewServerTLSFromFile(cfg.CertPath, cfg.KeyPath)
+		if err != nil {
+			return err
+		}
+		grpcOptions = append(grpcOptions, grpc.Creds(creds))
+	}
+	grpcAddr := cfg.GRPCAddr
+	if grpcAddr == "" {
+		grpcAddr = ":9090"
+	}
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpcapi.New(application, grpcOptions...)
+
+	serveErr := make(chan error, 2)
+	go func() {
+		logger.Info("server listening", zap.String("addr", addr), zap.Bool("tls", tls))
+		if tls {
+			serveErr <- server.ListenAndServeTLS(cfg.CertPath, cfg.KeyPath)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+	go func() {
+		logger.Info("grpc server listening", zap.String("addr", grpcAddr), zap.Bool("tls", tls))
+		serveErr <- grpcServer.Serve(grpcListener)
+	}()
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			runErr = err
+		}
+	case <-ctx.Done():
+	}
+
+	logger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// the seat availability streams only end when their clients leave,
+	// so the gRPC server is stopped hard once the timeout is over
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	if err := server.Shutdown(shutdownCtx); err != nil && runErr == nil {
+		runErr = err
+	}
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+	return runErr
+}
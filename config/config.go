@@ -1,19 +1,81 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/qs-lzh/movie-reservation/internal/model"
 	"github.com/qs-lzh/movie-reservation/internal/util"
 )
 
 type Config struct {
 	DatabaseDSN       string
 	Addr              string
+	GRPCAddr          string
 	JWTSecretKey      string
+	JWTKeyID          string
+	JWTPreviousKeys   string
 	CertPath          string
 	KeyPath           string
 	CacheURL          string
-	AdminRolePassword string
+	BootstrapAdmin    string
+	BootstrapEmail    string
+	PublicURL         string
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUsername      string
+	SMTPPassword      string
+	MailFrom          string
+	MailOutboxDir     string
+	GuestCodeSecret   string
+	WaitingRoomSecret string
+	OIDCProviders     []OIDCProvider
+	TOTPEncryptionKey string
+	TwoFactorRoles    []model.UserRole
+	RateLimits        map[string]RateLimit
+	TrustedProxies    []string
+	CacheTTLs         map[string]time.Duration
+}
+
+// RateLimit lets Burst requests through at once, and Requests more every Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// defaultRateLimits are the limits of the route classes of the router,
+// for each API key, signed-in user or anonymous client IP.
+// credentials are the requests with a token or an API key for each client IP,
+// counted before they are checked
+var defaultRateLimits = map[string]RateLimit{
+	"read":        {Requests: 20, Per: time.Second, Burst: 40},
+	"write":       {Requests: 5, Per: time.Second, Burst: 10},
+	"auth":        {Requests: 10, Per: time.Minute, Burst: 10},
+	"booking":     {Requests: 10, Per: time.Minute, Burst: 5},
+	"credentials": {Requests: 50, Per: time.Second, Burst: 100},
+}
+
+// defaultCacheTTLs are how long the responses of the catalogue reads are cached,
+// changes invalidate them earlier
+var defaultCacheTTLs = map[string]time.Duration{
+	"movies":    10 * time.Minute,
+	"halls":     10 * time.Minute,
+	"showtimes": time.Minute,
+}
+
+// OIDCProvider is an OpenID Connect provider users can sign in with
+type OIDCProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
 }
 
 func LoadConfig() (*Config, error) {
@@ -22,18 +84,222 @@ func LoadConfig() (*Config, error) {
 	}
 	databaseDSN := os.Getenv("DATABASE_DSN")
 	addr := os.Getenv("ADDR")
+	// the gRPC server listens on GRPC_ADDR, with the same TLS certificate as ADDR
+	grpcAddr := os.Getenv("GRPC_ADDR")
 	jwtSecretKey := os.Getenv("JWT_SECRET_KEY")
+	// kid of JWT_SECRET_KEY, and "kid:secret,kid:secret" of retired keys
+	// still accepted for verification while their tokens expire
+	jwtKeyID := os.Getenv("JWT_KEY_ID")
+	jwtPreviousKeys := os.Getenv("JWT_PREVIOUS_KEYS")
 	crtPath := os.Getenv("CERT_PATH")
 	keyPath := os.Getenv("KEY_PATH")
 	cacheURL := os.Getenv("CACHE_URL")
-	adminRolePassword := os.Getenv("ADMIN_ROLE_PASSWORD")
+	// name of the user promoted to admin at startup while there is no admin,
+	// the user must have verified BOOTSTRAP_ADMIN_EMAIL, so whoever registers
+	// the name of a deleted account can't take it over
+	bootstrapAdmin := os.Getenv("BOOTSTRAP_ADMIN")
+	bootstrapEmail := strings.ToLower(strings.TrimSpace(os.Getenv("BOOTSTRAP_ADMIN_EMAIL")))
+	if bootstrapAdmin != "" && bootstrapEmail == "" {
+		return nil, errors.New("BOOTSTRAP_ADMIN needs BOOTSTRAP_ADMIN_EMAIL")
+	}
+	// base URL of the links sent by email
+	publicURL := os.Getenv("PUBLIC_URL")
+	// mails go through SMTP_HOST, or are written to MAIL_OUTBOX_DIR when it is unset
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPort := 587
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		var err error
+		if smtpPort, err = strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT %q: %w", port, err)
+		}
+	}
+	smtpUsername := os.Getenv("SMTP_USERNAME")
+	smtpPassword := os.Getenv("SMTP_PASSWORD")
+	mailFrom := os.Getenv("MAIL_FROM")
+	mailOutboxDir := os.Getenv("MAIL_OUTBOX_DIR")
+	// signs the lookup codes of guest reservations
+	guestCodeSecret := os.Getenv("GUEST_CODE_SECRET")
+	// signs the admission tokens of waiting rooms, a key of its own so the tokens
+	// of one kind can't be passed off as the other
+	waitingRoomSecret := os.Getenv("WAITING_ROOM_SECRET")
+	// OIDC_PROVIDERS is a comma separated list of names,
+	// each configured with OIDC_<NAME>_ISSUER_URL, _CLIENT_ID, _CLIENT_SECRET and _REDIRECT_URL
+	var oidcProviders []OIDCProvider
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProvider{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER_URL"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+		if provider.IssuerURL == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			return nil, fmt.Errorf("identity provider %q needs %sISSUER_URL, %sCLIENT_ID and %sREDIRECT_URL",
+				name, prefix, prefix, prefix)
+		}
+		oidcProviders = append(oidcProviders, provider)
+	}
+	// base64 of the 32 byte key encrypting the TOTP secrets
+	totpEncryptionKey := os.Getenv("TOTP_ENCRYPTION_KEY")
+	// roles that only get their permissions with two-factor authentication,
+	// an empty TWO_FACTOR_ROLES requires it of no one
+	twoFactorRoles := []model.UserRole{model.RoleAdmin}
+	if roles, ok := os.LookupEnv("TWO_FACTOR_ROLES"); ok {
+		twoFactorRoles = nil
+		for _, role := range strings.Split(roles, ",") {
+			role = strings.TrimSpace(role)
+			if role == "" {
+				continue
+			}
+			if !model.UserRole(role).IsValid() {
+				return nil, fmt.Errorf("unknown role %q in TWO_FACTOR_ROLES, want one of %v", role, model.Roles())
+			}
+			twoFactorRoles = append(twoFactorRoles, model.UserRole(role))
+		}
+	}
+	// without the key nobody can enroll, so the roles would keep their permissions unprotected
+	if len(twoFactorRoles) != 0 && totpEncryptionKey == "" {
+		return nil, errors.New("TWO_FACTOR_ROLES needs TOTP_ENCRYPTION_KEY, set TWO_FACTOR_ROLES empty to disable two-factor authentication")
+	}
+	// RATE_LIMITS overrides the limits of route classes, e.g. "booking=5/1m:3,read=off",
+	// a limit is <requests>/<duration>[:<burst>], the burst defaults to the requests
+	rateLimits, err := parseRateLimits(os.Getenv("RATE_LIMITS"))
+	if err != nil {
+		return nil, err
+	}
+	// client IPs are only read from X-Forwarded-For behind TRUSTED_PROXIES, a comma separated
+	// list of IPs and CIDRs, otherwise clients could pick the IP their requests are limited by
+	var trustedProxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q, want an IP or a CIDR", proxy)
+		}
+		trustedProxies = append(trustedProxies, proxy)
+	}
+	// CACHE_TTLS overrides how long the catalogue responses are cached, e.g. "movies=1h,showtimes=off"
+	cacheTTLs, err := parseCacheTTLs(os.Getenv("CACHE_TTLS"))
+	if err != nil {
+		return nil, err
+	}
 	return &Config{
 		DatabaseDSN:       databaseDSN,
 		Addr:              addr,
+		GRPCAddr:          grpcAddr,
 		JWTSecretKey:      jwtSecretKey,
+		JWTKeyID:          jwtKeyID,
+		JWTPreviousKeys:   jwtPreviousKeys,
 		CertPath:          crtPath,
 		KeyPath:           keyPath,
 		CacheURL:          cacheURL,
-		AdminRolePassword: adminRolePassword,
+		BootstrapAdmin:    bootstrapAdmin,
+		BootstrapEmail:    bootstrapEmail,
+		PublicURL:         publicURL,
+		SMTPHost:          smtpHost,
+		SMTPPort:          smtpPort,
+		SMTPUsername:      smtpUsername,
+		SMTPPassword:      smtpPassword,
+		MailFrom:          mailFrom,
+		MailOutboxDir:     mailOutboxDir,
+		GuestCodeSecret:   guestCodeSecret,
+		WaitingRoomSecret: waitingRoomSecret,
+		OIDCProviders:     oidcProviders,
+		TOTPEncryptionKey: totpEncryptionKey,
+		TwoFactorRoles:    twoFactorRoles,
+		RateLimits:        rateLimits,
+		TrustedProxies:    trustedProxies,
+		CacheTTLs:         cacheTTLs,
 	}, nil
 }
+
+func parseRateLimits(value string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit, len(defaultRateLimits))
+	for class, limit := range defaultRateLimits {
+		limits[class] = limit
+	}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		class, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q, want <class>=<limit>", entry)
+		}
+		class = strings.TrimSpace(class)
+		if _, ok := defaultRateLimits[class]; !ok {
+			return nil, fmt.Errorf("unknown class %q in RATE_LIMITS", class)
+		}
+		if spec == "off" {
+			delete(limits, class)
+			continue
+		}
+		limit, err := parseRateLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMITS entry %q: %w", entry, err)
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
+
+func parseCacheTTLs(value string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration, len(defaultCacheTTLs))
+	for entity, ttl := range defaultCacheTTLs {
+		ttls[entity] = ttl
+	}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		entity, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid CACHE_TTLS entry %q, want <entity>=<duration>", entry)
+		}
+		entity = strings.TrimSpace(entity)
+		if _, ok := defaultCacheTTLs[entity]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in CACHE_TTLS", entity)
+		}
+		if spec == "off" {
+			delete(ttls, entity)
+			continue
+		}
+		ttl, err := time.ParseDuration(spec)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid CACHE_TTLS entry %q: duration must be positive, like 30s or 10m", entry)
+		}
+		ttls[entity] = ttl
+	}
+	return ttls, nil
+}
+
+func parseRateLimit(spec string) (RateLimit, error) {
+	rate, burst, hasBurst := strings.Cut(spec, ":")
+	requests, per, ok := strings.Cut(rate, "/")
+	if !ok {
+		return RateLimit{}, errors.New("want <requests>/<duration>[:<burst>]")
+	}
+	var limit RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return RateLimit{}, errors.New("requests must be a positive integer")
+	}
+	if limit.Per, err = time.ParseDuration(per); err != nil || limit.Per <= 0 {
+		return RateLimit{}, errors.New("duration must be positive, like 1s or 1m")
+	}
+	limit.Burst = limit.Requests
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+			return RateLimit{}, errors.New("burst must be a positive integer")
+		}
+	}
+	return limit, nil
+}
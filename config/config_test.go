@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestTwoFactorRoles(t *testing.T) {
+	const key = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+	t.Run("admins by default", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", key)
+		cfg, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserRole{model.RoleAdmin}, cfg.TwoFactorRoles)
+	})
+
+	t.Run("listed roles", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", key)
+		t.Setenv("TWO_FACTOR_ROLES", " admin, finance,")
+		cfg, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserRole{model.RoleAdmin, model.RoleFinance}, cfg.TwoFactorRoles)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", key)
+		t.Setenv("TWO_FACTOR_ROLES", "admin,Admin")
+		_, err := LoadConfig()
+		assert.ErrorContains(t, err, `unknown role "Admin" in TWO_FACTOR_ROLES`)
+	})
+
+	t.Run("roles without a key", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", "")
+		_, err := LoadConfig()
+		assert.ErrorContains(t, err, "TWO_FACTOR_ROLES needs TOTP_ENCRYPTION_KEY")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv("TOTP_ENCRYPTION_KEY", "")
+		t.Setenv("TWO_FACTOR_ROLES", "")
+		cfg, err := LoadConfig()
+		require.NoError(t, err)
+		assert.Empty(t, cfg.TwoFactorRoles)
+	})
+}
@@ -0,0 +1,261 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/config"
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/mail"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// App holds the configuration, connections and services shared by the handlers,
+// Logger is for the business-level logs of the handlers
+type App struct {
+	Config *config.Config
+	DB     *gorm.DB
+	Cache  *cache.RedisCache
+	Logger *zap.Logger
+
+	UserService        service.UserService
+	AccountService     service.AccountService
+	IdentityService    service.IdentityService
+	APIKeyService      service.APIKeyService
+	CaptchaService     service.CaptchaService
+	CinemaService      service.CinemaService
+	HallService        service.HallService
+	MovieService       service.MovieService
+	ShowtimeService    service.ShowtimeService
+	MaintenanceService service.MaintenanceService
+	ReservationService service.ReservationService
+	WaitingRoomService service.WaitingRoomService
+	// tells the watchers of seat availability about bookings and blocks
+	SeatEvents service.SeatEvents
+	// the responses of the booking requests retried with an Idempotency-Key
+	IdempotencyStore service.IdempotencyStore
+	RateLimiter      service.RateLimiter
+	// the limits of the route classes of the router, classes without one are not limited
+	RateLimits map[string]service.RateLimit
+	// the responses of the catalogue reads, kept for the TTL of their entity,
+	// entities without a TTL are not cached
+	CatalogCache service.CatalogCache
+	CacheTTLs    map[service.CatalogEntity]time.Duration
+	// nil when TOTP_ENCRYPTION_KEY is unset
+	TwoFactorService service.TwoFactorService
+	TokenService     auth.TokenService
+	// nil when no identity provider is configured
+	OIDCService auth.OIDCService
+}
+
+// the catalogue responses are also kept in process, saving the round trips
+// to redis for the hot reads, the versions they are read under are always in redis
+const (
+	catalogLocalEntries = 10000
+	catalogLocalTTL     = 30 * time.Second
+)
+
+// New connects to postgres and redis, migrates the schema and wires the services
+func New(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*App, error) {
+	if cfg.GuestCodeSecret == "" {
+		return nil, errors.New("GUEST_CODE_SECRET is required to sign the lookup codes of guests")
+	}
+	if cfg.WaitingRoomSecret == "" {
+		return nil, errors.New("WAITING_ROOM_SECRET is required to sign the admission tokens of waiting rooms")
+	}
+	keyring, err := auth.NewKeyring(cfg.JWTKeyID, cfg.JWTSecretKey, cfg.JWTPreviousKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseDSN), &gorm.Config{
+		// the services tell a violated unique index by gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		return nil, fmt.Errorf("migrate database: %w", err)
+	}
+
+	redisCache := cache.NewRedisCache(cfg.CacheURL)
+	if err := redisCache.Ping(); err != nil {
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+
+	mailer, err := newMailer(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	userRepo := repository.NewUserRepoGorm(db)
+	userTokenRepo := repository.NewUserTokenRepoGorm(db)
+	userIdentityRepo := repository.NewUserIdentityRepoGorm(db)
+	apiKeyRepo := repository.NewAPIKeyRepoGorm(db)
+	twoFactorRepo := repository.NewTwoFactorRepoGorm(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepoGorm(db)
+	cinemaRepo := repository.NewCinemaRepoGorm(db)
+	hallRepo := repository.NewHallRepoGorm(db)
+	movieRepo := repository.NewMovieRepoGorm(db)
+	showtimeRepo := repository.NewShowtimeRepoGorm(db)
+	reservationRepo := repository.NewReservationRepoGorm(db)
+	seatBlockRepo := repository.NewSeatBlockRepoGorm(db)
+	maintenanceRepo := repository.NewHallMaintenanceRepoGorm(db)
+
+	attempts := service.NewRedisAttemptStore(redisCache, service.NewMemoryAttemptStore())
+	captchaService, err := service.NewSlideCaptchaService(service.NewRedisChallengeStore(redisCache))
+	if err != nil {
+		return nil, err
+	}
+
+	catalogResponses := cache.NewTwoTier(cache.NewLRU(catalogLocalEntries), redisCache, catalogLocalTTL)
+
+	a := &App{
+		Config:         cfg,
+		DB:             db,
+		Cache:          redisCache,
+		Logger:         logger,
+		CaptchaService: captchaService,
+		SeatEvents:     service.NewRedisSeatEvents(redisCache),
+		IdempotencyStore: service.NewRedisIdempotencyStore(redisCache,
+			service.NewMemoryIdempotencyStore()),
+		RateLimiter:  service.NewRedisRateLimiter(redisCache, service.NewMemoryRateLimiter()),
+		RateLimits:   make(map[string]service.RateLimit, len(cfg.RateLimits)),
+		CatalogCache: service.NewCatalogCache(catalogResponses, redisCache),
+		CacheTTLs:    make(map[service.CatalogEntity]time.Duration, len(cfg.CacheTTLs)),
+	}
+	for class, limit := range cfg.RateLimits {
+		a.RateLimits[class] = service.RateLimit{
+			Requests: limit.Requests,
+			Per:      limit.Per,
+			Burst:    limit.Burst,
+		}
+	}
+	for entity, ttl := range cfg.CacheTTLs {
+		a.CacheTTLs[service.CatalogEntity(entity)] = ttl
+	}
+	// the services changing passwords end the sessions the token service issued
+	sessions := auth.NewRedisSessionStore(redisCache)
+	a.UserService = service.NewUserService(db, userRepo, reservationRepo,
+		service.NewLoginThrottle(attempts), captchaService, sessions, a.SeatEvents)
+	a.AccountService = service.NewAccountService(db, userRepo, userTokenRepo, sessions, mailer, cfg.PublicURL)
+	a.IdentityService = service.NewIdentityService(db, userIdentityRepo, userRepo)
+	a.APIKeyService = service.NewAPIKeyService(db, apiKeyRepo, service.NewRedisRequestCounter(redisCache))
+	a.CinemaService = service.NewCinemaService(db, cinemaRepo, hallRepo)
+	a.ShowtimeService = service.NewShowtimeService(db, showtimeRepo, movieRepo, hallRepo, maintenanceRepo, a.CatalogCache)
+	a.HallService = service.NewHallService(db, hallRepo, cinemaRepo, a.ShowtimeService, a.CatalogCache)
+	a.MovieService = service.NewMovieService(db, movieRepo, a.ShowtimeService, a.CatalogCache)
+	a.MaintenanceService = service.NewMaintenanceService(db, seatBlockRepo, maintenanceRepo, hallRepo,
+		showtimeRepo, a.SeatEvents)
+	a.WaitingRoomService = service.NewWaitingRoomService(db, repository.NewWaitingRoomRepoGorm(db), showtimeRepo,
+		movieRepo, service.NewRedisWaitingQueue(redisCache, service.NewMemoryWaitingQueue()),
+		service.NewAdmissionTokens(cfg.WaitingRoomSecret), a.RateLimiter)
+	a.ReservationService = service.NewReservationService(db, reservationRepo, showtimeRepo, hallRepo,
+		seatBlockRepo, maintenanceRepo, userRepo, captchaService, service.NewLookupCodes(cfg.GuestCodeSecret),
+		a.SeatEvents, a.WaitingRoomService)
+
+	var twoFactor service.TwoFactorService
+	if cfg.TOTPEncryptionKey != "" {
+		box, err := service.NewSecretBox(cfg.TOTPEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY: %w", err)
+		}
+		twoFactor = service.NewTwoFactorService(db, twoFactorRepo, recoveryCodeRepo, userRepo, box, attempts,
+			cfg.TwoFactorRoles)
+		a.TwoFactorService = twoFactor
+	} else {
+		logger.Warn("TOTP_ENCRYPTION_KEY is unset, two-factor authentication is disabled")
+	}
+	tokenService := auth.NewTokenService(keyring, sessions, a.UserService, twoFactor)
+	a.TokenService = tokenService
+
+	if len(cfg.OIDCProviders) != 0 {
+		configs := make([]auth.OIDCProviderConfig, 0, len(cfg.OIDCProviders))
+		for _, provider := range cfg.OIDCProviders {
+			configs = append(configs, auth.OIDCProviderConfig{
+				Name:         provider.Name,
+				IssuerURL:    provider.IssuerURL,
+				ClientID:     provider.ClientID,
+				ClientSecret: provider.ClientSecret,
+				RedirectURL:  provider.RedirectURL,
+			})
+		}
+		oidcService, err := auth.NewOIDCService(ctx, configs, auth.NewRedisOIDCStateStore(redisCache),
+			a.IdentityService, tokenService)
+		if err != nil {
+			return nil, err
+		}
+		a.OIDCService = oidcService
+	}
+
+	if cfg.BootstrapAdmin != "" {
+		promoted, err := a.UserService.BootstrapAdmin(cfg.BootstrapAdmin, cfg.BootstrapEmail)
+		switch {
+		case errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrUserNotVerified):
+			// the admin may not have signed up yet, the next start tries again
+			logger.Warn("bootstrap admin not promoted", zap.String("name", cfg.BootstrapAdmin), zap.Error(err))
+		case err != nil:
+			return nil, fmt.Errorf("bootstrap admin: %w", err)
+		case promoted:
+			logger.Info("promoted the bootstrap admin", zap.String("name", cfg.BootstrapAdmin))
+		}
+	}
+	return a, nil
+}
+
+// Close releases the database and redis connections
+func (a *App) Close() error {
+	var errs []error
+	if sqlDB, err := a.DB.DB(); err != nil {
+		errs = append(errs, err)
+	} else if err := sqlDB.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := a.Cache.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&model.User{},
+		&model.UserToken{},
+		&model.UserIdentity{},
+		&model.APIKey{},
+		&model.TwoFactor{},
+		&model.RecoveryCode{},
+		&model.Cinema{},
+		&model.Hall{},
+		&model.Movie{},
+		&model.Showtime{},
+		&model.Reservation{},
+		&model.SeatBlock{},
+		&model.HallMaintenance{},
+		&model.WaitingRoom{},
+		&model.AdmissionUse{},
+	)
+}
+
+// newMailer sends through SMTP_HOST, or writes the mails to MAIL_OUTBOX_DIR,
+// without either they are only kept in memory
+func newMailer(cfg *config.Config, logger *zap.Logger) (mail.Mailer, error) {
+	if cfg.SMTPHost != "" {
+		return mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	}
+	if cfg.MailOutboxDir != "" {
+		return mail.NewFileOutbox(cfg.MailOutboxDir)
+	}
+	logger.Warn("neither SMTP_HOST nor MAIL_OUTBOX_DIR is set, mails are not delivered")
+	return mail.NewMemoryOutbox(), nil
+}
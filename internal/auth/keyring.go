@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultKeyID is the kid used when JWT_KEY_ID is not set
+const DefaultKeyID = "default"
+
+// Keyring holds the HMAC keys by kid, new tokens are signed with the current key
+// and tokens signed with any key of the ring are accepted,
+// so a key can be rotated without logging everybody out
+type Keyring struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewKeyring builds a keyring signing with currentSecret,
+// previous is the "kid:secret,kid:secret" list of retired keys
+func NewKeyring(currentID, currentSecret, previous string) (*Keyring, error) {
+	if currentID == "" {
+		currentID = DefaultKeyID
+	}
+	if currentSecret == "" {
+		return nil, errors.New("auth: the current JWT secret key is empty")
+	}
+
+	ring := &Keyring{
+		currentID: currentID,
+		keys:      map[string][]byte{currentID: []byte(currentSecret)},
+	}
+	for i, entry := range strings.Split(previous, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			// only the position, the entry may be a secret missing its kid
+			return nil, fmt.Errorf("auth: malformed previous JWT key, entry %d, want kid:secret", i+1)
+		}
+		if _, exists := ring.keys[id]; exists {
+			return nil, fmt.Errorf("auth: JWT key id %q is used twice", id)
+		}
+		ring.keys[id] = []byte(secret)
+	}
+	return ring, nil
+}
+
+func (k *Keyring) current() (string, []byte) {
+	return k.currentID, k.keys[k.currentID]
+}
+
+func (k *Keyring) lookup(id string) ([]byte, bool) {
+	key, ok := k.keys[id]
+	return key, ok
+}
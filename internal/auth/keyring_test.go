@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringAcceptsThePreviousKeys(t *testing.T) {
+	keyring, err := NewKeyring("", "current-secret", " old:old-secret , older:older-secret,")
+	require.NoError(t, err)
+	id, key := keyring.current()
+	assert.Equal(t, DefaultKeyID, id)
+	assert.Equal(t, []byte("current-secret"), key)
+	key, ok := keyring.lookup("older")
+	require.True(t, ok)
+	assert.Equal(t, []byte("older-secret"), key)
+	_, ok = keyring.lookup("unknown")
+	assert.False(t, ok)
+}
+
+func TestKeyringErrorsNeverContainKeyMaterial(t *testing.T) {
+	_, err := NewKeyring("k2", "current-secret", "k1:first-secret,retired-secret-without-kid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entry 2")
+	assert.NotContains(t, err.Error(), "retired-secret")
+
+	_, err = NewKeyring("k2", "current-secret", "k1:")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entry 1")
+
+	_, err = NewKeyring("k2", "current-secret", "k2:another-secret")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "another-secret")
+
+	_, err = NewKeyring("k2", "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// how long a user has to complete the sign-in at the provider
+const oidcStateTTL = 10 * time.Minute
+
+// codes of the sign-in errors, see service.Code
+const (
+	CodeUnknownProvider service.Code = "unknown_identity_provider"
+	CodeInvalidState    service.Code = "invalid_sign_in_state"
+)
+
+var (
+	ErrUnknownProvider = service.NewError(CodeUnknownProvider, "unknown identity provider")
+	ErrInvalidState    = service.NewError(CodeInvalidState, "invalid or expired sign-in state")
+)
+
+// OIDCProviderConfig is an OpenID Connect provider the users can sign in with,
+// RedirectURL is the callback of this server registered at the provider
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// defaults to openid, email and profile
+	Scopes []string
+}
+
+// OIDCState is kept between the redirect to the provider and the callback,
+// the state parameter sent to the provider is its key
+type OIDCState struct {
+	Provider string `json:"provider"`
+	// PKCE code verifier, the provider only got its S256 challenge
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+	// set when a signed-in user links the identity instead of signing in
+	LinkActor *service.Actor `json:"link_actor,omitempty"`
+}
+
+// OIDCStateStore keeps the sign-ins in progress, each state is used once
+type OIDCStateStore interface {
+	Save(state string, value OIDCState, ttl time.Duration) error
+	// Take removes the state, it returns ErrInvalidState if there is none
+	Take(state string) (*OIDCState, error)
+}
+
+type redisOIDCStateStore struct {
+	cache cache.Store
+}
+
+var _ OIDCStateStore = (*redisOIDCStateStore)(nil)
+
+func NewRedisOIDCStateStore(cache cache.Store) *redisOIDCStateStore {
+	return &redisOIDCStateStore{
+		cache: cache,
+	}
+}
+
+func oidcStateKey(state string) string {
+	return "auth:oidc:" + state
+}
+
+func (s *redisOIDCStateStore) Save(state string, value OIDCState, ttl time.Duration) error {
+	return s.cache.Set(oidcStateKey(state), value, ttl)
+}
+
+func (s *redisOIDCStateStore) Take(state string) (*OIDCState, error) {
+	var value OIDCState
+	if err := s.cache.GetDelete(oidcStateKey(state), &value); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, ErrInvalidState
+		}
+		return nil, err
+	}
+	return &value, nil
+}
+
+type memoryOIDCStateStore struct {
+	mu     sync.Mutex
+	states map[string]memoryOIDCState
+}
+
+type memoryOIDCState struct {
+	value     OIDCState
+	expiresAt time.Time
+}
+
+var _ OIDCStateStore = (*memoryOIDCStateStore)(nil)
+
+func NewMemoryOIDCStateStore() *memoryOIDCStateStore {
+	return &memoryOIDCStateStore{
+		states: make(map[string]memoryOIDCState),
+	}
+}
+
+func (s *memoryOIDCStateStore) Save(state string, value OIDCState, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[state] = memoryOIDCState{value: value, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryOIDCStateStore) Take(state string) (*OIDCState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.states[state]
+	delete(s.states, state)
+	if !ok || time.Now().After(stored.expiresAt) {
+		return nil, ErrInvalidState
+	}
+	return &stored.value, nil
+}
+
+// OIDCService signs users in with the authorization code flow and PKCE:
+// AuthURL sends the user to the provider, which redirects back with a code for Callback
+type OIDCService interface {
+	Providers() []string
+	// AuthURL returns the URL to sign in at the provider
+	AuthURL(provider string) (string, error)
+	// LinkURL is AuthURL for a signed-in user adding an identity to the account
+	LinkURL(actor service.Actor, provider string) (string, error)
+	// Callback exchanges the code and returns tokens for the user of the identity
+	Callback(ctx context.Context, state, code string) (*TokenPair, error)
+}
+
+type oidcProvider struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+type oidcService struct {
+	providers       map[string]*oidcProvider
+	store           OIDCStateStore
+	identityService service.IdentityService
+	tokenService    TokenService
+}
+
+var _ OIDCService = (*oidcService)(nil)
+
+// NewOIDCService fetches the discovery document of every provider,
+// ctx is also used later to fetch their signing keys
+func NewOIDCService(ctx context.Context, configs []OIDCProviderConfig, store OIDCStateStore,
+	identityService service.IdentityService, tokenService TokenService) (*oidcService, error) {
+	providers := make(map[string]*oidcProvider, len(configs))
+	for _, config := range configs {
+		if _, ok := providers[config.Name]; ok {
+			return nil, fmt.Errorf("identity provider %q configured twice", config.Name)
+		}
+		discovered, err := oidc.NewProvider(ctx, config.IssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("discover identity provider %q: %w", config.Name, err)
+		}
+		scopes := config.Scopes
+		if len(scopes) == 0 {
+			scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+		}
+		providers[config.Name] = &oidcProvider{
+			oauth: oauth2.Config{
+				ClientID:     config.ClientID,
+				ClientSecret: config.ClientSecret,
+				RedirectURL:  config.RedirectURL,
+				Endpoint:     discovered.Endpoint(),
+				Scopes:       scopes,
+			},
+			verifier: discovered.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		}
+	}
+	return &oidcService{
+		providers:       providers,
+		store:           store,
+		identityService: identityService,
+		tokenService:    tokenService,
+	}, nil
+}
+
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *oidcService) AuthURL(provider string) (string, error) {
+	return s.authURL(provider, nil)
+}
+
+func (s *oidcService) LinkURL(actor service.Actor, provider string) (string, error) {
+	return s.authURL(provider, &actor)
+}
+
+func (s *oidcService) authURL(provider string, linkActor *service.Actor) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	err = s.store.Save(state, OIDCState{
+		Provider:  provider,
+		Verifier:  verifier,
+		Nonce:     nonce,
+		LinkActor: linkActor,
+	}, oidcStateTTL)
+	if err != nil {
+		return "", err
+	}
+	return p.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oidc.Nonce(nonce)), nil
+}
+
+// idTokenClaims are the standard claims used to create or link a user
+type idTokenClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+func (s *oidcService) Callback(ctx context.Context, state, code string) (*TokenPair, error) {
+	stored, err := s.store.Take(state)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := s.providers[stored.Provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(stored.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code with %q: %w", stored.Provider, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q returned no id token", ErrInvalidToken, stored.Provider)
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	// the token must answer this sign-in, not be replayed from another one
+	if idToken.Nonce != stored.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+	var claims idTokenClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	identity := model.ExternalIdentity{
+		Provider: stored.Provider,
+		Subject:  idToken.Subject,
+		Email:    claims.Email,
+		// some providers send the flag as a string
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:          claims.PreferredUsername,
+	}
+	if identity.Name == "" {
+		identity.Name = claims.Name
+	}
+
+	if stored.LinkActor != nil {
+		if err := s.identityService.LinkIdentity(*stored.LinkActor, stored.LinkActor.UserID, identity); err != nil {
+			return nil, err
+		}
+	}
+	user, err := s.identityService.SignInWithIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	// the provider's sign-in doesn't count as our second factor,
+	// roles requiring one don't get their permissions in this session
+	return s.tokenService.IssueTokens(user)
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
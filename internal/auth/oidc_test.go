@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+const testRedirectURL = "https://tickets.example.com/auth/oidc/callback"
+
+func newTestOIDCService(t *testing.T, provider *standInProvider, identities *fakeIdentityService) *oidcService {
+	t.Helper()
+	keyring, err := NewKeyring("test", "test-secret", "")
+	require.NoError(t, err)
+	tokens := NewTokenService(keyring, newFakeSessionStore(), nil, nil)
+
+	oidcService, err := NewOIDCService(context.Background(), []OIDCProviderConfig{{
+		Name:         "stand-in",
+		IssuerURL:    provider.server.URL,
+		ClientID:     provider.clientID,
+		ClientSecret: provider.clientSecret,
+		RedirectURL:  testRedirectURL,
+	}}, NewMemoryOIDCStateStore(), identities, tokens)
+	require.NoError(t, err)
+	return oidcService
+}
+
+// authorize plays the browser: it follows the auth URL to the provider
+// and returns the state and code of the redirect back to the callback
+func authorize(t *testing.T, authURL string) (state, code string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, testRedirectURL, location.Scheme+"://"+location.Host+location.Path)
+	return location.Query().Get("state"), location.Query().Get("code")
+}
+
+func TestOIDCSignInCreatesUserOnce(t *testing.T) {
+	provider := newStandInProvider(t)
+	identities := newFakeIdentityService()
+	oidc := newTestOIDCService(t, provider, identities)
+
+	authURL, err := oidc.AuthURL("stand-in")
+	require.NoError(t, err)
+	query := mustQuery(t, authURL)
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("nonce"))
+
+	state, code := authorize(t, authURL)
+	pair, err := oidc.Callback(context.Background(), state, code)
+	require.NoError(t, err)
+	claims, err := oidc.tokenService.ParseAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+
+	user := identities.users[claims.UserID()]
+	require.NotNil(t, user)
+	assert.Equal(t, "jane@example.com", user.Email)
+
+	// signing in again finds the linked identity
+	authURL, err = oidc.AuthURL("stand-in")
+	require.NoError(t, err)
+	state, code = authorize(t, authURL)
+	pair, err = oidc.Callback(context.Background(), state, code)
+	require.NoError(t, err)
+	claims, err = oidc.tokenService.ParseAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID())
+	assert.Len(t, identities.users, 1)
+}
+
+func TestOIDCStateIsSingleUse(t *testing.T) {
+	provider := newStandInProvider(t)
+	oidc := newTestOIDCService(t, provider, newFakeIdentityService())
+
+	authURL, err := oidc.AuthURL("stand-in")
+	require.NoError(t, err)
+	state, code := authorize(t, authURL)
+	_, err = oidc.Callback(context.Background(), state, code)
+	require.NoError(t, err)
+
+	_, err = oidc.Callback(context.Background(), state, code)
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestOIDCRejectsCodeOfAnotherSignIn(t *testing.T) {
+	provider := newStandInProvider(t)
+	oidc := newTestOIDCService(t, provider, newFakeIdentityService())
+
+	firstURL, err := oidc.AuthURL("stand-in")
+	require.NoError(t, err)
+	secondURL, err := oidc.AuthURL("stand-in")
+	require.NoError(t, err)
+	state, _ := authorize(t, firstURL)
+	_, stolenCode := authorize(t, secondURL)
+
+	// the PKCE verifier of the first sign-in doesn't match the second challenge
+	_, err = oidc.Callback(context.Background(), state, stolenCode)
+	assert.Error(t, err)
+}
+
+func TestOIDCRejectsNonceMismatch(t *testing.T) {
+	provider := newStandInProvider(t)
+	oidc := newTestOIDCService(t, provider, newFakeIdentityService())
+
+	authURL, err := oidc.AuthURL("stand-in")
+	require.NoError(t, err)
+	state, code := authorize(t, authURL)
+	provider.overrideNonce("replayed")
+
+	_, err = oidc.Callback(context.Background(), state, code)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestOIDCLinkAddsIdentityToSignedInUser(t *testing.T) {
+	provider := newStandInProvider(t)
+	identities := newFakeIdentityService()
+	oidc := newTestOIDCService(t, provider, identities)
+	existing := identities.addUser("jane", "jane@example.com")
+
+	provider.signIn("other-subject", "jane.work@example.com", true)
+	authURL, err := oidc.LinkURL(service.ActorOf(existing), "stand-in")
+	require.NoError(t, err)
+	state, code := authorize(t, authURL)
+	pair, err := oidc.Callback(context.Background(), state, code)
+	require.NoError(t, err)
+
+	claims, err := oidc.tokenService.ParseAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, claims.UserID())
+	assert.Equal(t, existing.ID, identities.linked["stand-in/other-subject"])
+}
+
+func TestOIDCUnknownProvider(t *testing.T) {
+	provider := newStandInProvider(t)
+	oidc := newTestOIDCService(t, provider, newFakeIdentityService())
+
+	_, err := oidc.AuthURL("nope")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func mustQuery(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.Query()
+}
+
+// fakeIdentityService links identities in memory and creates a user per new identity
+type fakeIdentityService struct {
+	mu     sync.Mutex
+	users  map[uint]*model.User
+	linked map[string]uint
+}
+
+var _ service.IdentityService = (*fakeIdentityService)(nil)
+
+func newFakeIdentityService() *fakeIdentityService {
+	return &fakeIdentityService{
+		users:  make(map[uint]*model.User),
+		linked: make(map[string]uint),
+	}
+}
+
+func (s *fakeIdentityService) addUser(name, email string) *model.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := &model.User{ID: uint(len(s.users) + 1), Name: name, Email: email, Role: model.RoleUser, Status: model.UserActive}
+	s.users[user.ID] = user
+	return user
+}
+
+func (s *fakeIdentityService) SignInWithIdentity(identity model.ExternalIdentity) (*model.User, error) {
+	s.mu.Lock()
+	userID, ok := s.linked[identity.Provider+"/"+identity.Subject]
+	s.mu.Unlock()
+	if ok {
+		return s.users[userID], nil
+	}
+	user := s.addUser(identity.Name, identity.Email)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.linked[identity.Provider+"/"+identity.Subject] = user.ID
+	return user, nil
+}
+
+func (s *fakeIdentityService) LinkIdentity(actor service.Actor, userID uint, identity model.ExternalIdentity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if actor.UserID != userID {
+		return service.ErrForbidden
+	}
+	if _, ok := s.linked[identity.Provider+"/"+identity.Subject]; ok {
+		return service.ErrAlreadyExists
+	}
+	s.linked[identity.Provider+"/"+identity.Subject] = userID
+	return nil
+}
+
+func (s *fakeIdentityService) UnlinkIdentity(actor service.Actor, userID, identityID uint) error {
+	return nil
+}
+
+func (s *fakeIdentityService) GetIdentities(actor service.Actor, userID uint) ([]model.UserIdentity, error) {
+	return nil, nil
+}
+
+// fakeSessionStore is an in-memory SessionStore
+type fakeSessionStore struct {
+	mu          sync.Mutex
+	refresh     map[string]uint
+	generations map[uint]int64
+}
+
+var _ SessionStore = (*fakeSessionStore)(nil)
+
+func newFakeSessionStore() *fakeSessionStore {
+	return &fakeSessionStore{
+		refresh:     make(map[string]uint),
+		generations: make(map[uint]int64),
+	}
+}
+
+func (s *fakeSessionStore) SaveRefreshToken(tokenID string, userID uint, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh[tokenID] = userID
+	return nil
+}
+
+func (s *fakeSessionStore) ConsumeRefreshToken(tokenID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.refresh[tokenID]
+	delete(s.refresh, tokenID)
+	return ok, nil
+}
+
+func (s *fakeSessionStore) Generation(userID uint) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[userID], nil
+}
+
+func (s *fakeSessionStore) BumpGeneration(userID uint) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generations[userID]++
+	return s.generations[userID], nil
+}
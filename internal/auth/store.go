@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// SessionStore keeps the server-side state of the tokens:
+// the refresh tokens not used yet, and a generation per user
+// which is bumped to invalidate every token issued before
+type SessionStore interface {
+	SaveRefreshToken(tokenID string, userID uint, ttl time.Duration) error
+	// ConsumeRefreshToken deletes the token and reports whether it was there
+	ConsumeRefreshToken(tokenID string) (bool, error)
+	Generation(userID uint) (int64, error)
+	BumpGeneration(userID uint) (int64, error)
+}
+
+type redisSessionStore struct {
+	cache cache.Store
+}
+
+var _ SessionStore = (*redisSessionStore)(nil)
+
+func NewRedisSessionStore(cache cache.Store) *redisSessionStore {
+	return &redisSessionStore{
+		cache: cache,
+	}
+}
+
+func refreshTokenKey(tokenID string) string {
+	return "auth:refresh:" + tokenID
+}
+
+func generationKey(userID uint) string {
+	return fmt.Sprintf("auth:generation:%d", userID)
+}
+
+func (s *redisSessionStore) SaveRefreshToken(tokenID string, userID uint, ttl time.Duration) error {
+	return s.cache.Set(refreshTokenKey(tokenID), userID, ttl)
+}
+
+func (s *redisSessionStore) ConsumeRefreshToken(tokenID string) (bool, error) {
+	return s.cache.Delete(refreshTokenKey(tokenID))
+}
+
+func (s *redisSessionStore) Generation(userID uint) (int64, error) {
+	var generation int64
+	if err := s.cache.Get(generationKey(userID), &generation); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return generation, nil
+}
+
+func (s *redisSessionStore) BumpGeneration(userID uint) (int64, error) {
+	return s.cache.Incr(generationKey(userID))
+}
@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+
+	issuer = "movie-reservation"
+)
+
+// codes of the token errors, see service.Code
+const (
+	CodeInvalidSessionToken service.Code = "invalid_session_token"
+	CodeSessionRevoked      service.Code = "session_revoked"
+)
+
+var (
+	ErrInvalidToken = service.NewError(CodeInvalidSessionToken, "invalid or expired token")
+	ErrTokenRevoked = service.NewError(CodeSessionRevoked, "token has been revoked")
+)
+
+type TokenType string
+
+const (
+	AccessToken  TokenType = "access"
+	RefreshToken TokenType = "refresh"
+)
+
+// Claims are carried by both token types,
+// Subject is the user ID and ID identifies the token.
+// MFA tells whether the session was started with a second factor,
+// MFARequired whether the role needs one to be granted
+type Claims struct {
+	jwt.RegisteredClaims
+	Type        TokenType      `json:"typ"`
+	Role        model.UserRole `json:"role"`
+	Generation  int64          `json:"gen"`
+	MFA         bool           `json:"mfa,omitempty"`
+	MFARequired bool           `json:"mfa_req,omitempty"`
+}
+
+// UserID returns the user the token was issued to
+func (c *Claims) UserID() uint {
+	id, _ := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(id)
+}
+
+// Actor returns the principal service calls are made on behalf of,
+// without the second factor its role requires it only gets the permissions of RoleUser
+func (c *Claims) Actor() service.Actor {
+	role := c.Role
+	if c.MFARequired && !c.MFA {
+		role = model.RoleUser
+	}
+	return service.Actor{
+		UserID: c.UserID(),
+		Role:   role,
+	}
+}
+
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// TokenService issues short-lived access tokens and single-use refresh tokens,
+// every refresh rotates the refresh token,
+// and replaying a used refresh token revokes all sessions of the user
+type TokenService interface {
+	Login(name, password, clientIP string, captcha service.CaptchaSolution, otp string) (*TokenPair, error)
+	IssueTokens(user *model.User) (*TokenPair, error)
+	ParseAccessToken(token string) (*Claims, error)
+	Refresh(refreshToken string) (*TokenPair, error)
+	Logout(refreshToken string) error
+	LogoutAll(userID uint) error
+}
+
+type tokenService struct {
+	keyring     *Keyring
+	store       SessionStore
+	userService service.UserService
+	twoFactor   service.TwoFactorService
+	now         func() time.Time
+}
+
+var _ TokenService = (*tokenService)(nil)
+
+// NewTokenService creates the token service, a nil twoFactor disables two-factor authentication
+func NewTokenService(keyring *Keyring, store SessionStore, userService service.UserService,
+	twoFactor service.TwoFactorService) *tokenService {
+	return &tokenService{
+		keyring:     keyring,
+		store:       store,
+		userService: userService,
+		twoFactor:   twoFactor,
+		now:         time.Now,
+	}
+}
+
+// Login returns service.ErrInvalidCredential for a wrong name or password,
+// see UserService.Authenticate for the throttling and captcha errors.
+// Users with two factors enabled also need the otp, a TOTP or recovery code,
+// and get service.ErrTwoFactorRequired without it
+func (s *tokenService) Login(name, password, clientIP string, captcha service.CaptchaSolution, otp string) (*TokenPair, error) {
+	user, err := s.userService.Authenticate(name, password, clientIP, captcha)
+	if err != nil {
+		return nil, err
+	}
+	if s.twoFactor == nil {
+		return s.issue(user, false)
+	}
+	enabled, err := s.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return s.issue(user, false)
+	}
+	if otp == "" {
+		return nil, service.ErrTwoFactorRequired
+	}
+	if err := s.twoFactor.Verify(user.ID, otp); err != nil {
+		return nil, err
+	}
+	return s.issue(user, true)
+}
+
+// IssueTokens starts a session without a second factor, e.g. after an external sign-in
+func (s *tokenService) IssueTokens(user *model.User) (*TokenPair, error) {
+	return s.issue(user, false)
+}
+
+func (s *tokenService) issue(user *model.User, mfa bool) (*TokenPair, error) {
+	generation, err := s.store.Generation(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	pair := &TokenPair{
+		AccessExpiresAt:  now.Add(AccessTokenTTL),
+		RefreshExpiresAt: now.Add(RefreshTokenTTL),
+	}
+	pair.AccessToken, _, err = s.sign(user, AccessToken, generation, mfa, now, pair.AccessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	var refreshID string
+	pair.RefreshToken, refreshID, err = s.sign(user, RefreshToken, generation, mfa, now, pair.RefreshExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveRefreshToken(refreshID, user.ID, RefreshTokenTTL); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// ParseAccessToken verifies the signature, expiry and generation of the token
+func (s *tokenService) ParseAccessToken(token string) (*Claims, error) {
+	return s.parse(token, AccessToken)
+}
+
+// Refresh consumes the refresh token and issues a new pair,
+// the user is reloaded so role changes, suspensions and deletions take effect
+func (s *tokenService) Refresh(refreshToken string) (*TokenPair, error) {
+	claims, err := s.parse(refreshToken, RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	consumed, err := s.store.ConsumeRefreshToken(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		// the token was already used, someone may have stolen it
+		if _, err := s.store.BumpGeneration(claims.UserID()); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenRevoked
+	}
+
+	user, err := s.userService.GetUserByID(claims.UserID())
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return nil, ErrTokenRevoked
+		}
+		return nil, err
+	}
+	if user.Status == model.UserSuspended {
+		return nil, service.ErrUserSuspended
+	}
+	// the second factor was checked when the session started
+	return s.issue(user, claims.MFA)
+}
+
+// Logout ends the session of the refresh token,
+// its access token stays valid until it expires
+func (s *tokenService) Logout(refreshToken string) error {
+	claims, err := s.parse(refreshToken, RefreshToken)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.ConsumeRefreshToken(claims.ID)
+	return err
+}
+
+// LogoutAll invalidates every access and refresh token issued to the user
+func (s *tokenService) LogoutAll(userID uint) error {
+	_, err := s.store.BumpGeneration(userID)
+	return err
+}
+
+func (s *tokenService) sign(user *model.User, tokenType TokenType, generation int64, mfa bool,
+	issuedAt, expiresAt time.Time) (token string, tokenID string, err error) {
+	tokenID, err = newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Type:        tokenType,
+		Role:        user.Role,
+		Generation:  generation,
+		MFA:         mfa,
+		MFARequired: s.twoFactor != nil && s.twoFactor.Required(user.Role),
+	}
+
+	keyID, key := s.keyring.current()
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwtToken.Header["kid"] = keyID
+	token, err = jwtToken.SignedString(key)
+	if err != nil {
+		return "", "", err
+	}
+	return token, tokenID, nil
+}
+
+func (s *tokenService) parse(token string, tokenType TokenType) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		keyID, _ := t.Header["kid"].(string)
+		key, ok := s.keyring.lookup(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	generation, err := s.store.Generation(claims.UserID())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Generation != generation {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestClaimsWithoutTheRequiredSecondFactorOnlyGrantRoleUser(t *testing.T) {
+	claims := Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "7"}, Role: model.RoleAdmin}
+	assert.Equal(t, model.RoleAdmin, claims.Actor().Role)
+
+	claims.MFARequired = true
+	actor := claims.Actor()
+	assert.Equal(t, model.RoleUser, actor.Role)
+	assert.EqualValues(t, 7, actor.UserID)
+
+	claims.MFA = true
+	assert.Equal(t, model.RoleAdmin, claims.Actor().Role)
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache keeps values under string keys until their TTL passes,
+// RedisCache, LRU and TwoTier implement it
+type Cache interface {
+	// GetBytes returns ErrNotFound when the key is missing or expired
+	GetBytes(key string) ([]byte, error)
+	// SetBytes stores the value, a ttl of 0 keeps it until it is deleted or evicted
+	SetBytes(key string, value []byte, ttl time.Duration) error
+	// Delete removes the key and reports whether it existed
+	Delete(key string) (bool, error)
+	// DeletePattern removes the keys matching the glob pattern, like "catalog:*",
+	// and returns how many it removed
+	DeletePattern(pattern string) (int, error)
+}
+
+// CounterStore keeps integers shared by the instances, unlike the values of a Cache
+// they are neither evicted nor copied locally, RedisCache implements it
+type CounterStore interface {
+	// Incr increments the counter at key and returns the new value, a missing key counts as 0
+	Incr(key string) (int64, error)
+	// Counters reads the counters at the keys in one round trip, a missing key counts as 0
+	Counters(keys ...string) ([]int64, error)
+}
+
+// Store is a Cache shared by the instances with the atomic operations the stores
+// of sessions, attempts, idempotency keys, challenges, rate limits and queues are built on,
+// RedisCache implements it
+type Store interface {
+	Cache
+	CounterStore
+	// Set stores the value as JSON
+	Set(key string, value any, ttl time.Duration) error
+	// Get reads the JSON at key into dest, it returns ErrNotFound when the key is missing
+	Get(key string, dest any) error
+	// SetIfAbsent sets the key only if it doesn't exist, and reports whether it did
+	SetIfAbsent(key string, value any, ttl time.Duration) (bool, error)
+	// GetDelete is Get removing the key in the same step
+	GetDelete(key string, dest any) error
+	// IncrWithTTL is Incr (re)setting the expiration of the key
+	IncrWithTTL(key string, ttl time.Duration) (int64, error)
+	// Run runs the script atomically
+	Run(script *Script, keys []string, args ...any) (any, error)
+}
+
+// PubSub delivers the messages published on a channel to the subscribers
+// of every instance, RedisCache implements it
+type PubSub interface {
+	// Publish sends the message as JSON
+	Publish(channel string, message any) error
+	// Subscribe returns the messages published on the channel until ctx is done
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+}
+
+// Typed stores values of T in a Cache as JSON
+type Typed[T any] struct {
+	cache Cache
+	loads singleflight.Group
+}
+
+func NewTyped[T any](cache Cache) *Typed[T] {
+	return &Typed[T]{cache: cache}
+}
+
+// Get returns ErrNotFound when the key is missing or expired
+func (t *Typed[T]) Get(key string) (T, error) {
+	var value T
+	data, err := t.cache.GetBytes(key)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+func (t *Typed[T]) Set(key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return t.cache.SetBytes(key, data, ttl)
+}
+
+func (t *Typed[T]) Delete(key string) (bool, error) {
+	return t.cache.Delete(key)
+}
+
+// GetOrLoad returns the value cached for the key, or loads and caches it for ttl.
+// Concurrent callers missing the same key share one load, so an expired hot key
+// doesn't send every request to the loader. The cache is best effort,
+// while it fails every call loads
+func (t *Typed[T]) GetOrLoad(key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	if value, err := t.Get(key); err == nil {
+		return value, nil
+	}
+	loaded, err, _ := t.loads.Do(key, func() (any, error) {
+		// the load that just finished may have cached it
+		if value, err := t.Get(key); err == nil {
+			return value, nil
+		}
+		value, err := load()
+		if err != nil {
+			return value, err
+		}
+		_ = t.Set(key, value, ttl)
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return loaded.(T), nil
+}
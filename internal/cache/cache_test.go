@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) *RedisCache {
+	redis := NewRedisCache(miniredis.RunT(t).Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+	return redis
+}
+
+// TestBackends checks that the backends behave alike
+func TestBackends(t *testing.T) {
+	backends := map[string]func(t *testing.T) Cache{
+		"lru":   func(t *testing.T) Cache { return NewLRU(100) },
+		"redis": func(t *testing.T) Cache { return newTestRedis(t) },
+		"two-tier": func(t *testing.T) Cache {
+			return NewTwoTier(NewLRU(100), newTestRedis(t), time.Minute)
+		},
+	}
+	for name, newCache := range backends {
+		t.Run(name, func(t *testing.T) {
+			c := newCache(t)
+
+			_, err := c.GetBytes("missing")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, c.SetBytes("movie:1", []byte("Dune"), time.Minute))
+			value, err := c.GetBytes("movie:1")
+			require.NoError(t, err)
+			assert.Equal(t, "Dune", string(value))
+
+			deleted, err := c.Delete("movie:1")
+			require.NoError(t, err)
+			assert.True(t, deleted)
+			deleted, err = c.Delete("movie:1")
+			require.NoError(t, err)
+			assert.False(t, deleted)
+			_, err = c.GetBytes("movie:1")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			for _, key := range []string{"movie:1", "movie:2", "movie:10", "hall:1"} {
+				require.NoError(t, c.SetBytes(key, []byte(key), 0))
+			}
+			n, err := c.DeletePattern("movie:?")
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+			n, err = c.DeletePattern("movie:*")
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			_, err = c.GetBytes("hall:1")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLRUEvictsTheLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(2)
+	require.NoError(t, c.SetBytes("a", []byte("a"), 0))
+	require.NoError(t, c.SetBytes("b", []byte("b"), 0))
+	_, err := c.GetBytes("a")
+	require.NoError(t, err)
+	require.NoError(t, c.SetBytes("c", []byte("c"), 0))
+
+	_, err = c.GetBytes("b")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.GetBytes("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRUExpires(t *testing.T) {
+	c := NewLRU(10)
+	require.NoError(t, c.SetBytes("a", []byte("a"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, err := c.GetBytes("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{"catalog:*", "catalog:|movies=0|:/movies?page=2", true},
+		{"catalog:*|halls=*", "catalog:|movies=0|halls=3|:/halls", true},
+		{"catalog:*|halls=*", "catalog:|movies=0|:/movies", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "heello", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{"a.b", "axb", false},
+		{"[", "[", true},
+		{"ü*", "über", true},
+	}
+	for _, test := range tests {
+		matcher, err := globRegexp(test.pattern)
+		require.NoError(t, err, test.pattern)
+		assert.Equal(t, test.match, matcher.MatchString(test.key), "%s ~ %s", test.pattern, test.key)
+	}
+}
+
+func TestGetOrLoadSharesConcurrentLoads(t *testing.T) {
+	movies := NewTyped[[]string](NewLRU(10))
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func() ([]string, error) {
+		loads.Add(1)
+		<-release
+		return []string{"Dune"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := movies.GetOrLoad("movies", time.Minute, load)
+			assert.NoError(t, err)
+			results[i] = value
+		}()
+	}
+	// let the callers pile up behind the first load
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	for _, result := range results {
+		assert.Equal(t, []string{"Dune"}, result)
+	}
+	cached, err := movies.Get("movies")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dune"}, cached)
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	counts := NewTyped[int](NewLRU(10))
+	_, err := counts.GetOrLoad("count", time.Minute, func() (int, error) {
+		return 0, errors.New("database down")
+	})
+	assert.Error(t, err)
+	count, err := counts.GetOrLoad("count", time.Minute, func() (int, error) { return 3, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestTwoTierKeepsLocalCopies(t *testing.T) {
+	shared := newTestRedis(t)
+	local := NewLRU(10)
+	c := NewTwoTier(local, shared, time.Minute)
+	other := NewTwoTier(NewLRU(10), shared, time.Minute)
+
+	require.NoError(t, other.SetBytes("movie:1", []byte("Dune"), time.Hour))
+	value, err := c.GetBytes("movie:1")
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", string(value))
+
+	// the local copy is served until it expires, the change of the other instance
+	// is only seen by new readers
+	require.NoError(t, other.SetBytes("movie:1", []byte("Dune: Part One"), time.Hour))
+	value, err = c.GetBytes("movie:1")
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", string(value))
+
+	_, err = c.Delete("movie:1")
+	require.NoError(t, err)
+	_, err = local.GetBytes("movie:1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = shared.GetBytes("movie:1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRedisCounters(t *testing.T) {
+	redis := newTestRedis(t)
+	_, err := redis.Incr("versions:halls")
+	require.NoError(t, err)
+	_, err = redis.Incr("versions:halls")
+	require.NoError(t, err)
+
+	counters, err := redis.Counters("versions:movies", "versions:halls")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 2}, counters)
+
+	require.NoError(t, redis.SetBytes("versions:movies", []byte("Dune"), 0))
+	_, err = redis.Counters("versions:movies")
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Cache of at most capacity keys,
+// the least recently used key is evicted to make room
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// the front is the most recently used
+	order *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+	// zero for no expiration
+	expiresAt time.Time
+}
+
+var _ Cache = (*LRU)(nil)
+
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: max(capacity, 1),
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRU) GetBytes(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	entry := element.Value.(*lruEntry)
+	if entry.expired(time.Now()) {
+		c.remove(element)
+		return nil, ErrNotFound
+	}
+	c.order.MoveToFront(element)
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (c *LRU) SetBytes(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lruEntry{key: key, value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Delete(key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return false, nil
+	}
+	expired := element.Value.(*lruEntry).expired(time.Now())
+	c.remove(element)
+	return !expired, nil
+}
+
+func (c *LRU) DeletePattern(pattern string) (int, error) {
+	matcher, err := globRegexp(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	deleted := 0
+	for key, element := range c.entries {
+		if !matcher.MatchString(key) {
+			continue
+		}
+		if !element.Value.(*lruEntry).expired(now) {
+			deleted++
+		}
+		c.remove(element)
+	}
+	return deleted, nil
+}
+
+// Len returns the number of keys, including the expired ones not evicted yet
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
+
+func (e *lruEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// globRegexp translates a glob pattern of redis: * and ? match any characters
+// and one character, [abc], [^abc] and [a-z] sets of characters, \ escapes the next one
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	chars := []rune(pattern)
+	var expr strings.Builder
+	expr.WriteString(`^(?s:`)
+	for i := 0; i < len(chars); i++ {
+		switch chars[i] {
+		case '*':
+			expr.WriteString(`.*`)
+		case '?':
+			expr.WriteString(`.`)
+		case '\\':
+			if i+1 < len(chars) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(string(chars[i])))
+		case '[':
+			end := slices.Index(chars[i+1:], ']')
+			if end <= 0 {
+				// no set, the bracket is a character
+				expr.WriteString(`\[`)
+				continue
+			}
+			set := chars[i+1 : i+1+end]
+			expr.WriteString(`[`)
+			if set[0] == '^' && len(set) > 1 {
+				expr.WriteString(`^`)
+				set = set[1:]
+			}
+			for j := 0; j < len(set); j++ {
+				if set[j] == '-' && j > 0 && j < len(set)-1 {
+					expr.WriteString(`-`)
+					continue
+				}
+				if set[j] == '\\' && j+1 < len(set) {
+					j++
+				}
+				expr.WriteString(regexp.QuoteMeta(string(set[j])))
+			}
+			expr.WriteString(`]`)
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(chars[i])))
+		}
+	}
+	expr.WriteString(`)$`)
+	return regexp.Compile(expr.String())
+}
@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
 	redis "github.com/redis/go-redis/v9"
@@ -10,10 +12,22 @@ import (
 
 var ctx = context.Background()
 
+// ErrNotFound is returned by the getters when the key doesn't exist
+var ErrNotFound = redis.Nil
+
+// RedisCache is the Cache shared by the instances,
+// it also offers the atomic operations of redis the stores are built on
 type RedisCache struct {
 	client *redis.Client
 }
 
+var (
+	_ Cache        = (*RedisCache)(nil)
+	_ CounterStore = (*RedisCache)(nil)
+	_ Store        = (*RedisCache)(nil)
+	_ PubSub       = (*RedisCache)(nil)
+)
+
 func NewRedisCache(url string) *RedisCache {
 	client := redis.NewClient(
 		&redis.Options{
@@ -41,18 +55,172 @@ func (r *RedisCache) Get(key string, dest any) error {
 	return json.Unmarshal(data, dest)
 }
 
-func (r *RedisCache) SetBool(key string, value bool) error {
-	strValue := "false"
-	if value {
-		strValue = "true"
+func (r *RedisCache) GetBytes(key string) ([]byte, error) {
+	return r.client.Get(ctx, key).Bytes()
+}
+
+func (r *RedisCache) SetBytes(key string, value []byte, expiration time.Duration) error {
+	return r.client.Set(ctx, key, value, expiration).Err()
+}
+
+// SetIfAbsent sets the key only if it doesn't exist, and reports whether it did
+func (r *RedisCache) SetIfAbsent(key string, value any, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetNX(ctx, key, data, expiration).Result()
+}
+
+// GetDelete reads the key into dest and removes it in one step,
+// so only one caller can get a single-use value
+func (r *RedisCache) GetDelete(key string, dest any) error {
+	data, err := r.client.GetDel(ctx, key).Bytes()
+	if err != nil {
+		return err
 	}
-	return r.client.Set(ctx, key, strValue, 5*time.Minute).Err()
+	return json.Unmarshal(data, dest)
 }
 
-func (r *RedisCache) GetBool(key string) (value bool, err error) {
-	value, err = r.client.Get(ctx, key).Bool()
+// Delete removes the key and reports whether it existed,
+// so it can be used to consume a single-use key
+func (r *RedisCache) Delete(key string) (bool, error) {
+	deleted, err := r.client.Del(ctx, key).Result()
 	if err != nil {
 		return false, err
 	}
-	return value, nil
+	return deleted > 0, nil
+}
+
+// DeletePattern scans for the matching keys, so it doesn't block redis,
+// keys set while it scans may be left
+func (r *RedisCache) DeletePattern(pattern string) (int, error) {
+	deleted := 0
+	iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		n, err := r.client.Del(ctx, keys...).Result()
+		deleted += int(n)
+		keys = keys[:0]
+		return err
+	}
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 100 {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+	return deleted, flush()
+}
+
+// Incr atomically increments the integer stored at key, a missing key counts as 0
+func (r *RedisCache) Incr(key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// Counters reads the integers stored by Incr at the keys in one round trip,
+// a missing key counts as 0
+func (r *RedisCache) Counters(keys ...string) ([]int64, error) {
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]int64, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("counter %s is %T, not a string", keys[i], value)
+		}
+		if counters[i], err = strconv.ParseInt(text, 10, 64); err != nil {
+			return nil, fmt.Errorf("counter %s: %w", keys[i], err)
+		}
+	}
+	return counters, nil
+}
+
+// IncrWithTTL increments the integer stored at key and (re)sets its expiration
+func (r *RedisCache) IncrWithTTL(key string, expiration time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, expiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Publish sends the message as JSON to the subscribers of the channel
+func (r *RedisCache) Publish(channel string, message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, channel, data).Err()
+}
+
+// Subscribe returns the messages published on the channel until subscriptionCtx is done,
+// it returns once redis confirmed the subscription, so no later message is missed
+func (r *RedisCache) Subscribe(subscriptionCtx context.Context, channel string) (<-chan []byte, error) {
+	pubsub := r.client.Subscribe(subscriptionCtx, channel)
+	if _, err := pubsub.Receive(subscriptionCtx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	messages := make(chan []byte)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+		received := pubsub.Channel()
+		for {
+			select {
+			case <-subscriptionCtx.Done():
+				return
+			case msg, ok := <-received:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- []byte(msg.Payload):
+				case <-subscriptionCtx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
+// Script is a lua script run atomically by redis
+type Script struct {
+	script *redis.Script
+}
+
+func NewScript(source string) *Script {
+	return &Script{script: redis.NewScript(source)}
+}
+
+// Run runs the script, it is only sent to redis when redis doesn't have it cached yet
+func (r *RedisCache) Run(script *Script, keys []string, args ...any) (any, error) {
+	return script.script.Run(ctx, r.client, keys, args...).Result()
+}
+
+// Ping checks the connection to redis
+func (r *RedisCache) Ping() error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *RedisCache) Close() error {
+	return r.client.Close()
 }
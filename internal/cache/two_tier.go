@@ -0,0 +1,58 @@
+package cache
+
+import "time"
+
+// TwoTier keeps short-lived local copies of the values of a shared cache,
+// saving the round trips to the shared cache for hot keys. A change by another
+// instance reaches the local copies after at most localTTL
+type TwoTier struct {
+	local    Cache
+	shared   Cache
+	localTTL time.Duration
+}
+
+var _ Cache = (*TwoTier)(nil)
+
+// NewTwoTier combines an in-process cache, like an LRU, with a shared one, like redis
+func NewTwoTier(local Cache, shared Cache, localTTL time.Duration) *TwoTier {
+	return &TwoTier{local: local, shared: shared, localTTL: localTTL}
+}
+
+func (c *TwoTier) GetBytes(key string) ([]byte, error) {
+	if value, err := c.local.GetBytes(key); err == nil {
+		return value, nil
+	}
+	value, err := c.shared.GetBytes(key)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.local.SetBytes(key, value, c.localTTL)
+	return value, nil
+}
+
+func (c *TwoTier) SetBytes(key string, value []byte, ttl time.Duration) error {
+	if err := c.shared.SetBytes(key, value, ttl); err != nil {
+		// the local copy would outlive the value other instances see
+		_, _ = c.local.Delete(key)
+		return err
+	}
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	return c.local.SetBytes(key, value, localTTL)
+}
+
+func (c *TwoTier) Delete(key string) (bool, error) {
+	if _, err := c.local.Delete(key); err != nil {
+		return false, err
+	}
+	return c.shared.Delete(key)
+}
+
+func (c *TwoTier) DeletePattern(pattern string) (int, error) {
+	if _, err := c.local.DeletePattern(pattern); err != nil {
+		return 0, err
+	}
+	return c.shared.DeletePattern(pattern)
+}
@@ -0,0 +1,999 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: moviereservation/v1/hall.proto
+
+package moviereservationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CellKind int32
+
+const (
+	CellKind_CELL_KIND_UNSPECIFIED CellKind = 0
+	CellKind_CELL_KIND_SEAT        CellKind = 1
+	CellKind_CELL_KIND_AISLE       CellKind = 2
+	CellKind_CELL_KIND_GAP         CellKind = 3
+)
+
+// Enum value maps for CellKind.
+var (
+	CellKind_name = map[int32]string{
+		0: "CELL_KIND_UNSPECIFIED",
+		1: "CELL_KIND_SEAT",
+		2: "CELL_KIND_AISLE",
+		3: "CELL_KIND_GAP",
+	}
+	CellKind_value = map[string]int32{
+		"CELL_KIND_UNSPECIFIED": 0,
+		"CELL_KIND_SEAT":        1,
+		"CELL_KIND_AISLE":       2,
+		"CELL_KIND_GAP":         3,
+	}
+)
+
+func (x CellKind) Enum() *CellKind {
+	p := new(CellKind)
+	*p = x
+	return p
+}
+
+func (x CellKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CellKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_moviereservation_v1_hall_proto_enumTypes[0].Descriptor()
+}
+
+func (CellKind) Type() protoreflect.EnumType {
+	return &file_moviereservation_v1_hall_proto_enumTypes[0]
+}
+
+func (x CellKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CellKind.Descriptor instead.
+func (CellKind) EnumDescriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{0}
+}
+
+type SeatCategory int32
+
+const (
+	SeatCategory_SEAT_CATEGORY_UNSPECIFIED SeatCategory = 0
+	SeatCategory_SEAT_CATEGORY_STANDARD    SeatCategory = 1
+	SeatCategory_SEAT_CATEGORY_PREMIUM     SeatCategory = 2
+	SeatCategory_SEAT_CATEGORY_WHEELCHAIR  SeatCategory = 3
+	SeatCategory_SEAT_CATEGORY_COMPANION   SeatCategory = 4
+)
+
+// Enum value maps for SeatCategory.
+var (
+	SeatCategory_name = map[int32]string{
+		0: "SEAT_CATEGORY_UNSPECIFIED",
+		1: "SEAT_CATEGORY_STANDARD",
+		2: "SEAT_CATEGORY_PREMIUM",
+		3: "SEAT_CATEGORY_WHEELCHAIR",
+		4: "SEAT_CATEGORY_COMPANION",
+	}
+	SeatCategory_value = map[string]int32{
+		"SEAT_CATEGORY_UNSPECIFIED": 0,
+		"SEAT_CATEGORY_STANDARD":    1,
+		"SEAT_CATEGORY_PREMIUM":     2,
+		"SEAT_CATEGORY_WHEELCHAIR":  3,
+		"SEAT_CATEGORY_COMPANION":   4,
+	}
+)
+
+func (x SeatCategory) Enum() *SeatCategory {
+	p := new(SeatCategory)
+	*p = x
+	return p
+}
+
+func (x SeatCategory) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SeatCategory) Descriptor() protoreflect.EnumDescriptor {
+	return file_moviereservation_v1_hall_proto_enumTypes[1].Descriptor()
+}
+
+func (SeatCategory) Type() protoreflect.EnumType {
+	return &file_moviereservation_v1_hall_proto_enumTypes[1]
+}
+
+func (x SeatCategory) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SeatCategory.Descriptor instead.
+func (SeatCategory) EnumDescriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{1}
+}
+
+type Hall struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CinemaId      uint64                 `protobuf:"varint,2,opt,name=cinema_id,json=cinemaId,proto3" json:"cinema_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	SeatCount     int32                  `protobuf:"varint,4,opt,name=seat_count,json=seatCount,proto3" json:"seat_count,omitempty"`
+	Rows          int32                  `protobuf:"varint,5,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          int32                  `protobuf:"varint,6,opt,name=cols,proto3" json:"cols,omitempty"`
+	Layout        *HallLayout            `protobuf:"bytes,7,opt,name=layout,proto3" json:"layout,omitempty"`
+	Version       uint64                 `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hall) Reset() {
+	*x = Hall{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hall) ProtoMessage() {}
+
+func (x *Hall) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hall.ProtoReflect.Descriptor instead.
+func (*Hall) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Hall) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Hall) GetCinemaId() uint64 {
+	if x != nil {
+		return x.CinemaId
+	}
+	return 0
+}
+
+func (x *Hall) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Hall) GetSeatCount() int32 {
+	if x != nil {
+		return x.SeatCount
+	}
+	return 0
+}
+
+func (x *Hall) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Hall) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *Hall) GetLayout() *HallLayout {
+	if x != nil {
+		return x.Layout
+	}
+	return nil
+}
+
+func (x *Hall) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// HallLayout is the seating plan row by row, bookable seats are numbered
+// from 1 in row-major order, which is the seat_id of a reservation
+type HallLayout struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          []*HallRow             `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HallLayout) Reset() {
+	*x = HallLayout{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HallLayout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HallLayout) ProtoMessage() {}
+
+func (x *HallLayout) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HallLayout.ProtoReflect.Descriptor instead.
+func (*HallLayout) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HallLayout) GetRows() []*HallRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type HallRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Curved        bool                   `protobuf:"varint,2,opt,name=curved,proto3" json:"curved,omitempty"`
+	Cells         []*HallCell            `protobuf:"bytes,3,rep,name=cells,proto3" json:"cells,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HallRow) Reset() {
+	*x = HallRow{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HallRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HallRow) ProtoMessage() {}
+
+func (x *HallRow) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HallRow.ProtoReflect.Descriptor instead.
+func (*HallRow) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HallRow) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *HallRow) GetCurved() bool {
+	if x != nil {
+		return x.Curved
+	}
+	return false
+}
+
+func (x *HallRow) GetCells() []*HallCell {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+type HallCell struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  CellKind               `protobuf:"varint,1,opt,name=kind,proto3,enum=moviereservation.v1.CellKind" json:"kind,omitempty"`
+	// required for seats
+	Category      SeatCategory `protobuf:"varint,2,opt,name=category,proto3,enum=moviereservation.v1.SeatCategory" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HallCell) Reset() {
+	*x = HallCell{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HallCell) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HallCell) ProtoMessage() {}
+
+func (x *HallCell) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HallCell.ProtoReflect.Descriptor instead.
+func (*HallCell) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HallCell) GetKind() CellKind {
+	if x != nil {
+		return x.Kind
+	}
+	return CellKind_CELL_KIND_UNSPECIFIED
+}
+
+func (x *HallCell) GetCategory() SeatCategory {
+	if x != nil {
+		return x.Category
+	}
+	return SeatCategory_SEAT_CATEGORY_UNSPECIFIED
+}
+
+type ListHallsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// only the halls of the cinema, 0 for all halls
+	CinemaId      uint64 `protobuf:"varint,1,opt,name=cinema_id,json=cinemaId,proto3" json:"cinema_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHallsRequest) Reset() {
+	*x = ListHallsRequest{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHallsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHallsRequest) ProtoMessage() {}
+
+func (x *ListHallsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHallsRequest.ProtoReflect.Descriptor instead.
+func (*ListHallsRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListHallsRequest) GetCinemaId() uint64 {
+	if x != nil {
+		return x.CinemaId
+	}
+	return 0
+}
+
+type ListHallsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Halls         []*Hall                `protobuf:"bytes,1,rep,name=halls,proto3" json:"halls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHallsResponse) Reset() {
+	*x = ListHallsResponse{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHallsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHallsResponse) ProtoMessage() {}
+
+func (x *ListHallsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHallsResponse.ProtoReflect.Descriptor instead.
+func (*ListHallsResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListHallsResponse) GetHalls() []*Hall {
+	if x != nil {
+		return x.Halls
+	}
+	return nil
+}
+
+type GetHallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHallRequest) Reset() {
+	*x = GetHallRequest{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHallRequest) ProtoMessage() {}
+
+func (x *GetHallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHallRequest.ProtoReflect.Descriptor instead.
+func (*GetHallRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetHallRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// a hall has either a layout, or rows and cols for a rectangular hall
+type CreateHallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CinemaId      uint64                 `protobuf:"varint,1,opt,name=cinema_id,json=cinemaId,proto3" json:"cinema_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Rows          int32                  `protobuf:"varint,3,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          int32                  `protobuf:"varint,4,opt,name=cols,proto3" json:"cols,omitempty"`
+	Layout        *HallLayout            `protobuf:"bytes,5,opt,name=layout,proto3" json:"layout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateHallRequest) Reset() {
+	*x = CreateHallRequest{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateHallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateHallRequest) ProtoMessage() {}
+
+func (x *CreateHallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateHallRequest.ProtoReflect.Descriptor instead.
+func (*CreateHallRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateHallRequest) GetCinemaId() uint64 {
+	if x != nil {
+		return x.CinemaId
+	}
+	return 0
+}
+
+func (x *CreateHallRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateHallRequest) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *CreateHallRequest) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *CreateHallRequest) GetLayout() *HallLayout {
+	if x != nil {
+		return x.Layout
+	}
+	return nil
+}
+
+type UpdateHallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CinemaId      uint64                 `protobuf:"varint,2,opt,name=cinema_id,json=cinemaId,proto3" json:"cinema_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Rows          int32                  `protobuf:"varint,4,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          int32                  `protobuf:"varint,5,opt,name=cols,proto3" json:"cols,omitempty"`
+	Layout        *HallLayout            `protobuf:"bytes,6,opt,name=layout,proto3" json:"layout,omitempty"`
+	Version       uint64                 `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateHallRequest) Reset() {
+	*x = UpdateHallRequest{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateHallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateHallRequest) ProtoMessage() {}
+
+func (x *UpdateHallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateHallRequest.ProtoReflect.Descriptor instead.
+func (*UpdateHallRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateHallRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateHallRequest) GetCinemaId() uint64 {
+	if x != nil {
+		return x.CinemaId
+	}
+	return 0
+}
+
+func (x *UpdateHallRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateHallRequest) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *UpdateHallRequest) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *UpdateHallRequest) GetLayout() *HallLayout {
+	if x != nil {
+		return x.Layout
+	}
+	return nil
+}
+
+func (x *UpdateHallRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type PatchHallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Layout        *HallLayout            `protobuf:"bytes,3,opt,name=layout,proto3" json:"layout,omitempty"`
+	Version       uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchHallRequest) Reset() {
+	*x = PatchHallRequest{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchHallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchHallRequest) ProtoMessage() {}
+
+func (x *PatchHallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchHallRequest.ProtoReflect.Descriptor instead.
+func (*PatchHallRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PatchHallRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PatchHallRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *PatchHallRequest) GetLayout() *HallLayout {
+	if x != nil {
+		return x.Layout
+	}
+	return nil
+}
+
+func (x *PatchHallRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type DeleteHallRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteHallRequest) Reset() {
+	*x = DeleteHallRequest{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteHallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteHallRequest) ProtoMessage() {}
+
+func (x *DeleteHallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteHallRequest.ProtoReflect.Descriptor instead.
+func (*DeleteHallRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteHallRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteHallResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteHallResponse) Reset() {
+	*x = DeleteHallResponse{}
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteHallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteHallResponse) ProtoMessage() {}
+
+func (x *DeleteHallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_hall_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteHallResponse.ProtoReflect.Descriptor instead.
+func (*DeleteHallResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_hall_proto_rawDescGZIP(), []int{11}
+}
+
+var File_moviereservation_v1_hall_proto protoreflect.FileDescriptor
+
+const file_moviereservation_v1_hall_proto_rawDesc = "" +
+	"\n" +
+	"\x1emoviereservation/v1/hall.proto\x12\x13moviereservation.v1\"\xe1\x01\n" +
+	"\x04Hall\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1b\n" +
+	"\tcinema_id\x18\x02 \x01(\x04R\bcinemaId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"seat_count\x18\x04 \x01(\x05R\tseatCount\x12\x12\n" +
+	"\x04rows\x18\x05 \x01(\x05R\x04rows\x12\x12\n" +
+	"\x04cols\x18\x06 \x01(\x05R\x04cols\x127\n" +
+	"\x06layout\x18\a \x01(\v2\x1f.moviereservation.v1.HallLayoutR\x06layout\x12\x18\n" +
+	"\aversion\x18\b \x01(\x04R\aversion\">\n" +
+	"\n" +
+	"HallLayout\x120\n" +
+	"\x04rows\x18\x01 \x03(\v2\x1c.moviereservation.v1.HallRowR\x04rows\"l\n" +
+	"\aHallRow\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x16\n" +
+	"\x06curved\x18\x02 \x01(\bR\x06curved\x123\n" +
+	"\x05cells\x18\x03 \x03(\v2\x1d.moviereservation.v1.HallCellR\x05cells\"|\n" +
+	"\bHallCell\x121\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1d.moviereservation.v1.CellKindR\x04kind\x12=\n" +
+	"\bcategory\x18\x02 \x01(\x0e2!.moviereservation.v1.SeatCategoryR\bcategory\"/\n" +
+	"\x10ListHallsRequest\x12\x1b\n" +
+	"\tcinema_id\x18\x01 \x01(\x04R\bcinemaId\"D\n" +
+	"\x11ListHallsResponse\x12/\n" +
+	"\x05halls\x18\x01 \x03(\v2\x19.moviereservation.v1.HallR\x05halls\" \n" +
+	"\x0eGetHallRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\xa5\x01\n" +
+	"\x11CreateHallRequest\x12\x1b\n" +
+	"\tcinema_id\x18\x01 \x01(\x04R\bcinemaId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04rows\x18\x03 \x01(\x05R\x04rows\x12\x12\n" +
+	"\x04cols\x18\x04 \x01(\x05R\x04cols\x127\n" +
+	"\x06layout\x18\x05 \x01(\v2\x1f.moviereservation.v1.HallLayoutR\x06layout\"\xcf\x01\n" +
+	"\x11UpdateHallRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1b\n" +
+	"\tcinema_id\x18\x02 \x01(\x04R\bcinemaId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04rows\x18\x04 \x01(\x05R\x04rows\x12\x12\n" +
+	"\x04cols\x18\x05 \x01(\x05R\x04cols\x127\n" +
+	"\x06layout\x18\x06 \x01(\v2\x1f.moviereservation.v1.HallLayoutR\x06layout\x12\x18\n" +
+	"\aversion\x18\a \x01(\x04R\aversion\"\x97\x01\n" +
+	"\x10PatchHallRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x127\n" +
+	"\x06layout\x18\x03 \x01(\v2\x1f.moviereservation.v1.HallLayoutR\x06layout\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversionB\a\n" +
+	"\x05_name\"#\n" +
+	"\x11DeleteHallRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x14\n" +
+	"\x12DeleteHallResponse*a\n" +
+	"\bCellKind\x12\x19\n" +
+	"\x15CELL_KIND_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eCELL_KIND_SEAT\x10\x01\x12\x13\n" +
+	"\x0fCELL_KIND_AISLE\x10\x02\x12\x11\n" +
+	"\rCELL_KIND_GAP\x10\x03*\x9f\x01\n" +
+	"\fSeatCategory\x12\x1d\n" +
+	"\x19SEAT_CATEGORY_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16SEAT_CATEGORY_STANDARD\x10\x01\x12\x19\n" +
+	"\x15SEAT_CATEGORY_PREMIUM\x10\x02\x12\x1c\n" +
+	"\x18SEAT_CATEGORY_WHEELCHAIR\x10\x03\x12\x1b\n" +
+	"\x17SEAT_CATEGORY_COMPANION\x10\x042\x84\x04\n" +
+	"\vHallService\x12Z\n" +
+	"\tListHalls\x12%.moviereservation.v1.ListHallsRequest\x1a&.moviereservation.v1.ListHallsResponse\x12I\n" +
+	"\aGetHall\x12#.moviereservation.v1.GetHallRequest\x1a\x19.moviereservation.v1.Hall\x12O\n" +
+	"\n" +
+	"CreateHall\x12&.moviereservation.v1.CreateHallRequest\x1a\x19.moviereservation.v1.Hall\x12O\n" +
+	"\n" +
+	"UpdateHall\x12&.moviereservation.v1.UpdateHallRequest\x1a\x19.moviereservation.v1.Hall\x12M\n" +
+	"\tPatchHall\x12%.moviereservation.v1.PatchHallRequest\x1a\x19.moviereservation.v1.Hall\x12]\n" +
+	"\n" +
+	"DeleteHall\x12&.moviereservation.v1.DeleteHallRequest\x1a'.moviereservation.v1.DeleteHallResponseBYZWgithub.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1;moviereservationv1b\x06proto3"
+
+var (
+	file_moviereservation_v1_hall_proto_rawDescOnce sync.Once
+	file_moviereservation_v1_hall_proto_rawDescData []byte
+)
+
+func file_moviereservation_v1_hall_proto_rawDescGZIP() []byte {
+	file_moviereservation_v1_hall_proto_rawDescOnce.Do(func() {
+		file_moviereservation_v1_hall_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_moviereservation_v1_hall_proto_rawDesc), len(file_moviereservation_v1_hall_proto_rawDesc)))
+	})
+	return file_moviereservation_v1_hall_proto_rawDescData
+}
+
+var file_moviereservation_v1_hall_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_moviereservation_v1_hall_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_moviereservation_v1_hall_proto_goTypes = []any{
+	(CellKind)(0),              // 0: moviereservation.v1.CellKind
+	(SeatCategory)(0),          // 1: moviereservation.v1.SeatCategory
+	(*Hall)(nil),               // 2: moviereservation.v1.Hall
+	(*HallLayout)(nil),         // 3: moviereservation.v1.HallLayout
+	(*HallRow)(nil),            // 4: moviereservation.v1.HallRow
+	(*HallCell)(nil),           // 5: moviereservation.v1.HallCell
+	(*ListHallsRequest)(nil),   // 6: moviereservation.v1.ListHallsRequest
+	(*ListHallsResponse)(nil),  // 7: moviereservation.v1.ListHallsResponse
+	(*GetHallRequest)(nil),     // 8: moviereservation.v1.GetHallRequest
+	(*CreateHallRequest)(nil),  // 9: moviereservation.v1.CreateHallRequest
+	(*UpdateHallRequest)(nil),  // 10: moviereservation.v1.UpdateHallRequest
+	(*PatchHallRequest)(nil),   // 11: moviereservation.v1.PatchHallRequest
+	(*DeleteHallRequest)(nil),  // 12: moviereservation.v1.DeleteHallRequest
+	(*DeleteHallResponse)(nil), // 13: moviereservation.v1.DeleteHallResponse
+}
+var file_moviereservation_v1_hall_proto_depIdxs = []int32{
+	3,  // 0: moviereservation.v1.Hall.layout:type_name -> moviereservation.v1.HallLayout
+	4,  // 1: moviereservation.v1.HallLayout.rows:type_name -> moviereservation.v1.HallRow
+	5,  // 2: moviereservation.v1.HallRow.cells:type_name -> moviereservation.v1.HallCell
+	0,  // 3: moviereservation.v1.HallCell.kind:type_name -> moviereservation.v1.CellKind
+	1,  // 4: moviereservation.v1.HallCell.category:type_name -> moviereservation.v1.SeatCategory
+	2,  // 5: moviereservation.v1.ListHallsResponse.halls:type_name -> moviereservation.v1.Hall
+	3,  // 6: moviereservation.v1.CreateHallRequest.layout:type_name -> moviereservation.v1.HallLayout
+	3,  // 7: moviereservation.v1.UpdateHallRequest.layout:type_name -> moviereservation.v1.HallLayout
+	3,  // 8: moviereservation.v1.PatchHallRequest.layout:type_name -> moviereservation.v1.HallLayout
+	6,  // 9: moviereservation.v1.HallService.ListHalls:input_type -> moviereservation.v1.ListHallsRequest
+	8,  // 10: moviereservation.v1.HallService.GetHall:input_type -> moviereservation.v1.GetHallRequest
+	9,  // 11: moviereservation.v1.HallService.CreateHall:input_type -> moviereservation.v1.CreateHallRequest
+	10, // 12: moviereservation.v1.HallService.UpdateHall:input_type -> moviereservation.v1.UpdateHallRequest
+	11, // 13: moviereservation.v1.HallService.PatchHall:input_type -> moviereservation.v1.PatchHallRequest
+	12, // 14: moviereservation.v1.HallService.DeleteHall:input_type -> moviereservation.v1.DeleteHallRequest
+	7,  // 15: moviereservation.v1.HallService.ListHalls:output_type -> moviereservation.v1.ListHallsResponse
+	2,  // 16: moviereservation.v1.HallService.GetHall:output_type -> moviereservation.v1.Hall
+	2,  // 17: moviereservation.v1.HallService.CreateHall:output_type -> moviereservation.v1.Hall
+	2,  // 18: moviereservation.v1.HallService.UpdateHall:output_type -> moviereservation.v1.Hall
+	2,  // 19: moviereservation.v1.HallService.PatchHall:output_type -> moviereservation.v1.Hall
+	13, // 20: moviereservation.v1.HallService.DeleteHall:output_type -> moviereservation.v1.DeleteHallResponse
+	15, // [15:21] is the sub-list for method output_type
+	9,  // [9:15] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_moviereservation_v1_hall_proto_init() }
+func file_moviereservation_v1_hall_proto_init() {
+	if File_moviereservation_v1_hall_proto != nil {
+		return
+	}
+	file_moviereservation_v1_hall_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_moviereservation_v1_hall_proto_rawDesc), len(file_moviereservation_v1_hall_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_moviereservation_v1_hall_proto_goTypes,
+		DependencyIndexes: file_moviereservation_v1_hall_proto_depIdxs,
+		EnumInfos:         file_moviereservation_v1_hall_proto_enumTypes,
+		MessageInfos:      file_moviereservation_v1_hall_proto_msgTypes,
+	}.Build()
+	File_moviereservation_v1_hall_proto = out.File
+	file_moviereservation_v1_hall_proto_goTypes = nil
+	file_moviereservation_v1_hall_proto_depIdxs = nil
+}
@@ -0,0 +1,323 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: moviereservation/v1/hall.proto
+
+package moviereservationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	HallService_ListHalls_FullMethodName  = "/moviereservation.v1.HallService/ListHalls"
+	HallService_GetHall_FullMethodName    = "/moviereservation.v1.HallService/GetHall"
+	HallService_CreateHall_FullMethodName = "/moviereservation.v1.HallService/CreateHall"
+	HallService_UpdateHall_FullMethodName = "/moviereservation.v1.HallService/UpdateHall"
+	HallService_PatchHall_FullMethodName  = "/moviereservation.v1.HallService/PatchHall"
+	HallService_DeleteHall_FullMethodName = "/moviereservation.v1.HallService/DeleteHall"
+)
+
+// HallServiceClient is the client API for HallService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HallService manages the halls of the cinemas and their seating plans,
+// changing them needs the halls:manage permission
+type HallServiceClient interface {
+	ListHalls(ctx context.Context, in *ListHallsRequest, opts ...grpc.CallOption) (*ListHallsResponse, error)
+	GetHall(ctx context.Context, in *GetHallRequest, opts ...grpc.CallOption) (*Hall, error)
+	CreateHall(ctx context.Context, in *CreateHallRequest, opts ...grpc.CallOption) (*Hall, error)
+	// UpdateHall replaces the hall, which is refused while it has showtimes
+	UpdateHall(ctx context.Context, in *UpdateHallRequest, opts ...grpc.CallOption) (*Hall, error)
+	// PatchHall changes the fields that are set
+	PatchHall(ctx context.Context, in *PatchHallRequest, opts ...grpc.CallOption) (*Hall, error)
+	// DeleteHall deletes a hall without showtimes
+	DeleteHall(ctx context.Context, in *DeleteHallRequest, opts ...grpc.CallOption) (*DeleteHallResponse, error)
+}
+
+type hallServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHallServiceClient(cc grpc.ClientConnInterface) HallServiceClient {
+	return &hallServiceClient{cc}
+}
+
+func (c *hallServiceClient) ListHalls(ctx context.Context, in *ListHallsRequest, opts ...grpc.CallOption) (*ListHallsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHallsResponse)
+	err := c.cc.Invoke(ctx, HallService_ListHalls_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hallServiceClient) GetHall(ctx context.Context, in *GetHallRequest, opts ...grpc.CallOption) (*Hall, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Hall)
+	err := c.cc.Invoke(ctx, HallService_GetHall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hallServiceClient) CreateHall(ctx context.Context, in *CreateHallRequest, opts ...grpc.CallOption) (*Hall, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Hall)
+	err := c.cc.Invoke(ctx, HallService_CreateHall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hallServiceClient) UpdateHall(ctx context.Context, in *UpdateHallRequest, opts ...grpc.CallOption) (*Hall, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Hall)
+	err := c.cc.Invoke(ctx, HallService_UpdateHall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hallServiceClient) PatchHall(ctx context.Context, in *PatchHallRequest, opts ...grpc.CallOption) (*Hall, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Hall)
+	err := c.cc.Invoke(ctx, HallService_PatchHall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hallServiceClient) DeleteHall(ctx context.Context, in *DeleteHallRequest, opts ...grpc.CallOption) (*DeleteHallResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteHallResponse)
+	err := c.cc.Invoke(ctx, HallService_DeleteHall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HallServiceServer is the server API for HallService service.
+// All implementations must embed UnimplementedHallServiceServer
+// for forward compatibility.
+//
+// HallService manages the halls of the cinemas and their seating plans,
+// changing them needs the halls:manage permission
+type HallServiceServer interface {
+	ListHalls(context.Context, *ListHallsRequest) (*ListHallsResponse, error)
+	GetHall(context.Context, *GetHallRequest) (*Hall, error)
+	CreateHall(context.Context, *CreateHallRequest) (*Hall, error)
+	// UpdateHall replaces the hall, which is refused while it has showtimes
+	UpdateHall(context.Context, *UpdateHallRequest) (*Hall, error)
+	// PatchHall changes the fields that are set
+	PatchHall(context.Context, *PatchHallRequest) (*Hall, error)
+	// DeleteHall deletes a hall without showtimes
+	DeleteHall(context.Context, *DeleteHallRequest) (*DeleteHallResponse, error)
+	mustEmbedUnimplementedHallServiceServer()
+}
+
+// UnimplementedHallServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHallServiceServer struct{}
+
+func (UnimplementedHallServiceServer) ListHalls(context.Context, *ListHallsRequest) (*ListHallsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHalls not implemented")
+}
+func (UnimplementedHallServiceServer) GetHall(context.Context, *GetHallRequest) (*Hall, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHall not implemented")
+}
+func (UnimplementedHallServiceServer) CreateHall(context.Context, *CreateHallRequest) (*Hall, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateHall not implemented")
+}
+func (UnimplementedHallServiceServer) UpdateHall(context.Context, *UpdateHallRequest) (*Hall, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateHall not implemented")
+}
+func (UnimplementedHallServiceServer) PatchHall(context.Context, *PatchHallRequest) (*Hall, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchHall not implemented")
+}
+func (UnimplementedHallServiceServer) DeleteHall(context.Context, *DeleteHallRequest) (*DeleteHallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteHall not implemented")
+}
+func (UnimplementedHallServiceServer) mustEmbedUnimplementedHallServiceServer() {}
+func (UnimplementedHallServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeHallServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HallServiceServer will
+// result in compilation errors.
+type UnsafeHallServiceServer interface {
+	mustEmbedUnimplementedHallServiceServer()
+}
+
+func RegisterHallServiceServer(s grpc.ServiceRegistrar, srv HallServiceServer) {
+	// If the following call pancis, it indicates UnimplementedHallServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HallService_ServiceDesc, srv)
+}
+
+func _HallService_ListHalls_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHallsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HallServiceServer).ListHalls(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HallService_ListHalls_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HallServiceServer).ListHalls(ctx, req.(*ListHallsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HallService_GetHall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HallServiceServer).GetHall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HallService_GetHall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HallServiceServer).GetHall(ctx, req.(*GetHallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HallService_CreateHall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateHallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HallServiceServer).CreateHall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HallService_CreateHall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HallServiceServer).CreateHall(ctx, req.(*CreateHallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HallService_UpdateHall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateHallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HallServiceServer).UpdateHall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HallService_UpdateHall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HallServiceServer).UpdateHall(ctx, req.(*UpdateHallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HallService_PatchHall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchHallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HallServiceServer).PatchHall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HallService_PatchHall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HallServiceServer).PatchHall(ctx, req.(*PatchHallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HallService_DeleteHall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteHallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HallServiceServer).DeleteHall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HallService_DeleteHall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HallServiceServer).DeleteHall(ctx, req.(*DeleteHallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HallService_ServiceDesc is the grpc.ServiceDesc for HallService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HallService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moviereservation.v1.HallService",
+	HandlerType: (*HallServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListHalls",
+			Handler:    _HallService_ListHalls_Handler,
+		},
+		{
+			MethodName: "GetHall",
+			Handler:    _HallService_GetHall_Handler,
+		},
+		{
+			MethodName: "CreateHall",
+			Handler:    _HallService_CreateHall_Handler,
+		},
+		{
+			MethodName: "UpdateHall",
+			Handler:    _HallService_UpdateHall_Handler,
+		},
+		{
+			MethodName: "PatchHall",
+			Handler:    _HallService_PatchHall_Handler,
+		},
+		{
+			MethodName: "DeleteHall",
+			Handler:    _HallService_DeleteHall_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "moviereservation/v1/hall.proto",
+}
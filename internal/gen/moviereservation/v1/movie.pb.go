@@ -0,0 +1,555 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: moviereservation/v1/movie.proto
+
+package moviereservationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Movie struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Version     uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	// 0 when it isn't known
+	RuntimeMinutes uint32 `protobuf:"varint,5,opt,name=runtime_minutes,json=runtimeMinutes,proto3" json:"runtime_minutes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Movie) Reset() {
+	*x = Movie{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Movie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Movie) ProtoMessage() {}
+
+func (x *Movie) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Movie.ProtoReflect.Descriptor instead.
+func (*Movie) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Movie) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Movie) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Movie) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Movie) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Movie) GetRuntimeMinutes() uint32 {
+	if x != nil {
+		return x.RuntimeMinutes
+	}
+	return 0
+}
+
+type ListMoviesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// only the movies shown in the cinema, 0 for all movies
+	CinemaId      uint64 `protobuf:"varint,1,opt,name=cinema_id,json=cinemaId,proto3" json:"cinema_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMoviesRequest) Reset() {
+	*x = ListMoviesRequest{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMoviesRequest) ProtoMessage() {}
+
+func (x *ListMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMoviesRequest.ProtoReflect.Descriptor instead.
+func (*ListMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListMoviesRequest) GetCinemaId() uint64 {
+	if x != nil {
+		return x.CinemaId
+	}
+	return 0
+}
+
+type ListMoviesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Movies        []*Movie               `protobuf:"bytes,1,rep,name=movies,proto3" json:"movies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMoviesResponse) Reset() {
+	*x = ListMoviesResponse{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMoviesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMoviesResponse) ProtoMessage() {}
+
+func (x *ListMoviesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMoviesResponse.ProtoReflect.Descriptor instead.
+func (*ListMoviesResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListMoviesResponse) GetMovies() []*Movie {
+	if x != nil {
+		return x.Movies
+	}
+	return nil
+}
+
+type GetMovieRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMovieRequest) Reset() {
+	*x = GetMovieRequest{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMovieRequest) ProtoMessage() {}
+
+func (x *GetMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMovieRequest.ProtoReflect.Descriptor instead.
+func (*GetMovieRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetMovieRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateMovieRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Title          string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description    string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	RuntimeMinutes uint32                 `protobuf:"varint,3,opt,name=runtime_minutes,json=runtimeMinutes,proto3" json:"runtime_minutes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateMovieRequest) Reset() {
+	*x = CreateMovieRequest{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMovieRequest) ProtoMessage() {}
+
+func (x *CreateMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMovieRequest.ProtoReflect.Descriptor instead.
+func (*CreateMovieRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateMovieRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateMovieRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateMovieRequest) GetRuntimeMinutes() uint32 {
+	if x != nil {
+		return x.RuntimeMinutes
+	}
+	return 0
+}
+
+type UpdateMovieRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title          string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description    string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Version        uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	RuntimeMinutes uint32                 `protobuf:"varint,5,opt,name=runtime_minutes,json=runtimeMinutes,proto3" json:"runtime_minutes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateMovieRequest) Reset() {
+	*x = UpdateMovieRequest{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMovieRequest) ProtoMessage() {}
+
+func (x *UpdateMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMovieRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMovieRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateMovieRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateMovieRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateMovieRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateMovieRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateMovieRequest) GetRuntimeMinutes() uint32 {
+	if x != nil {
+		return x.RuntimeMinutes
+	}
+	return 0
+}
+
+type PatchMovieRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title          *string                `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description    *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Version        uint64                 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	RuntimeMinutes *uint32                `protobuf:"varint,5,opt,name=runtime_minutes,json=runtimeMinutes,proto3,oneof" json:"runtime_minutes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PatchMovieRequest) Reset() {
+	*x = PatchMovieRequest{}
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchMovieRequest) ProtoMessage() {}
+
+func (x *PatchMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_movie_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchMovieRequest.ProtoReflect.Descriptor instead.
+func (*PatchMovieRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_movie_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PatchMovieRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PatchMovieRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *PatchMovieRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *PatchMovieRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PatchMovieRequest) GetRuntimeMinutes() uint32 {
+	if x != nil && x.RuntimeMinutes != nil {
+		return *x.RuntimeMinutes
+	}
+	return 0
+}
+
+var File_moviereservation_v1_movie_proto protoreflect.FileDescriptor
+
+const file_moviereservation_v1_movie_proto_rawDesc = "" +
+	"\n" +
+	"\x1fmoviereservation/v1/movie.proto\x12\x13moviereservation.v1\"\x92\x01\n" +
+	"\x05Movie\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\x12'\n" +
+	"\x0fruntime_minutes\x18\x05 \x01(\rR\x0eruntimeMinutes\"0\n" +
+	"\x11ListMoviesRequest\x12\x1b\n" +
+	"\tcinema_id\x18\x01 \x01(\x04R\bcinemaId\"H\n" +
+	"\x12ListMoviesResponse\x122\n" +
+	"\x06movies\x18\x01 \x03(\v2\x1a.moviereservation.v1.MovieR\x06movies\"!\n" +
+	"\x0fGetMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"u\n" +
+	"\x12CreateMovieRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12'\n" +
+	"\x0fruntime_minutes\x18\x03 \x01(\rR\x0eruntimeMinutes\"\x9f\x01\n" +
+	"\x12UpdateMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\x12'\n" +
+	"\x0fruntime_minutes\x18\x05 \x01(\rR\x0eruntimeMinutes\"\xdb\x01\n" +
+	"\x11PatchMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x19\n" +
+	"\x05title\x18\x02 \x01(\tH\x00R\x05title\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x01R\vdescription\x88\x01\x01\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\x12,\n" +
+	"\x0fruntime_minutes\x18\x05 \x01(\rH\x02R\x0eruntimeMinutes\x88\x01\x01B\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_descriptionB\x12\n" +
+	"\x10_runtime_minutes2\xb5\x03\n" +
+	"\fMovieService\x12]\n" +
+	"\n" +
+	"ListMovies\x12&.moviereservation.v1.ListMoviesRequest\x1a'.moviereservation.v1.ListMoviesResponse\x12L\n" +
+	"\bGetMovie\x12$.moviereservation.v1.GetMovieRequest\x1a\x1a.moviereservation.v1.Movie\x12R\n" +
+	"\vCreateMovie\x12'.moviereservation.v1.CreateMovieRequest\x1a\x1a.moviereservation.v1.Movie\x12R\n" +
+	"\vUpdateMovie\x12'.moviereservation.v1.UpdateMovieRequest\x1a\x1a.moviereservation.v1.Movie\x12P\n" +
+	"\n" +
+	"PatchMovie\x12&.moviereservation.v1.PatchMovieRequest\x1a\x1a.moviereservation.v1.MovieBYZWgithub.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1;moviereservationv1b\x06proto3"
+
+var (
+	file_moviereservation_v1_movie_proto_rawDescOnce sync.Once
+	file_moviereservation_v1_movie_proto_rawDescData []byte
+)
+
+func file_moviereservation_v1_movie_proto_rawDescGZIP() []byte {
+	file_moviereservation_v1_movie_proto_rawDescOnce.Do(func() {
+		file_moviereservation_v1_movie_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_moviereservation_v1_movie_proto_rawDesc), len(file_moviereservation_v1_movie_proto_rawDesc)))
+	})
+	return file_moviereservation_v1_movie_proto_rawDescData
+}
+
+var file_moviereservation_v1_movie_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_moviereservation_v1_movie_proto_goTypes = []any{
+	(*Movie)(nil),              // 0: moviereservation.v1.Movie
+	(*ListMoviesRequest)(nil),  // 1: moviereservation.v1.ListMoviesRequest
+	(*ListMoviesResponse)(nil), // 2: moviereservation.v1.ListMoviesResponse
+	(*GetMovieRequest)(nil),    // 3: moviereservation.v1.GetMovieRequest
+	(*CreateMovieRequest)(nil), // 4: moviereservation.v1.CreateMovieRequest
+	(*UpdateMovieRequest)(nil), // 5: moviereservation.v1.UpdateMovieRequest
+	(*PatchMovieRequest)(nil),  // 6: moviereservation.v1.PatchMovieRequest
+}
+var file_moviereservation_v1_movie_proto_depIdxs = []int32{
+	0, // 0: moviereservation.v1.ListMoviesResponse.movies:type_name -> moviereservation.v1.Movie
+	1, // 1: moviereservation.v1.MovieService.ListMovies:input_type -> moviereservation.v1.ListMoviesRequest
+	3, // 2: moviereservation.v1.MovieService.GetMovie:input_type -> moviereservation.v1.GetMovieRequest
+	4, // 3: moviereservation.v1.MovieService.CreateMovie:input_type -> moviereservation.v1.CreateMovieRequest
+	5, // 4: moviereservation.v1.MovieService.UpdateMovie:input_type -> moviereservation.v1.UpdateMovieRequest
+	6, // 5: moviereservation.v1.MovieService.PatchMovie:input_type -> moviereservation.v1.PatchMovieRequest
+	2, // 6: moviereservation.v1.MovieService.ListMovies:output_type -> moviereservation.v1.ListMoviesResponse
+	0, // 7: moviereservation.v1.MovieService.GetMovie:output_type -> moviereservation.v1.Movie
+	0, // 8: moviereservation.v1.MovieService.CreateMovie:output_type -> moviereservation.v1.Movie
+	0, // 9: moviereservation.v1.MovieService.UpdateMovie:output_type -> moviereservation.v1.Movie
+	0, // 10: moviereservation.v1.MovieService.PatchMovie:output_type -> moviereservation.v1.Movie
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_moviereservation_v1_movie_proto_init() }
+func file_moviereservation_v1_movie_proto_init() {
+	if File_moviereservation_v1_movie_proto != nil {
+		return
+	}
+	file_moviereservation_v1_movie_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_moviereservation_v1_movie_proto_rawDesc), len(file_moviereservation_v1_movie_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_moviereservation_v1_movie_proto_goTypes,
+		DependencyIndexes: file_moviereservation_v1_movie_proto_depIdxs,
+		MessageInfos:      file_moviereservation_v1_movie_proto_msgTypes,
+	}.Build()
+	File_moviereservation_v1_movie_proto = out.File
+	file_moviereservation_v1_movie_proto_goTypes = nil
+	file_moviereservation_v1_movie_proto_depIdxs = nil
+}
@@ -0,0 +1,1310 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: moviereservation/v1/reservation.proto
+
+package moviereservationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SeatStatus int32
+
+const (
+	SeatStatus_SEAT_STATUS_UNSPECIFIED SeatStatus = 0
+	SeatStatus_SEAT_STATUS_AVAILABLE   SeatStatus = 1
+	SeatStatus_SEAT_STATUS_RESERVED    SeatStatus = 2
+	// blocked seats and all seats of a hall under maintenance
+	SeatStatus_SEAT_STATUS_BLOCKED SeatStatus = 3
+)
+
+// Enum value maps for SeatStatus.
+var (
+	SeatStatus_name = map[int32]string{
+		0: "SEAT_STATUS_UNSPECIFIED",
+		1: "SEAT_STATUS_AVAILABLE",
+		2: "SEAT_STATUS_RESERVED",
+		3: "SEAT_STATUS_BLOCKED",
+	}
+	SeatStatus_value = map[string]int32{
+		"SEAT_STATUS_UNSPECIFIED": 0,
+		"SEAT_STATUS_AVAILABLE":   1,
+		"SEAT_STATUS_RESERVED":    2,
+		"SEAT_STATUS_BLOCKED":     3,
+	}
+)
+
+func (x SeatStatus) Enum() *SeatStatus {
+	p := new(SeatStatus)
+	*p = x
+	return p
+}
+
+func (x SeatStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SeatStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_moviereservation_v1_reservation_proto_enumTypes[0].Descriptor()
+}
+
+func (SeatStatus) Type() protoreflect.EnumType {
+	return &file_moviereservation_v1_reservation_proto_enumTypes[0]
+}
+
+func (x SeatStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SeatStatus.Descriptor instead.
+func (SeatStatus) EnumDescriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{0}
+}
+
+// Reservation belongs to user_id, or to the guest for a reservation without an account
+type Reservation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ShowtimeId    uint64                 `protobuf:"varint,2,opt,name=showtime_id,json=showtimeId,proto3" json:"showtime_id,omitempty"`
+	SeatId        uint64                 `protobuf:"varint,3,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`
+	UserId        *uint64                `protobuf:"varint,4,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	Guest         *Guest                 `protobuf:"bytes,5,opt,name=guest,proto3" json:"guest,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reservation) Reset() {
+	*x = Reservation{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reservation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reservation) ProtoMessage() {}
+
+func (x *Reservation) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reservation.ProtoReflect.Descriptor instead.
+func (*Reservation) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Reservation) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Reservation) GetShowtimeId() uint64 {
+	if x != nil {
+		return x.ShowtimeId
+	}
+	return 0
+}
+
+func (x *Reservation) GetSeatId() uint64 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+func (x *Reservation) GetUserId() uint64 {
+	if x != nil && x.UserId != nil {
+		return *x.UserId
+	}
+	return 0
+}
+
+func (x *Reservation) GetGuest() *Guest {
+	if x != nil {
+		return x.Guest
+	}
+	return nil
+}
+
+func (x *Reservation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Guest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Phone         string                 `protobuf:"bytes,3,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Guest) Reset() {
+	*x = Guest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Guest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Guest) ProtoMessage() {}
+
+func (x *Guest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Guest.ProtoReflect.Descriptor instead.
+func (*Guest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Guest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Guest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Guest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+// CaptchaSolution is the position the tile of a challenge from GET /captcha was dragged to,
+// only needed when the call was refused with captcha_required
+type CaptchaSolution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChallengeId   string                 `protobuf:"bytes,1,opt,name=challenge_id,json=challengeId,proto3" json:"challenge_id,omitempty"`
+	X             int32                  `protobuf:"varint,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,3,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptchaSolution) Reset() {
+	*x = CaptchaSolution{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptchaSolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptchaSolution) ProtoMessage() {}
+
+func (x *CaptchaSolution) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptchaSolution.ProtoReflect.Descriptor instead.
+func (*CaptchaSolution) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CaptchaSolution) GetChallengeId() string {
+	if x != nil {
+		return x.ChallengeId
+	}
+	return ""
+}
+
+func (x *CaptchaSolution) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *CaptchaSolution) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type CreateReservationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 for the signed-in user
+	UserId     uint64           `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ShowtimeId uint64           `protobuf:"varint,2,opt,name=showtime_id,json=showtimeId,proto3" json:"showtime_id,omitempty"`
+	SeatId     uint64           `protobuf:"varint,3,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`
+	Captcha    *CaptchaSolution `protobuf:"bytes,4,opt,name=captcha,proto3" json:"captcha,omitempty"`
+	// required while the showtime has an open waiting room of the HTTP API
+	AdmissionToken string `protobuf:"bytes,5,opt,name=admission_token,json=admissionToken,proto3" json:"admission_token,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateReservationRequest) Reset() {
+	*x = CreateReservationRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReservationRequest) ProtoMessage() {}
+
+func (x *CreateReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReservationRequest.ProtoReflect.Descriptor instead.
+func (*CreateReservationRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateReservationRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateReservationRequest) GetShowtimeId() uint64 {
+	if x != nil {
+		return x.ShowtimeId
+	}
+	return 0
+}
+
+func (x *CreateReservationRequest) GetSeatId() uint64 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+func (x *CreateReservationRequest) GetCaptcha() *CaptchaSolution {
+	if x != nil {
+		return x.Captcha
+	}
+	return nil
+}
+
+func (x *CreateReservationRequest) GetAdmissionToken() string {
+	if x != nil {
+		return x.AdmissionToken
+	}
+	return ""
+}
+
+type ListReservationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 for the signed-in user
+	UserId        uint64 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReservationsRequest) Reset() {
+	*x = ListReservationsRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReservationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReservationsRequest) ProtoMessage() {}
+
+func (x *ListReservationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReservationsRequest.ProtoReflect.Descriptor instead.
+func (*ListReservationsRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListReservationsRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ListReservationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reservations  []*Reservation         `protobuf:"bytes,1,rep,name=reservations,proto3" json:"reservations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReservationsResponse) Reset() {
+	*x = ListReservationsResponse{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReservationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReservationsResponse) ProtoMessage() {}
+
+func (x *ListReservationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReservationsResponse.ProtoReflect.Descriptor instead.
+func (*ListReservationsResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListReservationsResponse) GetReservations() []*Reservation {
+	if x != nil {
+		return x.Reservations
+	}
+	return nil
+}
+
+type GetReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReservationRequest) Reset() {
+	*x = GetReservationRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReservationRequest) ProtoMessage() {}
+
+func (x *GetReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReservationRequest.ProtoReflect.Descriptor instead.
+func (*GetReservationRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetReservationRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CancelReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelReservationRequest) Reset() {
+	*x = CancelReservationRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelReservationRequest) ProtoMessage() {}
+
+func (x *CancelReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelReservationRequest.ProtoReflect.Descriptor instead.
+func (*CancelReservationRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelReservationRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CancelReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelReservationResponse) Reset() {
+	*x = CancelReservationResponse{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelReservationResponse) ProtoMessage() {}
+
+func (x *CancelReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelReservationResponse.ProtoReflect.Descriptor instead.
+func (*CancelReservationResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{8}
+}
+
+type ClaimGuestReservationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimGuestReservationsRequest) Reset() {
+	*x = ClaimGuestReservationsRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimGuestReservationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimGuestReservationsRequest) ProtoMessage() {}
+
+func (x *ClaimGuestReservationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimGuestReservationsRequest.ProtoReflect.Descriptor instead.
+func (*ClaimGuestReservationsRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{9}
+}
+
+type ClaimGuestReservationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Claimed       int32                  `protobuf:"varint,1,opt,name=claimed,proto3" json:"claimed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimGuestReservationsResponse) Reset() {
+	*x = ClaimGuestReservationsResponse{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimGuestReservationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimGuestReservationsResponse) ProtoMessage() {}
+
+func (x *ClaimGuestReservationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimGuestReservationsResponse.ProtoReflect.Descriptor instead.
+func (*ClaimGuestReservationsResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClaimGuestReservationsResponse) GetClaimed() int32 {
+	if x != nil {
+		return x.Claimed
+	}
+	return 0
+}
+
+// at least one of email and phone is required
+type CreateGuestReservationRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Name       string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Email      string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Phone      string                 `protobuf:"bytes,3,opt,name=phone,proto3" json:"phone,omitempty"`
+	ShowtimeId uint64                 `protobuf:"varint,4,opt,name=showtime_id,json=showtimeId,proto3" json:"showtime_id,omitempty"`
+	SeatId     uint64                 `protobuf:"varint,5,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`
+	Captcha    *CaptchaSolution       `protobuf:"bytes,6,opt,name=captcha,proto3" json:"captcha,omitempty"`
+	// required while the showtime has an open waiting room of the HTTP API
+	AdmissionToken string `protobuf:"bytes,7,opt,name=admission_token,json=admissionToken,proto3" json:"admission_token,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateGuestReservationRequest) Reset() {
+	*x = CreateGuestReservationRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGuestReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGuestReservationRequest) ProtoMessage() {}
+
+func (x *CreateGuestReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGuestReservationRequest.ProtoReflect.Descriptor instead.
+func (*CreateGuestReservationRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateGuestReservationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateGuestReservationRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateGuestReservationRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *CreateGuestReservationRequest) GetShowtimeId() uint64 {
+	if x != nil {
+		return x.ShowtimeId
+	}
+	return 0
+}
+
+func (x *CreateGuestReservationRequest) GetSeatId() uint64 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+func (x *CreateGuestReservationRequest) GetCaptcha() *CaptchaSolution {
+	if x != nil {
+		return x.Captcha
+	}
+	return nil
+}
+
+func (x *CreateGuestReservationRequest) GetAdmissionToken() string {
+	if x != nil {
+		return x.AdmissionToken
+	}
+	return ""
+}
+
+type CreateGuestReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reservation   *Reservation           `protobuf:"bytes,1,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	LookupCode    string                 `protobuf:"bytes,2,opt,name=lookup_code,json=lookupCode,proto3" json:"lookup_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGuestReservationResponse) Reset() {
+	*x = CreateGuestReservationResponse{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGuestReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGuestReservationResponse) ProtoMessage() {}
+
+func (x *CreateGuestReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGuestReservationResponse.ProtoReflect.Descriptor instead.
+func (*CreateGuestReservationResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateGuestReservationResponse) GetReservation() *Reservation {
+	if x != nil {
+		return x.Reservation
+	}
+	return nil
+}
+
+func (x *CreateGuestReservationResponse) GetLookupCode() string {
+	if x != nil {
+		return x.LookupCode
+	}
+	return ""
+}
+
+type GetGuestReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LookupCode    string                 `protobuf:"bytes,1,opt,name=lookup_code,json=lookupCode,proto3" json:"lookup_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGuestReservationRequest) Reset() {
+	*x = GetGuestReservationRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGuestReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGuestReservationRequest) ProtoMessage() {}
+
+func (x *GetGuestReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGuestReservationRequest.ProtoReflect.Descriptor instead.
+func (*GetGuestReservationRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetGuestReservationRequest) GetLookupCode() string {
+	if x != nil {
+		return x.LookupCode
+	}
+	return ""
+}
+
+type CancelGuestReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LookupCode    string                 `protobuf:"bytes,1,opt,name=lookup_code,json=lookupCode,proto3" json:"lookup_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelGuestReservationRequest) Reset() {
+	*x = CancelGuestReservationRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelGuestReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelGuestReservationRequest) ProtoMessage() {}
+
+func (x *CancelGuestReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelGuestReservationRequest.ProtoReflect.Descriptor instead.
+func (*CancelGuestReservationRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CancelGuestReservationRequest) GetLookupCode() string {
+	if x != nil {
+		return x.LookupCode
+	}
+	return ""
+}
+
+type CancelGuestReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelGuestReservationResponse) Reset() {
+	*x = CancelGuestReservationResponse{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelGuestReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelGuestReservationResponse) ProtoMessage() {}
+
+func (x *CancelGuestReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelGuestReservationResponse.ProtoReflect.Descriptor instead.
+func (*CancelGuestReservationResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{15}
+}
+
+type GetSeatAvailabilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShowtimeId    uint64                 `protobuf:"varint,1,opt,name=showtime_id,json=showtimeId,proto3" json:"showtime_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSeatAvailabilityRequest) Reset() {
+	*x = GetSeatAvailabilityRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSeatAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSeatAvailabilityRequest) ProtoMessage() {}
+
+func (x *GetSeatAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSeatAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*GetSeatAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetSeatAvailabilityRequest) GetShowtimeId() uint64 {
+	if x != nil {
+		return x.ShowtimeId
+	}
+	return 0
+}
+
+type WatchSeatAvailabilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShowtimeId    uint64                 `protobuf:"varint,1,opt,name=showtime_id,json=showtimeId,proto3" json:"showtime_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSeatAvailabilityRequest) Reset() {
+	*x = WatchSeatAvailabilityRequest{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSeatAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSeatAvailabilityRequest) ProtoMessage() {}
+
+func (x *WatchSeatAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSeatAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*WatchSeatAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WatchSeatAvailabilityRequest) GetShowtimeId() uint64 {
+	if x != nil {
+		return x.ShowtimeId
+	}
+	return 0
+}
+
+type SeatAvailability struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShowtimeId    uint64                 `protobuf:"varint,1,opt,name=showtime_id,json=showtimeId,proto3" json:"showtime_id,omitempty"`
+	Seats         []*Seat                `protobuf:"bytes,2,rep,name=seats,proto3" json:"seats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SeatAvailability) Reset() {
+	*x = SeatAvailability{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeatAvailability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeatAvailability) ProtoMessage() {}
+
+func (x *SeatAvailability) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeatAvailability.ProtoReflect.Descriptor instead.
+func (*SeatAvailability) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SeatAvailability) GetShowtimeId() uint64 {
+	if x != nil {
+		return x.ShowtimeId
+	}
+	return 0
+}
+
+func (x *SeatAvailability) GetSeats() []*Seat {
+	if x != nil {
+		return x.Seats
+	}
+	return nil
+}
+
+type Seat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SeatId        uint64                 `protobuf:"varint,1,opt,name=seat_id,json=seatId,proto3" json:"seat_id,omitempty"`
+	Status        SeatStatus             `protobuf:"varint,2,opt,name=status,proto3,enum=moviereservation.v1.SeatStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Seat) Reset() {
+	*x = Seat{}
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Seat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Seat) ProtoMessage() {}
+
+func (x *Seat) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_reservation_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Seat.ProtoReflect.Descriptor instead.
+func (*Seat) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_reservation_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Seat) GetSeatId() uint64 {
+	if x != nil {
+		return x.SeatId
+	}
+	return 0
+}
+
+func (x *Seat) GetStatus() SeatStatus {
+	if x != nil {
+		return x.Status
+	}
+	return SeatStatus_SEAT_STATUS_UNSPECIFIED
+}
+
+var File_moviereservation_v1_reservation_proto protoreflect.FileDescriptor
+
+const file_moviereservation_v1_reservation_proto_rawDesc = "" +
+	"\n" +
+	"%moviereservation/v1/reservation.proto\x12\x13moviereservation.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xee\x01\n" +
+	"\vReservation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1f\n" +
+	"\vshowtime_id\x18\x02 \x01(\x04R\n" +
+	"showtimeId\x12\x17\n" +
+	"\aseat_id\x18\x03 \x01(\x04R\x06seatId\x12\x1c\n" +
+	"\auser_id\x18\x04 \x01(\x04H\x00R\x06userId\x88\x01\x01\x120\n" +
+	"\x05guest\x18\x05 \x01(\v2\x1a.moviereservation.v1.GuestR\x05guest\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\n" +
+	"\n" +
+	"\b_user_id\"G\n" +
+	"\x05Guest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x03 \x01(\tR\x05phone\"P\n" +
+	"\x0fCaptchaSolution\x12!\n" +
+	"\fchallenge_id\x18\x01 \x01(\tR\vchallengeId\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x05R\x01y\"\xd6\x01\n" +
+	"\x18CreateReservationRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12\x1f\n" +
+	"\vshowtime_id\x18\x02 \x01(\x04R\n" +
+	"showtimeId\x12\x17\n" +
+	"\aseat_id\x18\x03 \x01(\x04R\x06seatId\x12>\n" +
+	"\acaptcha\x18\x04 \x01(\v2$.moviereservation.v1.CaptchaSolutionR\acaptcha\x12'\n" +
+	"\x0fadmission_token\x18\x05 \x01(\tR\x0eadmissionToken\"2\n" +
+	"\x17ListReservationsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\"`\n" +
+	"\x18ListReservationsResponse\x12D\n" +
+	"\freservations\x18\x01 \x03(\v2 .moviereservation.v1.ReservationR\freservations\"'\n" +
+	"\x15GetReservationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"*\n" +
+	"\x18CancelReservationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x1b\n" +
+	"\x19CancelReservationResponse\"\x1f\n" +
+	"\x1dClaimGuestReservationsRequest\":\n" +
+	"\x1eClaimGuestReservationsResponse\x12\x18\n" +
+	"\aclaimed\x18\x01 \x01(\x05R\aclaimed\"\x82\x02\n" +
+	"\x1dCreateGuestReservationRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x03 \x01(\tR\x05phone\x12\x1f\n" +
+	"\vshowtime_id\x18\x04 \x01(\x04R\n" +
+	"showtimeId\x12\x17\n" +
+	"\aseat_id\x18\x05 \x01(\x04R\x06seatId\x12>\n" +
+	"\acaptcha\x18\x06 \x01(\v2$.moviereservation.v1.CaptchaSolutionR\acaptcha\x12'\n" +
+	"\x0fadmission_token\x18\a \x01(\tR\x0eadmissionToken\"\x85\x01\n" +
+	"\x1eCreateGuestReservationResponse\x12B\n" +
+	"\vreservation\x18\x01 \x01(\v2 .moviereservation.v1.ReservationR\vreservation\x12\x1f\n" +
+	"\vlookup_code\x18\x02 \x01(\tR\n" +
+	"lookupCode\"=\n" +
+	"\x1aGetGuestReservationRequest\x12\x1f\n" +
+	"\vlookup_code\x18\x01 \x01(\tR\n" +
+	"lookupCode\"@\n" +
+	"\x1dCancelGuestReservationRequest\x12\x1f\n" +
+	"\vlookup_code\x18\x01 \x01(\tR\n" +
+	"lookupCode\" \n" +
+	"\x1eCancelGuestReservationResponse\"=\n" +
+	"\x1aGetSeatAvailabilityRequest\x12\x1f\n" +
+	"\vshowtime_id\x18\x01 \x01(\x04R\n" +
+	"showtimeId\"?\n" +
+	"\x1cWatchSeatAvailabilityRequest\x12\x1f\n" +
+	"\vshowtime_id\x18\x01 \x01(\x04R\n" +
+	"showtimeId\"d\n" +
+	"\x10SeatAvailability\x12\x1f\n" +
+	"\vshowtime_id\x18\x01 \x01(\x04R\n" +
+	"showtimeId\x12/\n" +
+	"\x05seats\x18\x02 \x03(\v2\x19.moviereservation.v1.SeatR\x05seats\"X\n" +
+	"\x04Seat\x12\x17\n" +
+	"\aseat_id\x18\x01 \x01(\x04R\x06seatId\x127\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1f.moviereservation.v1.SeatStatusR\x06status*w\n" +
+	"\n" +
+	"SeatStatus\x12\x1b\n" +
+	"\x17SEAT_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15SEAT_STATUS_AVAILABLE\x10\x01\x12\x18\n" +
+	"\x14SEAT_STATUS_RESERVED\x10\x02\x12\x17\n" +
+	"\x13SEAT_STATUS_BLOCKED\x10\x032\x99\t\n" +
+	"\x12ReservationService\x12d\n" +
+	"\x11CreateReservation\x12-.moviereservation.v1.CreateReservationRequest\x1a .moviereservation.v1.Reservation\x12o\n" +
+	"\x10ListReservations\x12,.moviereservation.v1.ListReservationsRequest\x1a-.moviereservation.v1.ListReservationsResponse\x12^\n" +
+	"\x0eGetReservation\x12*.moviereservation.v1.GetReservationRequest\x1a .moviereservation.v1.Reservation\x12r\n" +
+	"\x11CancelReservation\x12-.moviereservation.v1.CancelReservationRequest\x1a..moviereservation.v1.CancelReservationResponse\x12\x81\x01\n" +
+	"\x16ClaimGuestReservations\x122.moviereservation.v1.ClaimGuestReservationsRequest\x1a3.moviereservation.v1.ClaimGuestReservationsResponse\x12\x81\x01\n" +
+	"\x16CreateGuestReservation\x122.moviereservation.v1.CreateGuestReservationRequest\x1a3.moviereservation.v1.CreateGuestReservationResponse\x12h\n" +
+	"\x13GetGuestReservation\x12/.moviereservation.v1.GetGuestReservationRequest\x1a .moviereservation.v1.Reservation\x12\x81\x01\n" +
+	"\x16CancelGuestReservation\x122.moviereservation.v1.CancelGuestReservationRequest\x1a3.moviereservation.v1.CancelGuestReservationResponse\x12m\n" +
+	"\x13GetSeatAvailability\x12/.moviereservation.v1.GetSeatAvailabilityRequest\x1a%.moviereservation.v1.SeatAvailability\x12s\n" +
+	"\x15WatchSeatAvailability\x121.moviereservation.v1.WatchSeatAvailabilityRequest\x1a%.moviereservation.v1.SeatAvailability0\x01BYZWgithub.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1;moviereservationv1b\x06proto3"
+
+var (
+	file_moviereservation_v1_reservation_proto_rawDescOnce sync.Once
+	file_moviereservation_v1_reservation_proto_rawDescData []byte
+)
+
+func file_moviereservation_v1_reservation_proto_rawDescGZIP() []byte {
+	file_moviereservation_v1_reservation_proto_rawDescOnce.Do(func() {
+		file_moviereservation_v1_reservation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_moviereservation_v1_reservation_proto_rawDesc), len(file_moviereservation_v1_reservation_proto_rawDesc)))
+	})
+	return file_moviereservation_v1_reservation_proto_rawDescData
+}
+
+var file_moviereservation_v1_reservation_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_moviereservation_v1_reservation_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_moviereservation_v1_reservation_proto_goTypes = []any{
+	(SeatStatus)(0),                        // 0: moviereservation.v1.SeatStatus
+	(*Reservation)(nil),                    // 1: moviereservation.v1.Reservation
+	(*Guest)(nil),                          // 2: moviereservation.v1.Guest
+	(*CaptchaSolution)(nil),                // 3: moviereservation.v1.CaptchaSolution
+	(*CreateReservationRequest)(nil),       // 4: moviereservation.v1.CreateReservationRequest
+	(*ListReservationsRequest)(nil),        // 5: moviereservation.v1.ListReservationsRequest
+	(*ListReservationsResponse)(nil),       // 6: moviereservation.v1.ListReservationsResponse
+	(*GetReservationRequest)(nil),          // 7: moviereservation.v1.GetReservationRequest
+	(*CancelReservationRequest)(nil),       // 8: moviereservation.v1.CancelReservationRequest
+	(*CancelReservationResponse)(nil),      // 9: moviereservation.v1.CancelReservationResponse
+	(*ClaimGuestReservationsRequest)(nil),  // 10: moviereservation.v1.ClaimGuestReservationsRequest
+	(*ClaimGuestReservationsResponse)(nil), // 11: moviereservation.v1.ClaimGuestReservationsResponse
+	(*CreateGuestReservationRequest)(nil),  // 12: moviereservation.v1.CreateGuestReservationRequest
+	(*CreateGuestReservationResponse)(nil), // 13: moviereservation.v1.CreateGuestReservationResponse
+	(*GetGuestReservationRequest)(nil),     // 14: moviereservation.v1.GetGuestReservationRequest
+	(*CancelGuestReservationRequest)(nil),  // 15: moviereservation.v1.CancelGuestReservationRequest
+	(*CancelGuestReservationResponse)(nil), // 16: moviereservation.v1.CancelGuestReservationResponse
+	(*GetSeatAvailabilityRequest)(nil),     // 17: moviereservation.v1.GetSeatAvailabilityRequest
+	(*WatchSeatAvailabilityRequest)(nil),   // 18: moviereservation.v1.WatchSeatAvailabilityRequest
+	(*SeatAvailability)(nil),               // 19: moviereservation.v1.SeatAvailability
+	(*Seat)(nil),                           // 20: moviereservation.v1.Seat
+	(*timestamppb.Timestamp)(nil),          // 21: google.protobuf.Timestamp
+}
+var file_moviereservation_v1_reservation_proto_depIdxs = []int32{
+	2,  // 0: moviereservation.v1.Reservation.guest:type_name -> moviereservation.v1.Guest
+	21, // 1: moviereservation.v1.Reservation.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 2: moviereservation.v1.CreateReservationRequest.captcha:type_name -> moviereservation.v1.CaptchaSolution
+	1,  // 3: moviereservation.v1.ListReservationsResponse.reservations:type_name -> moviereservation.v1.Reservation
+	3,  // 4: moviereservation.v1.CreateGuestReservationRequest.captcha:type_name -> moviereservation.v1.CaptchaSolution
+	1,  // 5: moviereservation.v1.CreateGuestReservationResponse.reservation:type_name -> moviereservation.v1.Reservation
+	20, // 6: moviereservation.v1.SeatAvailability.seats:type_name -> moviereservation.v1.Seat
+	0,  // 7: moviereservation.v1.Seat.status:type_name -> moviereservation.v1.SeatStatus
+	4,  // 8: moviereservation.v1.ReservationService.CreateReservation:input_type -> moviereservation.v1.CreateReservationRequest
+	5,  // 9: moviereservation.v1.ReservationService.ListReservations:input_type -> moviereservation.v1.ListReservationsRequest
+	7,  // 10: moviereservation.v1.ReservationService.GetReservation:input_type -> moviereservation.v1.GetReservationRequest
+	8,  // 11: moviereservation.v1.ReservationService.CancelReservation:input_type -> moviereservation.v1.CancelReservationRequest
+	10, // 12: moviereservation.v1.ReservationService.ClaimGuestReservations:input_type -> moviereservation.v1.ClaimGuestReservationsRequest
+	12, // 13: moviereservation.v1.ReservationService.CreateGuestReservation:input_type -> moviereservation.v1.CreateGuestReservationRequest
+	14, // 14: moviereservation.v1.ReservationService.GetGuestReservation:input_type -> moviereservation.v1.GetGuestReservationRequest
+	15, // 15: moviereservation.v1.ReservationService.CancelGuestReservation:input_type -> moviereservation.v1.CancelGuestReservationRequest
+	17, // 16: moviereservation.v1.ReservationService.GetSeatAvailability:input_type -> moviereservation.v1.GetSeatAvailabilityRequest
+	18, // 17: moviereservation.v1.ReservationService.WatchSeatAvailability:input_type -> moviereservation.v1.WatchSeatAvailabilityRequest
+	1,  // 18: moviereservation.v1.ReservationService.CreateReservation:output_type -> moviereservation.v1.Reservation
+	6,  // 19: moviereservation.v1.ReservationService.ListReservations:output_type -> moviereservation.v1.ListReservationsResponse
+	1,  // 20: moviereservation.v1.ReservationService.GetReservation:output_type -> moviereservation.v1.Reservation
+	9,  // 21: moviereservation.v1.ReservationService.CancelReservation:output_type -> moviereservation.v1.CancelReservationResponse
+	11, // 22: moviereservation.v1.ReservationService.ClaimGuestReservations:output_type -> moviereservation.v1.ClaimGuestReservationsResponse
+	13, // 23: moviereservation.v1.ReservationService.CreateGuestReservation:output_type -> moviereservation.v1.CreateGuestReservationResponse
+	1,  // 24: moviereservation.v1.ReservationService.GetGuestReservation:output_type -> moviereservation.v1.Reservation
+	16, // 25: moviereservation.v1.ReservationService.CancelGuestReservation:output_type -> moviereservation.v1.CancelGuestReservationResponse
+	19, // 26: moviereservation.v1.ReservationService.GetSeatAvailability:output_type -> moviereservation.v1.SeatAvailability
+	19, // 27: moviereservation.v1.ReservationService.WatchSeatAvailability:output_type -> moviereservation.v1.SeatAvailability
+	18, // [18:28] is the sub-list for method output_type
+	8,  // [8:18] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_moviereservation_v1_reservation_proto_init() }
+func file_moviereservation_v1_reservation_proto_init() {
+	if File_moviereservation_v1_reservation_proto != nil {
+		return
+	}
+	file_moviereservation_v1_reservation_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_moviereservation_v1_reservation_proto_rawDesc), len(file_moviereservation_v1_reservation_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_moviereservation_v1_reservation_proto_goTypes,
+		DependencyIndexes: file_moviereservation_v1_reservation_proto_depIdxs,
+		EnumInfos:         file_moviereservation_v1_reservation_proto_enumTypes,
+		MessageInfos:      file_moviereservation_v1_reservation_proto_msgTypes,
+	}.Build()
+	File_moviereservation_v1_reservation_proto = out.File
+	file_moviereservation_v1_reservation_proto_goTypes = nil
+	file_moviereservation_v1_reservation_proto_depIdxs = nil
+}
@@ -0,0 +1,493 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: moviereservation/v1/reservation.proto
+
+package moviereservationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReservationService_CreateReservation_FullMethodName      = "/moviereservation.v1.ReservationService/CreateReservation"
+	ReservationService_ListReservations_FullMethodName       = "/moviereservation.v1.ReservationService/ListReservations"
+	ReservationService_GetReservation_FullMethodName         = "/moviereservation.v1.ReservationService/GetReservation"
+	ReservationService_CancelReservation_FullMethodName      = "/moviereservation.v1.ReservationService/CancelReservation"
+	ReservationService_ClaimGuestReservations_FullMethodName = "/moviereservation.v1.ReservationService/ClaimGuestReservations"
+	ReservationService_CreateGuestReservation_FullMethodName = "/moviereservation.v1.ReservationService/CreateGuestReservation"
+	ReservationService_GetGuestReservation_FullMethodName    = "/moviereservation.v1.ReservationService/GetGuestReservation"
+	ReservationService_CancelGuestReservation_FullMethodName = "/moviereservation.v1.ReservationService/CancelGuestReservation"
+	ReservationService_GetSeatAvailability_FullMethodName    = "/moviereservation.v1.ReservationService/GetSeatAvailability"
+	ReservationService_WatchSeatAvailability_FullMethodName  = "/moviereservation.v1.ReservationService/WatchSeatAvailability"
+)
+
+// ReservationServiceClient is the client API for ReservationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReservationService books seats for users and for guests without an account,
+// and reports the availability of the seats of a showtime
+type ReservationServiceClient interface {
+	// CreateReservation books a seat for the signed-in user, or for user_id
+	// with the reservations:others permission
+	CreateReservation(ctx context.Context, in *CreateReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	// ListReservations returns the reservations of the signed-in user, or of user_id
+	// with the reservations:view permission
+	ListReservations(ctx context.Context, in *ListReservationsRequest, opts ...grpc.CallOption) (*ListReservationsResponse, error)
+	GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*CancelReservationResponse, error)
+	// ClaimGuestReservations moves the guest reservations made with the verified email
+	// of the signed-in user to the account
+	ClaimGuestReservations(ctx context.Context, in *ClaimGuestReservationsRequest, opts ...grpc.CallOption) (*ClaimGuestReservationsResponse, error)
+	// CreateGuestReservation books a seat without an account, the lookup code
+	// of the response is the only way to find the reservation again
+	CreateGuestReservation(ctx context.Context, in *CreateGuestReservationRequest, opts ...grpc.CallOption) (*CreateGuestReservationResponse, error)
+	GetGuestReservation(ctx context.Context, in *GetGuestReservationRequest, opts ...grpc.CallOption) (*Reservation, error)
+	CancelGuestReservation(ctx context.Context, in *CancelGuestReservationRequest, opts ...grpc.CallOption) (*CancelGuestReservationResponse, error)
+	GetSeatAvailability(ctx context.Context, in *GetSeatAvailabilityRequest, opts ...grpc.CallOption) (*SeatAvailability, error)
+	// WatchSeatAvailability sends every seat of the showtime first,
+	// then the seats whose status changed, until the client cancels
+	WatchSeatAvailability(ctx context.Context, in *WatchSeatAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SeatAvailability], error)
+}
+
+type reservationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReservationServiceClient(cc grpc.ClientConnInterface) ReservationServiceClient {
+	return &reservationServiceClient{cc}
+}
+
+func (c *reservationServiceClient) CreateReservation(ctx context.Context, in *CreateReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, ReservationService_CreateReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) ListReservations(ctx context.Context, in *ListReservationsRequest, opts ...grpc.CallOption) (*ListReservationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReservationsResponse)
+	err := c.cc.Invoke(ctx, ReservationService_ListReservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetReservation(ctx context.Context, in *GetReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, ReservationService_GetReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CancelReservation(ctx context.Context, in *CancelReservationRequest, opts ...grpc.CallOption) (*CancelReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelReservationResponse)
+	err := c.cc.Invoke(ctx, ReservationService_CancelReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) ClaimGuestReservations(ctx context.Context, in *ClaimGuestReservationsRequest, opts ...grpc.CallOption) (*ClaimGuestReservationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClaimGuestReservationsResponse)
+	err := c.cc.Invoke(ctx, ReservationService_ClaimGuestReservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CreateGuestReservation(ctx context.Context, in *CreateGuestReservationRequest, opts ...grpc.CallOption) (*CreateGuestReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateGuestReservationResponse)
+	err := c.cc.Invoke(ctx, ReservationService_CreateGuestReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetGuestReservation(ctx context.Context, in *GetGuestReservationRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, ReservationService_GetGuestReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CancelGuestReservation(ctx context.Context, in *CancelGuestReservationRequest, opts ...grpc.CallOption) (*CancelGuestReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelGuestReservationResponse)
+	err := c.cc.Invoke(ctx, ReservationService_CancelGuestReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetSeatAvailability(ctx context.Context, in *GetSeatAvailabilityRequest, opts ...grpc.CallOption) (*SeatAvailability, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SeatAvailability)
+	err := c.cc.Invoke(ctx, ReservationService_GetSeatAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) WatchSeatAvailability(ctx context.Context, in *WatchSeatAvailabilityRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SeatAvailability], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ReservationService_ServiceDesc.Streams[0], ReservationService_WatchSeatAvailability_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSeatAvailabilityRequest, SeatAvailability]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReservationService_WatchSeatAvailabilityClient = grpc.ServerStreamingClient[SeatAvailability]
+
+// ReservationServiceServer is the server API for ReservationService service.
+// All implementations must embed UnimplementedReservationServiceServer
+// for forward compatibility.
+//
+// ReservationService books seats for users and for guests without an account,
+// and reports the availability of the seats of a showtime
+type ReservationServiceServer interface {
+	// CreateReservation books a seat for the signed-in user, or for user_id
+	// with the reservations:others permission
+	CreateReservation(context.Context, *CreateReservationRequest) (*Reservation, error)
+	// ListReservations returns the reservations of the signed-in user, or of user_id
+	// with the reservations:view permission
+	ListReservations(context.Context, *ListReservationsRequest) (*ListReservationsResponse, error)
+	GetReservation(context.Context, *GetReservationRequest) (*Reservation, error)
+	CancelReservation(context.Context, *CancelReservationRequest) (*CancelReservationResponse, error)
+	// ClaimGuestReservations moves the guest reservations made with the verified email
+	// of the signed-in user to the account
+	ClaimGuestReservations(context.Context, *ClaimGuestReservationsRequest) (*ClaimGuestReservationsResponse, error)
+	// CreateGuestReservation books a seat without an account, the lookup code
+	// of the response is the only way to find the reservation again
+	CreateGuestReservation(context.Context, *CreateGuestReservationRequest) (*CreateGuestReservationResponse, error)
+	GetGuestReservation(context.Context, *GetGuestReservationRequest) (*Reservation, error)
+	CancelGuestReservation(context.Context, *CancelGuestReservationRequest) (*CancelGuestReservationResponse, error)
+	GetSeatAvailability(context.Context, *GetSeatAvailabilityRequest) (*SeatAvailability, error)
+	// WatchSeatAvailability sends every seat of the showtime first,
+	// then the seats whose status changed, until the client cancels
+	WatchSeatAvailability(*WatchSeatAvailabilityRequest, grpc.ServerStreamingServer[SeatAvailability]) error
+	mustEmbedUnimplementedReservationServiceServer()
+}
+
+// UnimplementedReservationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReservationServiceServer struct{}
+
+func (UnimplementedReservationServiceServer) CreateReservation(context.Context, *CreateReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) ListReservations(context.Context, *ListReservationsRequest) (*ListReservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReservations not implemented")
+}
+func (UnimplementedReservationServiceServer) GetReservation(context.Context, *GetReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) CancelReservation(context.Context, *CancelReservationRequest) (*CancelReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) ClaimGuestReservations(context.Context, *ClaimGuestReservationsRequest) (*ClaimGuestReservationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimGuestReservations not implemented")
+}
+func (UnimplementedReservationServiceServer) CreateGuestReservation(context.Context, *CreateGuestReservationRequest) (*CreateGuestReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGuestReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) GetGuestReservation(context.Context, *GetGuestReservationRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGuestReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) CancelGuestReservation(context.Context, *CancelGuestReservationRequest) (*CancelGuestReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelGuestReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) GetSeatAvailability(context.Context, *GetSeatAvailabilityRequest) (*SeatAvailability, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSeatAvailability not implemented")
+}
+func (UnimplementedReservationServiceServer) WatchSeatAvailability(*WatchSeatAvailabilityRequest, grpc.ServerStreamingServer[SeatAvailability]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSeatAvailability not implemented")
+}
+func (UnimplementedReservationServiceServer) mustEmbedUnimplementedReservationServiceServer() {}
+func (UnimplementedReservationServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeReservationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReservationServiceServer will
+// result in compilation errors.
+type UnsafeReservationServiceServer interface {
+	mustEmbedUnimplementedReservationServiceServer()
+}
+
+func RegisterReservationServiceServer(s grpc.ServiceRegistrar, srv ReservationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReservationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReservationService_ServiceDesc, srv)
+}
+
+func _ReservationService_CreateReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CreateReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CreateReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CreateReservation(ctx, req.(*CreateReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_ListReservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).ListReservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_ListReservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).ListReservations(ctx, req.(*ListReservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_GetReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_GetReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetReservation(ctx, req.(*GetReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_CancelReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CancelReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CancelReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CancelReservation(ctx, req.(*CancelReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_ClaimGuestReservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimGuestReservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).ClaimGuestReservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_ClaimGuestReservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).ClaimGuestReservations(ctx, req.(*ClaimGuestReservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_CreateGuestReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGuestReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CreateGuestReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CreateGuestReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CreateGuestReservation(ctx, req.(*CreateGuestReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_GetGuestReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGuestReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetGuestReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_GetGuestReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetGuestReservation(ctx, req.(*GetGuestReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_CancelGuestReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelGuestReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CancelGuestReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CancelGuestReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CancelGuestReservation(ctx, req.(*CancelGuestReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_GetSeatAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSeatAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetSeatAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_GetSeatAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetSeatAvailability(ctx, req.(*GetSeatAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_WatchSeatAvailability_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSeatAvailabilityRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReservationServiceServer).WatchSeatAvailability(m, &grpc.GenericServerStream[WatchSeatAvailabilityRequest, SeatAvailability]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReservationService_WatchSeatAvailabilityServer = grpc.ServerStreamingServer[SeatAvailability]
+
+// ReservationService_ServiceDesc is the grpc.ServiceDesc for ReservationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReservationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moviereservation.v1.ReservationService",
+	HandlerType: (*ReservationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReservation",
+			Handler:    _ReservationService_CreateReservation_Handler,
+		},
+		{
+			MethodName: "ListReservations",
+			Handler:    _ReservationService_ListReservations_Handler,
+		},
+		{
+			MethodName: "GetReservation",
+			Handler:    _ReservationService_GetReservation_Handler,
+		},
+		{
+			MethodName: "CancelReservation",
+			Handler:    _ReservationService_CancelReservation_Handler,
+		},
+		{
+			MethodName: "ClaimGuestReservations",
+			Handler:    _ReservationService_ClaimGuestReservations_Handler,
+		},
+		{
+			MethodName: "CreateGuestReservation",
+			Handler:    _ReservationService_CreateGuestReservation_Handler,
+		},
+		{
+			MethodName: "GetGuestReservation",
+			Handler:    _ReservationService_GetGuestReservation_Handler,
+		},
+		{
+			MethodName: "CancelGuestReservation",
+			Handler:    _ReservationService_CancelGuestReservation_Handler,
+		},
+		{
+			MethodName: "GetSeatAvailability",
+			Handler:    _ReservationService_GetSeatAvailability_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSeatAvailability",
+			Handler:       _ReservationService_WatchSeatAvailability_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "moviereservation/v1/reservation.proto",
+}
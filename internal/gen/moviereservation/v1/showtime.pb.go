@@ -0,0 +1,388 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: moviereservation/v1/showtime.proto
+
+package moviereservationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Showtime struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	MovieId       uint64                 `protobuf:"varint,2,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	HallId        uint64                 `protobuf:"varint,3,opt,name=hall_id,json=hallId,proto3" json:"hall_id,omitempty"`
+	StartAt       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Showtime) Reset() {
+	*x = Showtime{}
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Showtime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Showtime) ProtoMessage() {}
+
+func (x *Showtime) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Showtime.ProtoReflect.Descriptor instead.
+func (*Showtime) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_showtime_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Showtime) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Showtime) GetMovieId() uint64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *Showtime) GetHallId() uint64 {
+	if x != nil {
+		return x.HallId
+	}
+	return 0
+}
+
+func (x *Showtime) GetStartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartAt
+	}
+	return nil
+}
+
+type ListShowtimesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MovieId       uint64                 `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	HallId        uint64                 `protobuf:"varint,2,opt,name=hall_id,json=hallId,proto3" json:"hall_id,omitempty"`
+	CinemaId      uint64                 `protobuf:"varint,3,opt,name=cinema_id,json=cinemaId,proto3" json:"cinema_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShowtimesRequest) Reset() {
+	*x = ListShowtimesRequest{}
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShowtimesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShowtimesRequest) ProtoMessage() {}
+
+func (x *ListShowtimesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShowtimesRequest.ProtoReflect.Descriptor instead.
+func (*ListShowtimesRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_showtime_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListShowtimesRequest) GetMovieId() uint64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *ListShowtimesRequest) GetHallId() uint64 {
+	if x != nil {
+		return x.HallId
+	}
+	return 0
+}
+
+func (x *ListShowtimesRequest) GetCinemaId() uint64 {
+	if x != nil {
+		return x.CinemaId
+	}
+	return 0
+}
+
+type ListShowtimesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Showtimes     []*Showtime            `protobuf:"bytes,1,rep,name=showtimes,proto3" json:"showtimes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShowtimesResponse) Reset() {
+	*x = ListShowtimesResponse{}
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShowtimesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShowtimesResponse) ProtoMessage() {}
+
+func (x *ListShowtimesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShowtimesResponse.ProtoReflect.Descriptor instead.
+func (*ListShowtimesResponse) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_showtime_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListShowtimesResponse) GetShowtimes() []*Showtime {
+	if x != nil {
+		return x.Showtimes
+	}
+	return nil
+}
+
+type GetShowtimeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetShowtimeRequest) Reset() {
+	*x = GetShowtimeRequest{}
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetShowtimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetShowtimeRequest) ProtoMessage() {}
+
+func (x *GetShowtimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetShowtimeRequest.ProtoReflect.Descriptor instead.
+func (*GetShowtimeRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_showtime_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetShowtimeRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateShowtimeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MovieId       uint64                 `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	HallId        uint64                 `protobuf:"varint,2,opt,name=hall_id,json=hallId,proto3" json:"hall_id,omitempty"`
+	StartAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShowtimeRequest) Reset() {
+	*x = CreateShowtimeRequest{}
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShowtimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShowtimeRequest) ProtoMessage() {}
+
+func (x *CreateShowtimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviereservation_v1_showtime_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShowtimeRequest.ProtoReflect.Descriptor instead.
+func (*CreateShowtimeRequest) Descriptor() ([]byte, []int) {
+	return file_moviereservation_v1_showtime_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateShowtimeRequest) GetMovieId() uint64 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *CreateShowtimeRequest) GetHallId() uint64 {
+	if x != nil {
+		return x.HallId
+	}
+	return 0
+}
+
+func (x *CreateShowtimeRequest) GetStartAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartAt
+	}
+	return nil
+}
+
+var File_moviereservation_v1_showtime_proto protoreflect.FileDescriptor
+
+const file_moviereservation_v1_showtime_proto_rawDesc = "" +
+	"\n" +
+	"\"moviereservation/v1/showtime.proto\x12\x13moviereservation.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x85\x01\n" +
+	"\bShowtime\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x19\n" +
+	"\bmovie_id\x18\x02 \x01(\x04R\amovieId\x12\x17\n" +
+	"\ahall_id\x18\x03 \x01(\x04R\x06hallId\x125\n" +
+	"\bstart_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\astartAt\"g\n" +
+	"\x14ListShowtimesRequest\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x04R\amovieId\x12\x17\n" +
+	"\ahall_id\x18\x02 \x01(\x04R\x06hallId\x12\x1b\n" +
+	"\tcinema_id\x18\x03 \x01(\x04R\bcinemaId\"T\n" +
+	"\x15ListShowtimesResponse\x12;\n" +
+	"\tshowtimes\x18\x01 \x03(\v2\x1d.moviereservation.v1.ShowtimeR\tshowtimes\"$\n" +
+	"\x12GetShowtimeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x82\x01\n" +
+	"\x15CreateShowtimeRequest\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x04R\amovieId\x12\x17\n" +
+	"\ahall_id\x18\x02 \x01(\x04R\x06hallId\x125\n" +
+	"\bstart_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\astartAt2\xad\x02\n" +
+	"\x0fShowtimeService\x12f\n" +
+	"\rListShowtimes\x12).moviereservation.v1.ListShowtimesRequest\x1a*.moviereservation.v1.ListShowtimesResponse\x12U\n" +
+	"\vGetShowtime\x12'.moviereservation.v1.GetShowtimeRequest\x1a\x1d.moviereservation.v1.Showtime\x12[\n" +
+	"\x0eCreateShowtime\x12*.moviereservation.v1.CreateShowtimeRequest\x1a\x1d.moviereservation.v1.ShowtimeBYZWgithub.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1;moviereservationv1b\x06proto3"
+
+var (
+	file_moviereservation_v1_showtime_proto_rawDescOnce sync.Once
+	file_moviereservation_v1_showtime_proto_rawDescData []byte
+)
+
+func file_moviereservation_v1_showtime_proto_rawDescGZIP() []byte {
+	file_moviereservation_v1_showtime_proto_rawDescOnce.Do(func() {
+		file_moviereservation_v1_showtime_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_moviereservation_v1_showtime_proto_rawDesc), len(file_moviereservation_v1_showtime_proto_rawDesc)))
+	})
+	return file_moviereservation_v1_showtime_proto_rawDescData
+}
+
+var file_moviereservation_v1_showtime_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_moviereservation_v1_showtime_proto_goTypes = []any{
+	(*Showtime)(nil),              // 0: moviereservation.v1.Showtime
+	(*ListShowtimesRequest)(nil),  // 1: moviereservation.v1.ListShowtimesRequest
+	(*ListShowtimesResponse)(nil), // 2: moviereservation.v1.ListShowtimesResponse
+	(*GetShowtimeRequest)(nil),    // 3: moviereservation.v1.GetShowtimeRequest
+	(*CreateShowtimeRequest)(nil), // 4: moviereservation.v1.CreateShowtimeRequest
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_moviereservation_v1_showtime_proto_depIdxs = []int32{
+	5, // 0: moviereservation.v1.Showtime.start_at:type_name -> google.protobuf.Timestamp
+	0, // 1: moviereservation.v1.ListShowtimesResponse.showtimes:type_name -> moviereservation.v1.Showtime
+	5, // 2: moviereservation.v1.CreateShowtimeRequest.start_at:type_name -> google.protobuf.Timestamp
+	1, // 3: moviereservation.v1.ShowtimeService.ListShowtimes:input_type -> moviereservation.v1.ListShowtimesRequest
+	3, // 4: moviereservation.v1.ShowtimeService.GetShowtime:input_type -> moviereservation.v1.GetShowtimeRequest
+	4, // 5: moviereservation.v1.ShowtimeService.CreateShowtime:input_type -> moviereservation.v1.CreateShowtimeRequest
+	2, // 6: moviereservation.v1.ShowtimeService.ListShowtimes:output_type -> moviereservation.v1.ListShowtimesResponse
+	0, // 7: moviereservation.v1.ShowtimeService.GetShowtime:output_type -> moviereservation.v1.Showtime
+	0, // 8: moviereservation.v1.ShowtimeService.CreateShowtime:output_type -> moviereservation.v1.Showtime
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_moviereservation_v1_showtime_proto_init() }
+func file_moviereservation_v1_showtime_proto_init() {
+	if File_moviereservation_v1_showtime_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_moviereservation_v1_showtime_proto_rawDesc), len(file_moviereservation_v1_showtime_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_moviereservation_v1_showtime_proto_goTypes,
+		DependencyIndexes: file_moviereservation_v1_showtime_proto_depIdxs,
+		MessageInfos:      file_moviereservation_v1_showtime_proto_msgTypes,
+	}.Build()
+	File_moviereservation_v1_showtime_proto = out.File
+	file_moviereservation_v1_showtime_proto_goTypes = nil
+	file_moviereservation_v1_showtime_proto_depIdxs = nil
+}
@@ -0,0 +1,207 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: moviereservation/v1/showtime.proto
+
+package moviereservationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ShowtimeService_ListShowtimes_FullMethodName  = "/moviereservation.v1.ShowtimeService/ListShowtimes"
+	ShowtimeService_GetShowtime_FullMethodName    = "/moviereservation.v1.ShowtimeService/GetShowtime"
+	ShowtimeService_CreateShowtime_FullMethodName = "/moviereservation.v1.ShowtimeService/CreateShowtime"
+)
+
+// ShowtimeServiceClient is the client API for ShowtimeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ShowtimeService schedules the movies in the halls,
+// scheduling needs the showtimes:manage permission
+type ShowtimeServiceClient interface {
+	// ListShowtimes returns all showtimes, or those of at most one of the filters
+	ListShowtimes(ctx context.Context, in *ListShowtimesRequest, opts ...grpc.CallOption) (*ListShowtimesResponse, error)
+	GetShowtime(ctx context.Context, in *GetShowtimeRequest, opts ...grpc.CallOption) (*Showtime, error)
+	// CreateShowtime schedules a showtime in the future, outside the maintenance windows of the hall
+	CreateShowtime(ctx context.Context, in *CreateShowtimeRequest, opts ...grpc.CallOption) (*Showtime, error)
+}
+
+type showtimeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShowtimeServiceClient(cc grpc.ClientConnInterface) ShowtimeServiceClient {
+	return &showtimeServiceClient{cc}
+}
+
+func (c *showtimeServiceClient) ListShowtimes(ctx context.Context, in *ListShowtimesRequest, opts ...grpc.CallOption) (*ListShowtimesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListShowtimesResponse)
+	err := c.cc.Invoke(ctx, ShowtimeService_ListShowtimes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showtimeServiceClient) GetShowtime(ctx context.Context, in *GetShowtimeRequest, opts ...grpc.CallOption) (*Showtime, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Showtime)
+	err := c.cc.Invoke(ctx, ShowtimeService_GetShowtime_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *showtimeServiceClient) CreateShowtime(ctx context.Context, in *CreateShowtimeRequest, opts ...grpc.CallOption) (*Showtime, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Showtime)
+	err := c.cc.Invoke(ctx, ShowtimeService_CreateShowtime_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShowtimeServiceServer is the server API for ShowtimeService service.
+// All implementations must embed UnimplementedShowtimeServiceServer
+// for forward compatibility.
+//
+// ShowtimeService schedules the movies in the halls,
+// scheduling needs the showtimes:manage permission
+type ShowtimeServiceServer interface {
+	// ListShowtimes returns all showtimes, or those of at most one of the filters
+	ListShowtimes(context.Context, *ListShowtimesRequest) (*ListShowtimesResponse, error)
+	GetShowtime(context.Context, *GetShowtimeRequest) (*Showtime, error)
+	// CreateShowtime schedules a showtime in the future, outside the maintenance windows of the hall
+	CreateShowtime(context.Context, *CreateShowtimeRequest) (*Showtime, error)
+	mustEmbedUnimplementedShowtimeServiceServer()
+}
+
+// UnimplementedShowtimeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShowtimeServiceServer struct{}
+
+func (UnimplementedShowtimeServiceServer) ListShowtimes(context.Context, *ListShowtimesRequest) (*ListShowtimesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShowtimes not implemented")
+}
+func (UnimplementedShowtimeServiceServer) GetShowtime(context.Context, *GetShowtimeRequest) (*Showtime, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShowtime not implemented")
+}
+func (UnimplementedShowtimeServiceServer) CreateShowtime(context.Context, *CreateShowtimeRequest) (*Showtime, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShowtime not implemented")
+}
+func (UnimplementedShowtimeServiceServer) mustEmbedUnimplementedShowtimeServiceServer() {}
+func (UnimplementedShowtimeServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeShowtimeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShowtimeServiceServer will
+// result in compilation errors.
+type UnsafeShowtimeServiceServer interface {
+	mustEmbedUnimplementedShowtimeServiceServer()
+}
+
+func RegisterShowtimeServiceServer(s grpc.ServiceRegistrar, srv ShowtimeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedShowtimeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ShowtimeService_ServiceDesc, srv)
+}
+
+func _ShowtimeService_ListShowtimes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShowtimesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowtimeServiceServer).ListShowtimes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShowtimeService_ListShowtimes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowtimeServiceServer).ListShowtimes(ctx, req.(*ListShowtimesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowtimeService_GetShowtime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShowtimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowtimeServiceServer).GetShowtime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShowtimeService_GetShowtime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowtimeServiceServer).GetShowtime(ctx, req.(*GetShowtimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShowtimeService_CreateShowtime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShowtimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShowtimeServiceServer).CreateShowtime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShowtimeService_CreateShowtime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShowtimeServiceServer).CreateShowtime(ctx, req.(*CreateShowtimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ShowtimeService_ServiceDesc is the grpc.ServiceDesc for ShowtimeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShowtimeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moviereservation.v1.ShowtimeService",
+	HandlerType: (*ShowtimeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListShowtimes",
+			Handler:    _ShowtimeService_ListShowtimes_Handler,
+		},
+		{
+			MethodName: "GetShowtime",
+			Handler:    _ShowtimeService_GetShowtime_Handler,
+		},
+		{
+			MethodName: "CreateShowtime",
+			Handler:    _ShowtimeService_CreateShowtime_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "moviereservation/v1/showtime.proto",
+}
@@ -0,0 +1,96 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+const apiKeyMetadata = "x-api-key"
+
+type actorKey struct{}
+
+// authenticate reads the actor from the x-api-key metadata or a Bearer access token
+// in authorization, like the HTTP API. Calls without either go on anonymously
+// and are refused by requireActor
+func authenticate(ctx context.Context, tokens auth.TokenService, apiKeys service.APIKeyService) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if secret := firstValue(md, apiKeyMetadata); secret != "" {
+		actor, err := apiKeys.Authenticate(secret)
+		if err != nil {
+			return nil, err
+		}
+		return context.WithValue(ctx, actorKey{}, actor), nil
+	}
+
+	header := firstValue(md, "authorization")
+	if header == "" {
+		return ctx, nil
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	claims, err := tokens.ParseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, actorKey{}, claims.Actor()), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// actorOf returns the actor of the call, the zero actor for anonymous calls
+func actorOf(ctx context.Context) service.Actor {
+	actor, _ := ctx.Value(actorKey{}).(service.Actor)
+	return actor
+}
+
+// requireActor refuses anonymous calls
+func requireActor(ctx context.Context) (service.Actor, error) {
+	actor, ok := ctx.Value(actorKey{}).(service.Actor)
+	if !ok {
+		return service.Actor{}, service.ErrInvalidCredential
+	}
+	return actor, nil
+}
+
+func authUnary(tokens auth.TokenService, apiKeys service.APIKeyService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, tokens, apiKeys)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authStream(tokens auth.TokenService, apiKeys service.APIKeyService) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), tokens, apiKeys)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// contextStream replaces the context of a stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,182 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// requireID rejects an unset ID field like the HTTP API rejects an invalid ID in a path
+func requireID(name string, id uint64) (uint, error) {
+	if id == 0 {
+		return 0, &service.ValidationError{Fields: []service.FieldError{{
+			Field:   name,
+			Rule:    service.RuleRequired,
+			Message: "is required",
+		}}}
+	}
+	return uint(id), nil
+}
+
+// timeOf returns the zero time for an unset timestamp, which the services reject
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toMovie(movie *model.Movie) *pb.Movie {
+	return &pb.Movie{
+		Id:             uint64(movie.ID),
+		Title:          movie.Title,
+		Description:    movie.Description,
+		RuntimeMinutes: uint32(movie.RuntimeMinutes),
+		Version:        uint64(movie.Version),
+	}
+}
+
+func toHall(hall *model.Hall) *pb.Hall {
+	return &pb.Hall{
+		Id:        uint64(hall.ID),
+		CinemaId:  uint64(hall.CinemaID),
+		Name:      hall.Name,
+		SeatCount: int32(hall.SeatCount),
+		Rows:      int32(hall.Rows),
+		Cols:      int32(hall.Cols),
+		Layout:    toLayout(hall.SeatLayout()),
+		Version:   uint64(hall.Version),
+	}
+}
+
+var cellKinds = map[model.CellKind]pb.CellKind{
+	model.CellSeat:  pb.CellKind_CELL_KIND_SEAT,
+	model.CellAisle: pb.CellKind_CELL_KIND_AISLE,
+	model.CellGap:   pb.CellKind_CELL_KIND_GAP,
+}
+
+var seatCategories = map[model.SeatCategory]pb.SeatCategory{
+	model.SeatStandard:   pb.SeatCategory_SEAT_CATEGORY_STANDARD,
+	model.SeatPremium:    pb.SeatCategory_SEAT_CATEGORY_PREMIUM,
+	model.SeatWheelchair: pb.SeatCategory_SEAT_CATEGORY_WHEELCHAIR,
+	model.SeatCompanion:  pb.SeatCategory_SEAT_CATEGORY_COMPANION,
+}
+
+func toLayout(layout model.HallLayout) *pb.HallLayout {
+	rows := make([]*pb.HallRow, 0, len(layout.Rows))
+	for _, row := range layout.Rows {
+		cells := make([]*pb.HallCell, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			cells = append(cells, &pb.HallCell{
+				Kind:     cellKinds[cell.Kind],
+				Category: seatCategories[cell.Category],
+			})
+		}
+		rows = append(rows, &pb.HallRow{Label: row.Label, Curved: row.Curved, Cells: cells})
+	}
+	return &pb.HallLayout{Rows: rows}
+}
+
+// fromLayout returns nil for an unset layout, unspecified enums
+// become empty values the validation of the hall rejects
+func fromLayout(layout *pb.HallLayout) *model.HallLayout {
+	if layout == nil {
+		return nil
+	}
+	result := &model.HallLayout{Rows: make([]model.LayoutRow, 0, len(layout.Rows))}
+	for _, row := range layout.Rows {
+		cells := make([]model.LayoutCell, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			cells = append(cells, model.LayoutCell{
+				Kind:     keyOf(cellKinds, cell.Kind),
+				Category: keyOf(seatCategories, cell.Category),
+			})
+		}
+		result.Rows = append(result.Rows, model.LayoutRow{Label: row.Label, Curved: row.Curved, Cells: cells})
+	}
+	return result
+}
+
+// keyOf looks up the model value of an enum, the zero value if there is none
+func keyOf[K comparable, V comparable](values map[K]V, value V) K {
+	for key, v := range values {
+		if v == value {
+			return key
+		}
+	}
+	var zero K
+	return zero
+}
+
+func toShowtime(showtime *model.Showtime) *pb.Showtime {
+	return &pb.Showtime{
+		Id:      uint64(showtime.ID),
+		MovieId: uint64(showtime.MovieID),
+		HallId:  uint64(showtime.HallID),
+		StartAt: timestamppb.New(showtime.StartAt),
+	}
+}
+
+func toReservation(reservation *model.Reservation) *pb.Reservation {
+	response := &pb.Reservation{
+		Id:         uint64(reservation.ID),
+		ShowtimeId: uint64(reservation.ShowtimeID),
+		SeatId:     uint64(reservation.SeatID),
+		CreatedAt:  timestamppb.New(reservation.CreatedAt),
+	}
+	if reservation.UserID != nil {
+		userID := uint64(*reservation.UserID)
+		response.UserId = &userID
+	}
+	if reservation.IsGuest() {
+		response.Guest = &pb.Guest{
+			Name:  reservation.Guest.Name,
+			Email: reservation.Guest.Email,
+			Phone: reservation.Guest.Phone,
+		}
+	}
+	return response
+}
+
+func toReservations(reservations []model.Reservation) []*pb.Reservation {
+	response := make([]*pb.Reservation, 0, len(reservations))
+	for i := range reservations {
+		response = append(response, toReservation(&reservations[i]))
+	}
+	return response
+}
+
+func captchaOf(captcha *pb.CaptchaSolution) service.CaptchaSolution {
+	if captcha == nil {
+		return service.CaptchaSolution{}
+	}
+	return service.CaptchaSolution{
+		ChallengeID: captcha.ChallengeId,
+		X:           int(captcha.X),
+		Y:           int(captcha.Y),
+	}
+}
+
+var seatStatuses = map[service.SeatStatus]pb.SeatStatus{
+	service.SeatAvailable: pb.SeatStatus_SEAT_STATUS_AVAILABLE,
+	service.SeatReserved:  pb.SeatStatus_SEAT_STATUS_RESERVED,
+	service.SeatBlocked:   pb.SeatStatus_SEAT_STATUS_BLOCKED,
+}
+
+func toSeatAvailability(showtimeID uint, seats []service.SeatState) *pb.SeatAvailability {
+	response := &pb.SeatAvailability{
+		ShowtimeId: uint64(showtimeID),
+		Seats:      make([]*pb.Seat, 0, len(seats)),
+	}
+	for _, seat := range seats {
+		response.Seats = append(response.Seats, &pb.Seat{
+			SeatId: uint64(seat.SeatID),
+			Status: seatStatuses[seat.Status],
+		})
+	}
+	return response
+}
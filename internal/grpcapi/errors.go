@@ -0,0 +1,102 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// errorDomain is the domain of the ErrorInfo details
+const errorDomain = "movie-reservation"
+
+// grpcCodes are the codes that don't follow from the HTTP status of the error
+var grpcCodes = map[service.Code]codes.Code{
+	service.CodeAlreadyExists:        codes.AlreadyExists,
+	service.CodeAlreadyReserved:      codes.AlreadyExists,
+	service.CodeSeatTaken:            codes.AlreadyExists,
+	service.CodeVersionConflict:      codes.Aborted,
+	service.CodeRelatedResources:     codes.FailedPrecondition,
+	service.CodeAlreadyVerified:      codes.FailedPrecondition,
+	service.CodeNoTicketsAvailable:   codes.FailedPrecondition,
+	service.CodeSeatBlocked:          codes.FailedPrecondition,
+	service.CodeHallUnderMaintenance: codes.FailedPrecondition,
+}
+
+var httpStatusCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// statusOf turns err into the status the client gets, the error code of the HTTP API
+// is the reason of an ErrorInfo detail, so clients of both branch on the same codes
+func statusOf(err error) *status.Status {
+	payload := httperror.PayloadOf(err)
+	code, ok := grpcCodes[payload.Code]
+	if !ok {
+		code = httpStatusCodes[httperror.Status(payload.Code)]
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   string(payload.Code),
+		Domain:   errorDomain,
+		Metadata: map[string]string{},
+	}
+	details := []protoadapt.MessageV1{info}
+	for key, value := range payload.Details {
+		if fields, ok := value.([]service.FieldError); ok {
+			badRequest := &errdetails.BadRequest{}
+			for _, field := range fields {
+				badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+					Field:       field.Field,
+					Description: field.Message,
+					Reason:      string(field.Rule),
+				})
+			}
+			details = append(details, badRequest)
+			continue
+		}
+		if seconds, ok := value.(int); ok && key == "retry_after" {
+			details = append(details, &errdetails.RetryInfo{
+				RetryDelay: durationpb.New(time.Duration(seconds) * time.Second),
+			})
+		}
+		info.Metadata[key] = metadataValue(value)
+	}
+
+	st := status.New(code, payload.Message)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// metadataValue writes a detail as a string, lists and objects as JSON
+func metadataValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,125 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type hallServer struct {
+	pb.UnimplementedHallServiceServer
+	app *app.App
+}
+
+func (s *hallServer) ListHalls(_ context.Context, req *pb.ListHallsRequest) (*pb.ListHallsResponse, error) {
+	var halls []model.Hall
+	var err error
+	if req.CinemaId != 0 {
+		halls, err = s.app.HallService.GetHallsByCinemaID(uint(req.CinemaId))
+	} else {
+		halls, err = s.app.HallService.GetAllHalls()
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListHallsResponse{Halls: make([]*pb.Hall, 0, len(halls))}
+	for i := range halls {
+		response.Halls = append(response.Halls, toHall(&halls[i]))
+	}
+	return response, nil
+}
+
+func (s *hallServer) GetHall(_ context.Context, req *pb.GetHallRequest) (*pb.Hall, error) {
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	hall, err := s.app.HallService.GetHallByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toHall(hall), nil
+}
+
+func (s *hallServer) CreateHall(ctx context.Context, req *pb.CreateHallRequest) (*pb.Hall, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hall := &model.Hall{
+		CinemaID: uint(req.CinemaId),
+		Name:     req.Name,
+		Rows:     int(req.Rows),
+		Cols:     int(req.Cols),
+	}
+	if layout := fromLayout(req.Layout); layout != nil {
+		hall.Layout = *layout
+	}
+	if err := s.app.HallService.CreateHall(actor, hall); err != nil {
+		return nil, err
+	}
+	return toHall(hall), nil
+}
+
+// UpdateHall replaces the hall, which is refused while it has showtimes
+func (s *hallServer) UpdateHall(ctx context.Context, req *pb.UpdateHallRequest) (*pb.Hall, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	hall := &model.Hall{
+		ID:       id,
+		CinemaID: uint(req.CinemaId),
+		Name:     req.Name,
+		Rows:     int(req.Rows),
+		Cols:     int(req.Cols),
+		Version:  uint(req.Version),
+	}
+	if layout := fromLayout(req.Layout); layout != nil {
+		hall.Layout = *layout
+	}
+	if err := s.app.HallService.UpdateHall(actor, hall); err != nil {
+		return nil, err
+	}
+	return toHall(hall), nil
+}
+
+func (s *hallServer) PatchHall(ctx context.Context, req *pb.PatchHallRequest) (*pb.Hall, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	hall, err := s.app.HallService.PatchHall(actor, id, uint(req.Version), model.HallPatch{
+		Name:   req.Name,
+		Layout: fromLayout(req.Layout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toHall(hall), nil
+}
+
+func (s *hallServer) DeleteHall(ctx context.Context, req *pb.DeleteHallRequest) (*pb.DeleteHallResponse, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.HallService.DeleteHallByID(actor, id); err != nil {
+		return nil, err
+	}
+	return &pb.DeleteHallResponse{}, nil
+}
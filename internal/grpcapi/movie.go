@@ -0,0 +1,106 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type movieServer struct {
+	pb.UnimplementedMovieServiceServer
+	app *app.App
+}
+
+func (s *movieServer) ListMovies(_ context.Context, req *pb.ListMoviesRequest) (*pb.ListMoviesResponse, error) {
+	var movies []model.Movie
+	var err error
+	if req.CinemaId != 0 {
+		movies, err = s.app.MovieService.GetMoviesByCinemaID(uint(req.CinemaId))
+	} else {
+		movies, err = s.app.MovieService.GetAllMovies()
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListMoviesResponse{Movies: make([]*pb.Movie, 0, len(movies))}
+	for i := range movies {
+		response.Movies = append(response.Movies, toMovie(&movies[i]))
+	}
+	return response, nil
+}
+
+func (s *movieServer) GetMovie(_ context.Context, req *pb.GetMovieRequest) (*pb.Movie, error) {
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	movie, err := s.app.MovieService.GetMovieByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toMovie(movie), nil
+}
+
+func (s *movieServer) CreateMovie(ctx context.Context, req *pb.CreateMovieRequest) (*pb.Movie, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	movie := &model.Movie{
+		Title:          req.Title,
+		Description:    req.Description,
+		RuntimeMinutes: uint(req.RuntimeMinutes),
+	}
+	if err := s.app.MovieService.CreateMovie(actor, movie); err != nil {
+		return nil, err
+	}
+	return toMovie(movie), nil
+}
+
+func (s *movieServer) UpdateMovie(ctx context.Context, req *pb.UpdateMovieRequest) (*pb.Movie, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	movie := &model.Movie{
+		ID:             id,
+		Title:          req.Title,
+		Description:    req.Description,
+		RuntimeMinutes: uint(req.RuntimeMinutes),
+		Version:        uint(req.Version),
+	}
+	if err := s.app.MovieService.UpdateMovie(actor, movie); err != nil {
+		return nil, err
+	}
+	return toMovie(movie), nil
+}
+
+func (s *movieServer) PatchMovie(ctx context.Context, req *pb.PatchMovieRequest) (*pb.Movie, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	patch := model.MoviePatch{
+		Title:       req.Title,
+		Description: req.Description,
+	}
+	if req.RuntimeMinutes != nil {
+		runtimeMinutes := uint(*req.RuntimeMinutes)
+		patch.RuntimeMinutes = &runtimeMinutes
+	}
+	movie, err := s.app.MovieService.PatchMovie(actor, id, uint(req.Version), patch)
+	if err != nil {
+		return nil, err
+	}
+	return toMovie(movie), nil
+}
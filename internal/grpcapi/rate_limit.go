@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// methodClass puts the methods in the route classes of the HTTP API,
+// so a caller has the same buckets over both
+func methodClass(fullMethod string) string {
+	name := fullMethod[strings.LastIndexByte(fullMethod, '/')+1:]
+	switch {
+	case strings.HasPrefix(name, "Get") || strings.HasPrefix(name, "List") || strings.HasPrefix(name, "Watch"):
+		return "read"
+	case strings.Contains(fullMethod, ".ReservationService/"):
+		return "booking"
+	default:
+		return "write"
+	}
+}
+
+// rateLimit takes a token from the bucket of the caller like the HTTP API,
+// the state of the bucket is sent in the ratelimit-* header metadata
+func rateLimit(ctx context.Context, limiter service.RateLimiter, limits map[string]service.RateLimit,
+	fullMethod string, setHeader func(metadata.MD) error) error {
+	class := methodClass(fullMethod)
+	limit, ok := limits[class]
+	if !ok {
+		return nil
+	}
+	decision, err := limiter.Allow("ratelimit:"+class+":"+rateLimitSubject(ctx), limit)
+	if err != nil {
+		// an unavailable limiter doesn't take the API down
+		return nil
+	}
+	_ = setHeader(metadata.Pairs(
+		"ratelimit-policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, int(limit.Per.Seconds()), limit.Burst),
+		"ratelimit-limit", strconv.Itoa(limit.Burst),
+		"ratelimit-remaining", strconv.Itoa(decision.Remaining),
+		"ratelimit-reset", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))),
+	))
+	if !decision.Allowed {
+		return &service.RetryAfterError{Err: service.ErrRateLimited, RetryAfter: decision.RetryAfter}
+	}
+	return nil
+}
+
+func rateLimitSubject(ctx context.Context) string {
+	actor := actorOf(ctx)
+	switch {
+	case actor.APIKeyID != 0:
+		return "key:" + strconv.FormatUint(uint64(actor.APIKeyID), 10)
+	case actor.UserID != 0:
+		return "user:" + strconv.FormatUint(uint64(actor.UserID), 10)
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:"
+}
+
+func rateLimitUnary(limiter service.RateLimiter, limits map[string]service.RateLimit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		err := rateLimit(ctx, limiter, limits, info.FullMethod, func(md metadata.MD) error {
+			return grpc.SetHeader(ctx, md)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func rateLimitStream(limiter service.RateLimiter, limits map[string]service.RateLimit) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rateLimit(stream.Context(), limiter, limits, info.FullMethod, stream.SetHeader); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,147 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+type reservationServer struct {
+	pb.UnimplementedReservationServiceServer
+	app *app.App
+}
+
+func (s *reservationServer) CreateReservation(ctx context.Context, req *pb.CreateReservationRequest) (*pb.Reservation, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID := uint(req.UserId)
+	if userID == 0 {
+		userID = actor.UserID
+	}
+	reservation, err := s.app.ReservationService.Reserve(actor, userID, uint(req.ShowtimeId), uint(req.SeatId),
+		captchaOf(req.Captcha), req.AdmissionToken)
+	if err != nil {
+		return nil, err
+	}
+	return toReservation(reservation), nil
+}
+
+func (s *reservationServer) ListReservations(ctx context.Context, req *pb.ListReservationsRequest) (*pb.ListReservationsResponse, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userID := uint(req.UserId)
+	if userID == 0 {
+		userID = actor.UserID
+	}
+	reservations, err := s.app.ReservationService.GetReservationsByUserID(actor, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListReservationsResponse{Reservations: toReservations(reservations)}, nil
+}
+
+func (s *reservationServer) GetReservation(ctx context.Context, req *pb.GetReservationRequest) (*pb.Reservation, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	reservation, err := s.app.ReservationService.GetReservationByID(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return toReservation(reservation), nil
+}
+
+func (s *reservationServer) CancelReservation(ctx context.Context, req *pb.CancelReservationRequest) (*pb.CancelReservationResponse, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.app.ReservationService.CancelReservation(actor, id); err != nil {
+		return nil, err
+	}
+	return &pb.CancelReservationResponse{}, nil
+}
+
+func (s *reservationServer) ClaimGuestReservations(ctx context.Context, _ *pb.ClaimGuestReservationsRequest) (*pb.ClaimGuestReservationsResponse, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.app.ReservationService.ClaimGuestReservations(actor, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ClaimGuestReservationsResponse{Claimed: int32(claimed)}, nil
+}
+
+func (s *reservationServer) CreateGuestReservation(ctx context.Context, req *pb.CreateGuestReservationRequest) (*pb.CreateGuestReservationResponse, error) {
+	guest := model.GuestContact{
+		Name:  req.Name,
+		Email: req.Email,
+		Phone: req.Phone,
+	}
+	reservation, code, err := s.app.ReservationService.ReserveAsGuest(actorOf(ctx), guest, uint(req.ShowtimeId),
+		uint(req.SeatId), captchaOf(req.Captcha), req.AdmissionToken)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateGuestReservationResponse{
+		Reservation: toReservation(reservation),
+		LookupCode:  code,
+	}, nil
+}
+
+func (s *reservationServer) GetGuestReservation(_ context.Context, req *pb.GetGuestReservationRequest) (*pb.Reservation, error) {
+	reservation, err := s.app.ReservationService.GetGuestReservation(req.LookupCode)
+	if err != nil {
+		return nil, err
+	}
+	return toReservation(reservation), nil
+}
+
+func (s *reservationServer) CancelGuestReservation(_ context.Context, req *pb.CancelGuestReservationRequest) (*pb.CancelGuestReservationResponse, error) {
+	if err := s.app.ReservationService.CancelGuestReservation(req.LookupCode); err != nil {
+		return nil, err
+	}
+	return &pb.CancelGuestReservationResponse{}, nil
+}
+
+func (s *reservationServer) GetSeatAvailability(_ context.Context, req *pb.GetSeatAvailabilityRequest) (*pb.SeatAvailability, error) {
+	showtimeID, err := requireID("showtime_id", req.ShowtimeId)
+	if err != nil {
+		return nil, err
+	}
+	seats, err := s.app.ReservationService.GetSeatAvailability(showtimeID)
+	if err != nil {
+		return nil, err
+	}
+	return toSeatAvailability(showtimeID, seats), nil
+}
+
+// WatchSeatAvailability sends every seat first, then the seats whose status changed
+func (s *reservationServer) WatchSeatAvailability(req *pb.WatchSeatAvailabilityRequest, stream pb.ReservationService_WatchSeatAvailabilityServer) error {
+	showtimeID, err := requireID("showtime_id", req.ShowtimeId)
+	if err != nil {
+		return err
+	}
+	return s.app.ReservationService.WatchSeatAvailability(stream.Context(), showtimeID,
+		func(changes []service.SeatState) error {
+			return stream.Send(toSeatAvailability(showtimeID, changes))
+		})
+}
@@ -0,0 +1,144 @@
+// Package grpcapi serves the movie, hall, showtime and reservation operations over gRPC.
+// It calls the same services as the HTTP API, so the permission checks are the same,
+// and callers authenticate with the same access tokens and API keys
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+)
+
+// New builds the server with the services of app registered, opts are added
+// to the interceptors, e.g. the transport credentials
+func New(app *app.App, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(
+			recoveryUnary(app.Logger),
+			logUnary(app.Logger),
+			errorUnary(app.Logger),
+			authUnary(app.TokenService, app.APIKeyService),
+			rateLimitUnary(app.RateLimiter, app.RateLimits),
+		),
+		grpc.ChainStreamInterceptor(
+			recoveryStream(app.Logger),
+			logStream(app.Logger),
+			errorStream(app.Logger),
+			authStream(app.TokenService, app.APIKeyService),
+			rateLimitStream(app.RateLimiter, app.RateLimits),
+		),
+	)
+	server := grpc.NewServer(opts...)
+	pb.RegisterMovieServiceServer(server, &movieServer{app: app})
+	pb.RegisterHallServiceServer(server, &hallServer{app: app})
+	pb.RegisterShowtimeServiceServer(server, &showtimeServer{app: app})
+	pb.RegisterReservationServiceServer(server, &reservationServer{app: app})
+	return server
+}
+
+// logUnary logs every call at INFO level once it is handled
+func logUnary(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(logger, info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+func logStream(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		logCall(logger, info.FullMethod, err, start)
+		return err
+	}
+}
+
+func logCall(logger *zap.Logger, method string, err error, start time.Time) {
+	logger.Info("call",
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("latency", time.Since(start)),
+	)
+}
+
+// errorUnary turns the errors of the services into statuses,
+// logging them at ERROR level for server errors and at WARN level otherwise
+func errorUnary(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatusError(logger, info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+func errorStream(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, stream); err != nil {
+			return toStatusError(logger, info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+func toStatusError(logger *zap.Logger, method string, err error) error {
+	// errors of the transport, e.g. a cancelled stream, already are statuses
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	st := statusOf(err)
+	level := zapcore.WarnLevel
+	if st.Code() == codes.Internal {
+		level = zapcore.ErrorLevel
+	}
+	logger.Log(level, "call failed",
+		zap.String("method", method),
+		zap.String("code", st.Code().String()),
+		zap.Error(err),
+	)
+	return st.Err()
+}
+
+// recoveryUnary turns a panic into an Internal status and logs it with the stack
+func recoveryUnary(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoveredError(logger, info.FullMethod, recovered)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func recoveryStream(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoveredError(logger, info.FullMethod, recovered)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+func recoveredError(logger *zap.Logger, method string, recovered any) error {
+	logger.Error("panic while handling call",
+		zap.String("method", method),
+		zap.Any("panic", recovered),
+		zap.Stack("stack"),
+	)
+	return statusOf(fmt.Errorf("panic: %v", recovered)).Err()
+}
@@ -0,0 +1,306 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// testServer is the gRPC API of an app on sqlite and an in-memory redis,
+// served over an in-memory connection
+type testServer struct {
+	app  *app.App
+	conn *grpc.ClientConn
+}
+
+func newTestServer(t *testing.T, limits map[string]service.RateLimit) *testServer {
+	t.Helper()
+	redis := cache.NewRedisCache(miniredis.RunT(t).Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&model.User{},
+		&model.APIKey{},
+		&model.Cinema{},
+		&model.Hall{},
+		&model.Movie{},
+		&model.Showtime{},
+		&model.Reservation{},
+		&model.SeatBlock{},
+		&model.HallMaintenance{},
+	))
+
+	userRepo := repository.NewUserRepoGorm(db)
+	hallRepo := repository.NewHallRepoGorm(db)
+	movieRepo := repository.NewMovieRepoGorm(db)
+	showtimeRepo := repository.NewShowtimeRepoGorm(db)
+	reservationRepo := repository.NewReservationRepoGorm(db)
+	maintenanceRepo := repository.NewHallMaintenanceRepoGorm(db)
+	seatBlockRepo := repository.NewSeatBlockRepoGorm(db)
+	sessions := auth.NewRedisSessionStore(redis)
+	a := &app.App{
+		DB:          db,
+		Cache:       redis,
+		Logger:      zap.NewNop(),
+		SeatEvents:  service.NewRedisSeatEvents(redis),
+		RateLimiter: service.NewMemoryRateLimiter(),
+		RateLimits:  limits,
+	}
+	a.UserService = service.NewUserService(db, userRepo, reservationRepo,
+		service.NewLoginThrottle(service.NewMemoryAttemptStore()), nil, sessions, a.SeatEvents)
+	a.APIKeyService = service.NewAPIKeyService(db, repository.NewAPIKeyRepoGorm(db), service.NewMemoryRequestCounter())
+	a.ShowtimeService = service.NewShowtimeService(db, showtimeRepo, movieRepo, hallRepo, maintenanceRepo, nil)
+	a.HallService = service.NewHallService(db, hallRepo, repository.NewCinemaRepoGorm(db), a.ShowtimeService, nil)
+	a.MovieService = service.NewMovieService(db, movieRepo, a.ShowtimeService, nil)
+	a.ReservationService = service.NewReservationService(db, reservationRepo, showtimeRepo, hallRepo,
+		seatBlockRepo, maintenanceRepo, userRepo, nil, service.NewLookupCodes("lookup-secret"), a.SeatEvents, nil)
+	keyring, err := auth.NewKeyring("k1", "grpc-test-signing-secret", "")
+	require.NoError(t, err)
+	a.TokenService = auth.NewTokenService(keyring, sessions, a.UserService, nil)
+
+	listener := bufconn.Listen(1 << 20)
+	server := New(a)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return &testServer{app: a, conn: conn}
+}
+
+// bearer returns the context of calls made with an access token of a new user of the role
+func (s *testServer) bearer(t *testing.T, name string, role model.UserRole) (context.Context, *model.User) {
+	t.Helper()
+	user, err := s.app.UserService.Register(name, "Correct-Horse-9", model.UserProfile{Email: name + "@example.com"},
+		service.CaptchaSolution{})
+	require.NoError(t, err)
+	require.NoError(t, s.app.DB.Model(user).Updates(map[string]any{
+		"role": role, "status": model.UserActive, "email_verified_at": time.Now(),
+	}).Error)
+	user.Role, user.Status = role, model.UserActive
+	tokens, err := s.app.TokenService.IssueTokens(user)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tokens.AccessToken), user
+}
+
+// apiKey returns the context of calls made with a new API key of the scopes
+func (s *testServer) apiKey(t *testing.T, scopes ...model.Permission) context.Context {
+	t.Helper()
+	_, secret, err := s.app.APIKeyService.CreateAPIKey(service.SystemActor, "kiosk", scopes, nil, 0)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, secret)
+}
+
+// requireStatus checks the code of err and the error code of the HTTP API in its ErrorInfo
+func requireStatus(t *testing.T, err error, code codes.Code, reason service.Code) *status.Status {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status: %v", err)
+	require.Equal(t, code, st.Code(), st.Message())
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, string(reason), info.Reason)
+			assert.Equal(t, errorDomain, info.Domain)
+			return st
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", st.Details())
+	return st
+}
+
+func TestCallersAreAuthenticatedLikeTheHTTPAPI(t *testing.T) {
+	s := newTestServer(t, nil)
+	movies := pb.NewMovieServiceClient(s.conn)
+	create := &pb.CreateMovieRequest{Title: "Dune", RuntimeMinutes: 155}
+
+	// anonymous callers can read, but not change anything
+	_, err := movies.ListMovies(context.Background(), &pb.ListMoviesRequest{})
+	require.NoError(t, err)
+	_, err = movies.CreateMovie(context.Background(), create)
+	requireStatus(t, err, codes.Unauthenticated, service.CodeInvalidCredential)
+
+	userCtx, _ := s.bearer(t, "alice", model.RoleUser)
+	_, err = movies.CreateMovie(userCtx, create)
+	requireStatus(t, err, codes.PermissionDenied, service.CodeForbidden)
+	programmerCtx, _ := s.bearer(t, "paula", model.RoleProgrammer)
+	movie, err := movies.CreateMovie(programmerCtx, create)
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", movie.Title)
+
+	// an API key can do what its scopes allow
+	_, err = movies.CreateMovie(s.apiKey(t, model.PermViewReservations), create)
+	requireStatus(t, err, codes.PermissionDenied, service.CodeForbidden)
+	_, err = movies.CreateMovie(s.apiKey(t, model.PermManageMovies), &pb.CreateMovieRequest{
+		Title: "Arrival", RuntimeMinutes: 116,
+	})
+	require.NoError(t, err)
+
+	// bad credentials are refused even for reads
+	for _, ctx := range []context.Context{
+		metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer forged"),
+		metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic YWxpY2U6c2VjcmV0"),
+	} {
+		_, err = movies.ListMovies(ctx, &pb.ListMoviesRequest{})
+		requireStatus(t, err, codes.Unauthenticated, auth.CodeInvalidSessionToken)
+	}
+	_, err = movies.ListMovies(metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, "mrk_0_forged"),
+		&pb.ListMoviesRequest{})
+	requireStatus(t, err, codes.Unauthenticated, service.CodeInvalidCredential)
+
+	// validation errors carry the fields
+	_, err = movies.CreateMovie(programmerCtx, &pb.CreateMovieRequest{})
+	st := requireStatus(t, err, codes.InvalidArgument, service.CodeInvalidInput)
+	var fields []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	assert.Contains(t, fields, "title")
+}
+
+func TestMethodClass(t *testing.T) {
+	for method, class := range map[string]string{
+		pb.MovieService_ListMovies_FullMethodName:                   "read",
+		pb.MovieService_GetMovie_FullMethodName:                     "read",
+		pb.MovieService_CreateMovie_FullMethodName:                  "write",
+		pb.ReservationService_GetSeatAvailability_FullMethodName:    "read",
+		pb.ReservationService_WatchSeatAvailability_FullMethodName:  "read",
+		pb.ReservationService_CreateReservation_FullMethodName:      "booking",
+		pb.ReservationService_CancelReservation_FullMethodName:      "booking",
+		pb.ReservationService_CreateGuestReservation_FullMethodName: "booking",
+		pb.ShowtimeService_CreateShowtime_FullMethodName:            "write",
+	} {
+		assert.Equal(t, class, methodClass(method), method)
+	}
+}
+
+func TestRateLimitMetadata(t *testing.T) {
+	s := newTestServer(t, map[string]service.RateLimit{
+		"read": {Requests: 1, Per: time.Minute, Burst: 2},
+	})
+	movies := pb.NewMovieServiceClient(s.conn)
+
+	for _, remaining := range []string{"1", "0"} {
+		var header metadata.MD
+		_, err := movies.ListMovies(context.Background(), &pb.ListMoviesRequest{}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1;w=60;burst=2"}, header.Get("ratelimit-policy"))
+		assert.Equal(t, []string{"2"}, header.Get("ratelimit-limit"))
+		assert.Equal(t, []string{remaining}, header.Get("ratelimit-remaining"))
+	}
+	var header metadata.MD
+	_, err := movies.ListMovies(context.Background(), &pb.ListMoviesRequest{}, grpc.Header(&header))
+	st := requireStatus(t, err, codes.ResourceExhausted, service.CodeRateLimited)
+	assert.Equal(t, []string{"0"}, header.Get("ratelimit-remaining"))
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	require.NotNil(t, retry)
+	assert.Equal(t, time.Minute, retry.RetryDelay.AsDuration())
+
+	// a signed-in caller has a bucket of its own, and writes aren't limited here
+	userCtx, _ := s.bearer(t, "paula", model.RoleProgrammer)
+	_, err = movies.ListMovies(userCtx, &pb.ListMoviesRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-remaining"))
+	header = nil
+	_, err = movies.CreateMovie(userCtx, &pb.CreateMovieRequest{Title: "Dune", RuntimeMinutes: 155},
+		grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Empty(t, header.Get("ratelimit-remaining"))
+}
+
+func TestWatchSeatAvailability(t *testing.T) {
+	s := newTestServer(t, map[string]service.RateLimit{
+		"read": {Requests: 10, Per: time.Second, Burst: 10},
+	})
+	db := s.app.DB
+	cinema := &model.Cinema{Name: "Odeon", Timezone: "Europe/Berlin"}
+	require.NoError(t, db.Create(cinema).Error)
+	hall := &model.Hall{CinemaID: cinema.ID, Name: "Hall 1", Layout: model.RectangularLayout(2, 3)}
+	hall.ApplyLayout()
+	require.NoError(t, db.Create(hall).Error)
+	movie := &model.Movie{Title: "Dune", RuntimeMinutes: 155}
+	require.NoError(t, db.Create(movie).Error)
+	showtime := &model.Showtime{MovieID: movie.ID, HallID: hall.ID, StartAt: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, db.Create(showtime).Error)
+	reservations := pb.NewReservationServiceClient(s.conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := reservations.WatchSeatAvailability(ctx,
+		&pb.WatchSeatAvailabilityRequest{ShowtimeId: uint64(showtime.ID)})
+	require.NoError(t, err)
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"9"}, header.Get("ratelimit-remaining"))
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(showtime.ID), first.ShowtimeId)
+	require.Len(t, first.Seats, 6)
+	for _, seat := range first.Seats {
+		assert.Equal(t, pb.SeatStatus_SEAT_STATUS_AVAILABLE, seat.Status)
+	}
+
+	// a booking made over gRPC reaches the stream as the one seat that changed
+	userCtx, user := s.bearer(t, "alice", model.RoleUser)
+	reservation, err := reservations.CreateReservation(userCtx, &pb.CreateReservationRequest{
+		ShowtimeId: uint64(showtime.ID), SeatId: 5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(user.ID), reservation.GetUserId())
+	changes, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, changes.Seats, 1)
+	assert.Equal(t, uint64(5), changes.Seats[0].SeatId)
+	assert.Equal(t, pb.SeatStatus_SEAT_STATUS_RESERVED, changes.Seats[0].Status)
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	// a missing showtime ends the stream with its error
+	stream, err = reservations.WatchSeatAvailability(context.Background(), &pb.WatchSeatAvailabilityRequest{ShowtimeId: 42})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	requireStatus(t, err, codes.NotFound, service.CodeShowtimeNotFound)
+}
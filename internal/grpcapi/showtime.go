@@ -0,0 +1,65 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	pb "github.com/qs-lzh/movie-reservation/internal/gen/moviereservation/v1"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+type showtimeServer struct {
+	pb.UnimplementedShowtimeServiceServer
+	app *app.App
+}
+
+// ListShowtimes returns all showtimes, or those filtered by one of movie_id, hall_id and cinema_id
+func (s *showtimeServer) ListShowtimes(_ context.Context, req *pb.ListShowtimesRequest) (*pb.ListShowtimesResponse, error) {
+	var showtimes []model.Showtime
+	var err error
+	switch {
+	case req.MovieId != 0 && req.HallId == 0 && req.CinemaId == 0:
+		showtimes, err = s.app.ShowtimeService.GetShowtimesByMovieID(uint(req.MovieId))
+	case req.HallId != 0 && req.MovieId == 0 && req.CinemaId == 0:
+		showtimes, err = s.app.ShowtimeService.GetShowtimesByHallID(uint(req.HallId))
+	case req.CinemaId != 0 && req.MovieId == 0 && req.HallId == 0:
+		showtimes, err = s.app.ShowtimeService.GetShowtimesByCinemaID(uint(req.CinemaId))
+	case req.MovieId == 0 && req.HallId == 0 && req.CinemaId == 0:
+		showtimes, err = s.app.ShowtimeService.GetAllShowtimes()
+	default:
+		return nil, service.NewError(service.CodeInvalidInput, "only one of movie_id, hall_id and cinema_id can be given")
+	}
+	if err != nil {
+		return nil, err
+	}
+	response := &pb.ListShowtimesResponse{Showtimes: make([]*pb.Showtime, 0, len(showtimes))}
+	for i := range showtimes {
+		response.Showtimes = append(response.Showtimes, toShowtime(&showtimes[i]))
+	}
+	return response, nil
+}
+
+func (s *showtimeServer) GetShowtime(_ context.Context, req *pb.GetShowtimeRequest) (*pb.Showtime, error) {
+	id, err := requireID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	showtime, err := s.app.ShowtimeService.GetShowtimeByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toShowtime(showtime), nil
+}
+
+func (s *showtimeServer) CreateShowtime(ctx context.Context, req *pb.CreateShowtimeRequest) (*pb.Showtime, error) {
+	actor, err := requireActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	showtime, err := s.app.ShowtimeService.CreateShowtime(actor, uint(req.MovieId), timeOf(req.StartAt), uint(req.HallId))
+	if err != nil {
+		return nil, err
+	}
+	return toShowtime(showtime), nil
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type APIKeyHandler struct {
+	app *app.App
+}
+
+func NewAPIKeyHandler(app *app.App) *APIKeyHandler {
+	return &APIKeyHandler{app: app}
+}
+
+// the secret of the key is never returned, prefix identifies it
+type apiKeyResponse struct {
+	ID          uint               `json:"id"`
+	Name        string             `json:"name"`
+	Prefix      string             `json:"prefix"`
+	Scopes      []model.Permission `json:"scopes"`
+	RateLimit   int                `json:"rate_limit"`
+	ExpiresAt   *time.Time         `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time         `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time         `json:"revoked_at,omitempty"`
+	CreatedByID uint               `json:"created_by_id"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+func newAPIKeyResponse(key *model.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Scopes:      key.Scopes,
+		RateLimit:   key.RateLimit,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+		CreatedByID: key.CreatedByID,
+		CreatedAt:   key.CreatedAt,
+	}
+}
+
+// createdAPIKeyResponse is the only response with the key itself
+type createdAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+// rate_limit is per minute, 0 for the default
+type apiKeyRequest struct {
+	Name      string             `json:"name"`
+	Scopes    []model.Permission `json:"scopes"`
+	ExpiresAt *time.Time         `json:"expires_at"`
+	RateLimit int                `json:"rate_limit"`
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	actor, _ := actorOf(c)
+	keys, err := h.app.APIKeyService.GetAPIKeys(actor)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]apiKeyResponse, 0, len(keys))
+	for i := range keys {
+		response = append(response, newAPIKeyResponse(&keys[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Create returns the key once, it is sent in the X-API-Key header
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var request apiKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	key, secret, err := h.app.APIKeyService.CreateAPIKey(actor, request.Name, request.Scopes,
+		request.ExpiresAt, request.RateLimit)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdAPIKeyResponse{apiKeyResponse: newAPIKeyResponse(key), Key: secret})
+}
+
+// Revoke stops the key from working, it is kept for the records
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.APIKeyService.RevokeAPIKey(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
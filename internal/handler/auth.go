@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type AuthHandler struct {
+	app *app.App
+}
+
+func NewAuthHandler(app *app.App) *AuthHandler {
+	return &AuthHandler{app: app}
+}
+
+type registerRequest struct {
+	Name        string          `json:"name"`
+	Password    string          `json:"password"`
+	Email       string          `json:"email"`
+	DisplayName string          `json:"display_name"`
+	Phone       string          `json:"phone"`
+	Captcha     *captchaRequest `json:"captcha"`
+}
+
+type userResponse struct {
+	ID            uint             `json:"id"`
+	Name          string           `json:"name"`
+	Role          model.UserRole   `json:"role"`
+	Status        model.UserStatus `json:"status"`
+	Email         string           `json:"email"`
+	EmailVerified bool             `json:"email_verified"`
+	DisplayName   string           `json:"display_name"`
+	Phone         string           `json:"phone"`
+}
+
+func newUserResponse(user *model.User) userResponse {
+	return userResponse{
+		ID:            user.ID,
+		Name:          user.Name,
+		Role:          user.Role,
+		Status:        user.Status,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		DisplayName:   user.DisplayName,
+		Phone:         user.Phone,
+	}
+}
+
+// otp is the TOTP or recovery code of users with two factors enabled
+type loginRequest struct {
+	Name     string          `json:"name"`
+	Password string          `json:"password"`
+	OTP      string          `json:"otp"`
+	Captcha  *captchaRequest `json:"captcha"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+}
+
+func newTokenResponse(pair *auth.TokenPair) tokenResponse {
+	return tokenResponse{
+		AccessToken:      pair.AccessToken,
+		AccessExpiresAt:  pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+		TokenType:        "Bearer",
+	}
+}
+
+type tokenRequest struct {
+	Token string `json:"token"`
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Register creates an unverified user and mails the verification link
+func (h *AuthHandler) Register(c *gin.Context) {
+	var request registerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	user, err := h.app.UserService.Register(request.Name, request.Password, model.UserProfile{
+		Email:       request.Email,
+		DisplayName: request.DisplayName,
+		Phone:       request.Phone,
+	}, request.Captcha.solution())
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	// the user can ask for another mail, so the registration still succeeds
+	if err := h.app.AccountService.SendEmailVerification(user.ID); err != nil {
+		h.app.Logger.Error("send email verification", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	c.JSON(http.StatusCreated, newUserResponse(user))
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var request loginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	pair, err := h.app.TokenService.Login(request.Name, request.Password, c.ClientIP(),
+		request.Captcha.solution(), request.OTP)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTokenResponse(pair))
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var request refreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	pair, err := h.app.TokenService.Refresh(request.RefreshToken)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTokenResponse(pair))
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var request refreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if err := h.app.TokenService.Logout(request.RefreshToken); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll ends every session of the signed-in user, on every device
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	actor, _ := actorOf(c)
+	if err := h.app.TokenService.LogoutAll(actor.UserID); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the signed-in user
+func (h *AuthHandler) Me(c *gin.Context) {
+	actor, _ := actorOf(c)
+	user, err := h.app.UserService.GetUserByID(actor.UserID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// Captcha returns a new challenge, whose solution is sent along with
+// the login, registration or booking that asked for it
+func (h *AuthHandler) Captcha(c *gin.Context) {
+	challenge, err := h.app.CaptchaService.Generate()
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, challenge)
+}
+
+func (h *AuthHandler) SendEmailVerification(c *gin.Context) {
+	actor, _ := actorOf(c)
+	if err := h.app.AccountService.SendEmailVerification(actor.UserID); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var request tokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if err := h.app.AccountService.VerifyEmail(request.Token); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RequestPasswordReset answers the same whether a user has the email or not
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var request passwordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if err := h.app.AccountService.RequestPasswordReset(request.Email); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var request resetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	if err := h.app.AccountService.ResetPasswordWithToken(request.Token, request.Password); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type CinemaHandler struct {
+	app *app.App
+}
+
+func NewCinemaHandler(app *app.App) *CinemaHandler {
+	return &CinemaHandler{app: app}
+}
+
+type cinemaResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	Timezone     string `json:"timezone"`
+	ContactEmail string `json:"contact_email"`
+	ContactPhone string `json:"contact_phone"`
+}
+
+func newCinemaResponse(cinema *model.Cinema) cinemaResponse {
+	return cinemaResponse{
+		ID:           cinema.ID,
+		Name:         cinema.Name,
+		Address:      cinema.Address,
+		Timezone:     cinema.Timezone,
+		ContactEmail: cinema.ContactEmail,
+		ContactPhone: cinema.ContactPhone,
+	}
+}
+
+// timezone is an IANA name, e.g. Europe/Berlin
+type cinemaRequest struct {
+	Name         string `json:"name"`
+	Address      string `json:"address"`
+	Timezone     string `json:"timezone"`
+	ContactEmail string `json:"contact_email"`
+	ContactPhone string `json:"contact_phone"`
+}
+
+func (r *cinemaRequest) cinema(id uint) *model.Cinema {
+	return &model.Cinema{
+		ID:           id,
+		Name:         r.Name,
+		Address:      r.Address,
+		Timezone:     r.Timezone,
+		ContactEmail: r.ContactEmail,
+		ContactPhone: r.ContactPhone,
+	}
+}
+
+func (h *CinemaHandler) List(c *gin.Context) {
+	cinemas, err := h.app.CinemaService.GetAllCinemas()
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]cinemaResponse, 0, len(cinemas))
+	for i := range cinemas {
+		response = append(response, newCinemaResponse(&cinemas[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *CinemaHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	cinema, err := h.app.CinemaService.GetCinemaByID(id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newCinemaResponse(cinema))
+}
+
+func (h *CinemaHandler) Create(c *gin.Context) {
+	var request cinemaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	cinema := request.cinema(0)
+	if err := h.app.CinemaService.CreateCinema(actor, cinema); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newCinemaResponse(cinema))
+}
+
+func (h *CinemaHandler) Update(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request cinemaRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	cinema := request.cinema(id)
+	if err := h.app.CinemaService.UpdateCinema(actor, cinema); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newCinemaResponse(cinema))
+}
+
+// Delete is refused while the cinema has halls
+func (h *CinemaHandler) Delete(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.CinemaService.DeleteCinemaByID(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
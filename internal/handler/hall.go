@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type HallHandler struct {
+	app *app.App
+}
+
+func NewHallHandler(app *app.App) *HallHandler {
+	return &HallHandler{app: app}
+}
+
+type hallResponse struct {
+	ID        uint             `json:"id"`
+	CinemaID  uint             `json:"cinema_id"`
+	Name      string           `json:"name"`
+	SeatCount int              `json:"seat_count"`
+	Rows      int              `json:"rows"`
+	Cols      int              `json:"cols"`
+	Layout    model.HallLayout `json:"layout"`
+	Version   uint             `json:"version"`
+}
+
+func newHallResponse(hall *model.Hall) hallResponse {
+	return hallResponse{
+		ID:        hall.ID,
+		CinemaID:  hall.CinemaID,
+		Name:      hall.Name,
+		SeatCount: hall.SeatCount,
+		Rows:      hall.Rows,
+		Cols:      hall.Cols,
+		Layout:    hall.SeatLayout(),
+		Version:   hall.Version,
+	}
+}
+
+// hallRequest has either a layout, or rows and cols for a rectangular hall
+type hallRequest struct {
+	CinemaID uint              `json:"cinema_id"`
+	Name     string            `json:"name"`
+	Rows     int               `json:"rows"`
+	Cols     int               `json:"cols"`
+	Layout   *model.HallLayout `json:"layout"`
+	// only read by PUT, the version the client read
+	Version uint `json:"version"`
+}
+
+func (r *hallRequest) hall(id uint) *model.Hall {
+	hall := &model.Hall{
+		ID:       id,
+		CinemaID: r.CinemaID,
+		Name:     r.Name,
+		Rows:     r.Rows,
+		Cols:     r.Cols,
+		Version:  r.Version,
+	}
+	if r.Layout != nil {
+		hall.Layout = *r.Layout
+	}
+	return hall
+}
+
+// the fields of PATCH are optional, version is required
+type patchHallRequest struct {
+	Name    *string           `json:"name"`
+	Layout  *model.HallLayout `json:"layout"`
+	Version uint              `json:"version"`
+}
+
+// List returns all halls, or those of the cinema of ?cinema_id=
+func (h *HallHandler) List(c *gin.Context) {
+	cinemaID, ok := idQuery(c, "cinema_id")
+	if !ok {
+		return
+	}
+	var halls []model.Hall
+	var err error
+	if cinemaID != 0 {
+		halls, err = h.app.HallService.GetHallsByCinemaID(cinemaID)
+	} else {
+		halls, err = h.app.HallService.GetAllHalls()
+	}
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]hallResponse, 0, len(halls))
+	for i := range halls {
+		response = append(response, newHallResponse(&halls[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HallHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	hall, err := h.app.HallService.GetHallByID(id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newHallResponse(hall))
+}
+
+func (h *HallHandler) Create(c *gin.Context) {
+	var request hallRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	hall := request.hall(0)
+	if err := h.app.HallService.CreateHall(actor, hall); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newHallResponse(hall))
+}
+
+// Update replaces the hall, which is refused while it has showtimes
+func (h *HallHandler) Update(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request hallRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	hall := request.hall(id)
+	if err := h.app.HallService.UpdateHall(actor, hall); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newHallResponse(hall))
+}
+
+func (h *HallHandler) Patch(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request patchHallRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	hall, err := h.app.HallService.PatchHall(actor, id, request.Version, model.HallPatch{
+		Name:   request.Name,
+		Layout: request.Layout,
+	})
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newHallResponse(hall))
+}
+
+func (h *HallHandler) Delete(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.HallService.DeleteHallByID(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
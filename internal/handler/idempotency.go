@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotencyReplayedHeader marks the responses that were replayed
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	// maxIdempotentBodyBytes bounds the body read into memory to hash and replay the request
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+const (
+	// idempotencyPendingTTL frees the key of a request that never completed, e.g. after a crash
+	idempotencyPendingTTL = time.Minute
+	// idempotencyTTL is how long retries get the stored response
+	idempotencyTTL = 24 * time.Hour
+)
+
+// replayedHeaders are the headers of the response stored with its status and body
+var replayedHeaders = []string{"Content-Type", "Location", "Retry-After"}
+
+// Idempotent makes a request carrying an Idempotency-Key run once: retries with the same key
+// and request get the stored response, a different request with the key is refused.
+// Server errors are not stored, so the request can be retried.
+// Keys are scoped by the actor, so it must run after Authenticate,
+// anonymous keys by the client IP and the request, see idempotencyStoreKey
+func Idempotent(store service.IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			httperror.Write(c, &service.ValidationError{Fields: []service.FieldError{{
+				Field:   idempotencyKeyHeader,
+				Rule:    service.RuleInvalid,
+				Message: fmt.Sprintf("must be 1 to %d printable ASCII characters", maxIdempotencyKeyLength),
+			}}})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes)
+		body, err := c.GetRawData()
+		if err != nil {
+			invalidBody(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := idempotencyRequestHash(c, body)
+		storeKey := idempotencyStoreKey(c, key, requestHash)
+		record, claimed, err := store.Claim(storeKey, requestHash, idempotencyPendingTTL)
+		if err != nil {
+			httperror.Write(c, err)
+			return
+		}
+		if !claimed {
+			switch {
+			case record.RequestHash != requestHash:
+				httperror.Write(c, service.ErrIdempotencyKeyReused)
+			case !record.Done:
+				httperror.Write(c, service.ErrIdempotencyPending)
+			default:
+				replay(c, record.Response)
+			}
+			return
+		}
+
+		completed := false
+		defer func() {
+			// a panic or server error leaves nothing to replay
+			if !completed {
+				_ = store.Release(storeKey)
+			}
+		}()
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		response := service.IdempotentResponse{
+			Status: status,
+			Header: make(map[string]string),
+			Body:   writer.body.Bytes(),
+		}
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				response.Header[name] = value
+			}
+		}
+		record.Done = true
+		record.Response = response
+		if err := store.Complete(storeKey, record, idempotencyTTL); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		completed = true
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// idempotencyStoreKey scopes the key by the actor. Anonymous clients share no
+// identity, so their keys are scoped by the client IP and the request hash,
+// another client sending the same key can't get the response, like the lookup
+// code of a guest reservation, unless it sends the same request from the same IP
+func idempotencyStoreKey(c *gin.Context, key, requestHash string) string {
+	actor, ok := actorOf(c)
+	if !ok {
+		return fmt.Sprintf("idempotency:guest:%s:%s:%s", c.ClientIP(), requestHash, key)
+	}
+	return fmt.Sprintf("idempotency:%d:%d:%s", actor.UserID, actor.APIKeyID, key)
+}
+
+// idempotencyRequestHash identifies the request, a retry has the same method, URL and body
+func idempotencyRequestHash(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", c.Request.Method, c.Request.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(c *gin.Context, response service.IdempotentResponse) {
+	for name, value := range response.Header {
+		c.Header(name, value)
+	}
+	c.Header(idempotencyReplayedHeader, "true")
+	c.Status(response.Status)
+	_, _ = c.Writer.Write(response.Body)
+	c.Abort()
+}
+
+// recordingWriter keeps a copy of the body written to the client
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type MaintenanceHandler struct {
+	app *app.App
+}
+
+func NewMaintenanceHandler(app *app.App) *MaintenanceHandler {
+	return &MaintenanceHandler{app: app}
+}
+
+// a block is for showtime_id, or for every showtime of the hall within [start_at, end_at)
+type seatBlockResponse struct {
+	ID         uint       `json:"id"`
+	HallID     uint       `json:"hall_id"`
+	SeatID     uint       `json:"seat_id"`
+	ShowtimeID *uint      `json:"showtime_id,omitempty"`
+	StartAt    *time.Time `json:"start_at,omitempty"`
+	EndAt      *time.Time `json:"end_at,omitempty"`
+	Reason     string     `json:"reason"`
+}
+
+func newSeatBlockResponse(block *model.SeatBlock) seatBlockResponse {
+	return seatBlockResponse{
+		ID:         block.ID,
+		HallID:     block.HallID,
+		SeatID:     block.SeatID,
+		ShowtimeID: block.ShowtimeID,
+		StartAt:    block.StartAt,
+		EndAt:      block.EndAt,
+		Reason:     block.Reason,
+	}
+}
+
+// either showtime_id, or hall_id with start_at and end_at
+type seatBlockRequest struct {
+	HallID     uint       `json:"hall_id"`
+	SeatID     uint       `json:"seat_id"`
+	ShowtimeID *uint      `json:"showtime_id"`
+	StartAt    *time.Time `json:"start_at"`
+	EndAt      *time.Time `json:"end_at"`
+	Reason     string     `json:"reason"`
+}
+
+type maintenanceWindowResponse struct {
+	ID      uint      `json:"id"`
+	HallID  uint      `json:"hall_id"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+	Reason  string    `json:"reason"`
+}
+
+func newMaintenanceWindowResponse(maintenance *model.HallMaintenance) maintenanceWindowResponse {
+	return maintenanceWindowResponse{
+		ID:      maintenance.ID,
+		HallID:  maintenance.HallID,
+		StartAt: maintenance.StartAt,
+		EndAt:   maintenance.EndAt,
+		Reason:  maintenance.Reason,
+	}
+}
+
+type maintenanceWindowRequest struct {
+	HallID  uint      `json:"hall_id"`
+	StartAt time.Time `json:"start_at"`
+	EndAt   time.Time `json:"end_at"`
+	Reason  string    `json:"reason"`
+}
+
+// ListSeatBlocks returns the seat blocks of the hall
+func (h *MaintenanceHandler) ListSeatBlocks(c *gin.Context) {
+	hallID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	blocks, err := h.app.MaintenanceService.GetSeatBlocksByHallID(hallID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]seatBlockResponse, 0, len(blocks))
+	for i := range blocks {
+		response = append(response, newSeatBlockResponse(&blocks[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *MaintenanceHandler) BlockSeat(c *gin.Context) {
+	var request seatBlockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	block := &model.SeatBlock{
+		HallID:     request.HallID,
+		SeatID:     request.SeatID,
+		ShowtimeID: request.ShowtimeID,
+		StartAt:    request.StartAt,
+		EndAt:      request.EndAt,
+		Reason:     request.Reason,
+	}
+	if err := h.app.MaintenanceService.BlockSeat(actor, block); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newSeatBlockResponse(block))
+}
+
+func (h *MaintenanceHandler) UnblockSeat(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.MaintenanceService.UnblockSeat(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMaintenanceWindows returns the maintenance windows of the hall
+func (h *MaintenanceHandler) ListMaintenanceWindows(c *gin.Context) {
+	hallID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	windows, err := h.app.MaintenanceService.GetHallMaintenancesByHallID(hallID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]maintenanceWindowResponse, 0, len(windows))
+	for i := range windows {
+		response = append(response, newMaintenanceWindowResponse(&windows[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ScheduleMaintenance makes the whole hall unavailable within [start_at, end_at),
+// showtimes can't be scheduled into the window
+func (h *MaintenanceHandler) ScheduleMaintenance(c *gin.Context) {
+	var request maintenanceWindowRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	maintenance := &model.HallMaintenance{
+		HallID:  request.HallID,
+		StartAt: request.StartAt,
+		EndAt:   request.EndAt,
+		Reason:  request.Reason,
+	}
+	if err := h.app.MaintenanceService.ScheduleHallMaintenance(actor, maintenance); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newMaintenanceWindowResponse(maintenance))
+}
+
+func (h *MaintenanceHandler) CancelMaintenance(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.MaintenanceService.CancelHallMaintenance(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
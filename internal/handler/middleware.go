@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+const (
+	actorKey     = "actor"
+	apiKeyHeader = "X-API-Key"
+)
+
+// Authenticate reads the actor from a Bearer access token or an X-API-Key header,
+// requests without either go on anonymously and are refused by RequireActor
+func Authenticate(tokens auth.TokenService, apiKeys service.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret := c.GetHeader(apiKeyHeader); secret != "" {
+			actor, err := apiKeys.Authenticate(secret)
+			if err != nil {
+				httperror.Write(c, err)
+				return
+			}
+			c.Set(actorKey, actor)
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			httperror.Write(c, auth.ErrInvalidToken)
+			return
+		}
+		claims, err := tokens.ParseAccessToken(token)
+		if err != nil {
+			httperror.Write(c, err)
+			return
+		}
+		c.Set(actorKey, claims.Actor())
+		c.Next()
+	}
+}
+
+// RequireActor refuses anonymous requests
+func RequireActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := actorOf(c); !ok {
+			httperror.Write(c, service.ErrInvalidCredential)
+			return
+		}
+		c.Next()
+	}
+}
+
+// actorOf returns the actor set by Authenticate, false for anonymous requests
+func actorOf(c *gin.Context) (service.Actor, bool) {
+	value, ok := c.Get(actorKey)
+	if !ok {
+		return service.Actor{}, false
+	}
+	actor, ok := value.(service.Actor)
+	return actor, ok
+}
+
+// idParam parses the path parameter as an ID, it responds 400 and returns false if it isn't one
+func idParam(c *gin.Context, name string) (uint, bool) {
+	id, err := parseID(c.Param(name))
+	if err != nil {
+		invalidID(c, name)
+		return 0, false
+	}
+	return id, true
+}
+
+// idQuery is idParam for an optional query parameter, 0 means it is absent
+func idQuery(c *gin.Context, name string) (uint, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	id, err := parseID(value)
+	if err != nil {
+		invalidID(c, name)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, strconv.ErrRange
+	}
+	return uint(id), nil
+}
+
+func invalidID(c *gin.Context, name string) {
+	httperror.Write(c, &service.ValidationError{Fields: []service.FieldError{{
+		Field:   name,
+		Rule:    service.RuleInvalid,
+		Message: "must be a positive integer",
+	}}})
+}
+
+// invalidBody responds 400 to a body that doesn't decode into the request
+func invalidBody(c *gin.Context, err error) {
+	httperror.InvalidRequest(c, "invalid request body: "+err.Error())
+}
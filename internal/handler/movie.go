@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type MovieHandler struct {
+	app *app.App
+}
+
+func NewMovieHandler(app *app.App) *MovieHandler {
+	return &MovieHandler{app: app}
+}
+
+type movieResponse struct {
+	ID             uint   `json:"id"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	RuntimeMinutes uint   `json:"runtime_minutes"`
+	Version        uint   `json:"version"`
+}
+
+func newMovieResponse(movie *model.Movie) movieResponse {
+	return movieResponse{
+		ID:             movie.ID,
+		Title:          movie.Title,
+		Description:    movie.Description,
+		RuntimeMinutes: movie.RuntimeMinutes,
+		Version:        movie.Version,
+	}
+}
+
+type movieRequest struct {
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	RuntimeMinutes uint   `json:"runtime_minutes"`
+	// only read by PUT, the version the client read
+	Version uint `json:"version"`
+}
+
+// the fields of PATCH are optional, version is required
+type patchMovieRequest struct {
+	Title          *string `json:"title"`
+	Description    *string `json:"description"`
+	RuntimeMinutes *uint   `json:"runtime_minutes"`
+	Version        uint    `json:"version"`
+}
+
+// List returns all movies, or those shown in the cinema of ?cinema_id=
+func (h *MovieHandler) List(c *gin.Context) {
+	cinemaID, ok := idQuery(c, "cinema_id")
+	if !ok {
+		return
+	}
+	var movies []model.Movie
+	var err error
+	if cinemaID != 0 {
+		movies, err = h.app.MovieService.GetMoviesByCinemaID(cinemaID)
+	} else {
+		movies, err = h.app.MovieService.GetAllMovies()
+	}
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]movieResponse, 0, len(movies))
+	for i := range movies {
+		response = append(response, newMovieResponse(&movies[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *MovieHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	movie, err := h.app.MovieService.GetMovieByID(id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newMovieResponse(movie))
+}
+
+func (h *MovieHandler) Create(c *gin.Context) {
+	var request movieRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	movie := &model.Movie{
+		Title:          request.Title,
+		Description:    request.Description,
+		RuntimeMinutes: request.RuntimeMinutes,
+	}
+	if err := h.app.MovieService.CreateMovie(actor, movie); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newMovieResponse(movie))
+}
+
+// Update replaces the movie, the body carries the version the client read
+func (h *MovieHandler) Update(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request movieRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	movie := &model.Movie{
+		ID:             id,
+		Title:          request.Title,
+		Description:    request.Description,
+		RuntimeMinutes: request.RuntimeMinutes,
+		Version:        request.Version,
+	}
+	if err := h.app.MovieService.UpdateMovie(actor, movie); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newMovieResponse(movie))
+}
+
+func (h *MovieHandler) Patch(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request patchMovieRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	movie, err := h.app.MovieService.PatchMovie(actor, id, request.Version, model.MoviePatch{
+		Title:          request.Title,
+		Description:    request.Description,
+		RuntimeMinutes: request.RuntimeMinutes,
+	})
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newMovieResponse(movie))
+}
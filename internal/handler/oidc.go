@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+// OIDCHandler signs users in at the configured OpenID Connect providers
+// and manages the identities linked to the accounts
+type OIDCHandler struct {
+	app *app.App
+}
+
+func NewOIDCHandler(app *app.App) *OIDCHandler {
+	return &OIDCHandler{app: app}
+}
+
+type providersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+type authURLResponse struct {
+	URL string `json:"url"`
+}
+
+type identityResponse struct {
+	ID        uint      `json:"id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newIdentityResponse(identity *model.UserIdentity) identityResponse {
+	return identityResponse{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
+
+// oidc returns the service, it responds 404 and returns false
+// when no provider is configured
+func (h *OIDCHandler) oidc(c *gin.Context) (auth.OIDCService, bool) {
+	if h.app.OIDCService == nil {
+		httperror.Write(c, auth.ErrUnknownProvider)
+		return nil, false
+	}
+	return h.app.OIDCService, true
+}
+
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	providers := []string{}
+	if h.app.OIDCService != nil {
+		providers = h.app.OIDCService.Providers()
+	}
+	c.JSON(http.StatusOK, providersResponse{Providers: providers})
+}
+
+// Login redirects the browser to sign in at the provider, which sends it back to Callback
+func (h *OIDCHandler) Login(c *gin.Context) {
+	oidc, ok := h.oidc(c)
+	if !ok {
+		return
+	}
+	url, err := oidc.AuthURL(c.Param("provider"))
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Redirect(http.StatusFound, url)
+}
+
+// Link returns the URL to sign in at the provider, the identity is then added
+// to the account of the signed-in user. It is not a redirect because
+// the browser would not send the access token along
+func (h *OIDCHandler) Link(c *gin.Context) {
+	oidc, ok := h.oidc(c)
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	url, err := oidc.LinkURL(actor, c.Param("provider"))
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, authURLResponse{URL: url})
+}
+
+// Callback is the redirect URL registered at the providers, each state is used once
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	oidc, ok := h.oidc(c)
+	if !ok {
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		// the provider sends error instead of code when the user declined
+		httperror.InvalidRequest(c, "state and code are required")
+		return
+	}
+	pair, err := oidc.Callback(c.Request.Context(), state, code)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newTokenResponse(pair))
+}
+
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	identities, err := h.app.IdentityService.GetIdentities(actor, id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]identityResponse, 0, len(identities))
+	for i := range identities {
+		response = append(response, newIdentityResponse(&identities[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UnlinkIdentity keeps the last identity of a user without a password
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	identityID, ok := idParam(c, "identity_id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.IdentityService.UnlinkIdentity(actor, id, identityID); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// RateLimit takes a token from the bucket of the caller in the route class of the request:
+// an API key, a signed-in user or the client IP of an anonymous request.
+// Classes without a limit are not limited. The responses tell the state of the bucket
+// in the RateLimit-* headers, a refused request gets 429 rate_limited with Retry-After.
+// Running before Authenticate, it limits every request by its client IP
+func RateLimit(limiter service.RateLimiter, limits map[string]service.RateLimit,
+	classOf func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		class := classOf(c)
+		limit, ok := limits[class]
+		if !ok {
+			c.Next()
+			return
+		}
+		decision, err := limiter.Allow("ratelimit:"+class+":"+rateLimitSubject(c), limit)
+		if err != nil {
+			// an unavailable limiter doesn't take the API down
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests,
+			int(limit.Per.Seconds()), limit.Burst))
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))))
+		if !decision.Allowed {
+			httperror.Write(c, &service.RetryAfterError{Err: service.ErrRateLimited, RetryAfter: decision.RetryAfter})
+			return
+		}
+		c.Next()
+	}
+}
+
+func rateLimitSubject(c *gin.Context) string {
+	actor, ok := actorOf(c)
+	switch {
+	case ok && actor.APIKeyID != 0:
+		return "key:" + strconv.FormatUint(uint64(actor.APIKeyID), 10)
+	case ok && actor.UserID != 0:
+		return "user:" + strconv.FormatUint(uint64(actor.UserID), 10)
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
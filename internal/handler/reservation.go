@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+type ReservationHandler struct {
+	app *app.App
+}
+
+func NewReservationHandler(app *app.App) *ReservationHandler {
+	return &ReservationHandler{app: app}
+}
+
+type reservationResponse struct {
+	ID         uint           `json:"id"`
+	ShowtimeID uint           `json:"showtime_id"`
+	SeatID     uint           `json:"seat_id"`
+	UserID     *uint          `json:"user_id,omitempty"`
+	Guest      *guestResponse `json:"guest,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type guestResponse struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+func newReservationResponse(reservation *model.Reservation) reservationResponse {
+	response := reservationResponse{
+		ID:         reservation.ID,
+		ShowtimeID: reservation.ShowtimeID,
+		SeatID:     reservation.SeatID,
+		UserID:     reservation.UserID,
+		CreatedAt:  reservation.CreatedAt,
+	}
+	if reservation.IsGuest() {
+		response.Guest = &guestResponse{
+			Name:  reservation.Guest.Name,
+			Email: reservation.Guest.Email,
+			Phone: reservation.Guest.Phone,
+		}
+	}
+	return response
+}
+
+func newReservationsResponse(reservations []model.Reservation) []reservationResponse {
+	response := make([]reservationResponse, 0, len(reservations))
+	for i := range reservations {
+		response = append(response, newReservationResponse(&reservations[i]))
+	}
+	return response
+}
+
+// captchaRequest is the solution of a challenge from GET /captcha,
+// only needed when the service asks for it
+type captchaRequest struct {
+	ChallengeID string `json:"challenge_id"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+}
+
+func (r *captchaRequest) solution() service.CaptchaSolution {
+	if r == nil {
+		return service.CaptchaSolution{}
+	}
+	return service.CaptchaSolution{
+		ChallengeID: r.ChallengeID,
+		X:           r.X,
+		Y:           r.Y,
+	}
+}
+
+// user_id defaults to the signed-in user, booking for others needs the permission
+type reservationRequest struct {
+	UserID     uint            `json:"user_id"`
+	ShowtimeID uint            `json:"showtime_id"`
+	SeatID     uint            `json:"seat_id"`
+	Captcha    *captchaRequest `json:"captcha"`
+	// from the waiting room of the showtime, if it has one
+	AdmissionToken string `json:"admission_token"`
+}
+
+type guestReservationRequest struct {
+	Name       string          `json:"name"`
+	Email      string          `json:"email"`
+	Phone      string          `json:"phone"`
+	ShowtimeID uint            `json:"showtime_id"`
+	SeatID     uint            `json:"seat_id"`
+	Captcha    *captchaRequest `json:"captcha"`
+	// from the waiting room of the showtime, if it has one
+	AdmissionToken string `json:"admission_token"`
+}
+
+// the lookup code is the only way for the guest to find the reservation again
+type guestReservationResponse struct {
+	Reservation reservationResponse `json:"reservation"`
+	LookupCode  string              `json:"lookup_code"`
+}
+
+func (h *ReservationHandler) Create(c *gin.Context) {
+	var request reservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	userID := request.UserID
+	if userID == 0 {
+		userID = actor.UserID
+	}
+	reservation, err := h.app.ReservationService.Reserve(actor, userID, request.ShowtimeID, request.SeatID,
+		request.Captcha.solution(), request.AdmissionToken)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newReservationResponse(reservation))
+}
+
+// List returns the reservations of the signed-in user, or of the user of ?user_id=
+func (h *ReservationHandler) List(c *gin.Context) {
+	userID, ok := idQuery(c, "user_id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if userID == 0 {
+		userID = actor.UserID
+	}
+	reservations, err := h.app.ReservationService.GetReservationsByUserID(actor, userID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newReservationsResponse(reservations))
+}
+
+func (h *ReservationHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	reservation, err := h.app.ReservationService.GetReservationByID(actor, id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
+
+func (h *ReservationHandler) Cancel(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.ReservationService.CancelReservation(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Claim moves the guest reservations made with the verified email of the user to the account
+func (h *ReservationHandler) Claim(c *gin.Context) {
+	actor, _ := actorOf(c)
+	claimed, err := h.app.ReservationService.ClaimGuestReservations(actor, actor.UserID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"claimed": claimed})
+}
+
+func (h *ReservationHandler) CreateGuest(c *gin.Context) {
+	var request guestReservationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	guest := model.GuestContact{
+		Name:  request.Name,
+		Email: request.Email,
+		Phone: request.Phone,
+	}
+	reservation, code, err := h.app.ReservationService.ReserveAsGuest(actor, guest, request.ShowtimeID,
+		request.SeatID, request.Captcha.solution(), request.AdmissionToken)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, guestReservationResponse{
+		Reservation: newReservationResponse(reservation),
+		LookupCode:  code,
+	})
+}
+
+func (h *ReservationHandler) GetGuest(c *gin.Context) {
+	reservation, err := h.app.ReservationService.GetGuestReservation(c.Param("code"))
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newReservationResponse(reservation))
+}
+
+func (h *ReservationHandler) CancelGuest(c *gin.Context) {
+	if err := h.app.ReservationService.CancelGuestReservation(c.Param("code")); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
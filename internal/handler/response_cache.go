@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// cacheStatusHeader tells whether the response came from the cache, HIT or MISS
+const cacheStatusHeader = "X-Cache"
+
+// errNotCacheable is returned by the load of a response other than 200 OK
+var errNotCacheable = errors.New("response is not cacheable")
+
+// CacheResponse serves the successful responses of a catalogue read from the cache
+// for ttl, keyed by the URL, and answers a matching If-None-Match with 304 Not Modified.
+// The responses are built from the entities, a change of any of them invalidates them.
+// Concurrent misses of a URL wait for the request building the response instead of
+// building it too. With a ttl of 0 nothing is cached, the responses still get an ETag.
+// The responses must not depend on the caller
+func CacheResponse(catalog service.CatalogCache, ttl time.Duration, entities ...service.CatalogEntity) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if catalog == nil || ttl <= 0 {
+			if response, ok := buildResponse(c); ok {
+				writeCachedResponse(c, response)
+			}
+			return
+		}
+
+		built := false
+		response, err := catalog.GetOrLoad(c.Request.URL.RequestURI(), entities, ttl,
+			func() (service.CachedResponse, error) {
+				built = true
+				response, ok := buildResponse(c)
+				if !ok {
+					return response, errNotCacheable
+				}
+				return response, nil
+			})
+		switch {
+		case built && err != nil:
+			// the response was written as it is
+			return
+		case err != nil:
+			if !errors.Is(err, errNotCacheable) {
+				// the database still answers while the cache is down
+				_ = c.Error(err)
+			}
+			// the shared load failed, this request gets its own response
+			if response, ok := buildResponse(c); ok {
+				writeCachedResponse(c, response)
+			}
+			return
+		case built:
+			c.Header(cacheStatusHeader, "MISS")
+		default:
+			c.Header(cacheStatusHeader, "HIT")
+			c.Abort()
+		}
+		writeCachedResponse(c, response)
+	}
+}
+
+// buildResponse runs the handlers and returns their response if it is 200 OK,
+// any other response is written as it is
+func buildResponse(c *gin.Context) (service.CachedResponse, bool) {
+	writer := &bufferingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Next()
+	c.Writer = writer.ResponseWriter
+
+	if writer.Status() != http.StatusOK {
+		c.Writer.WriteHeaderNow()
+		_, _ = c.Writer.Write(writer.body.Bytes())
+		return service.CachedResponse{}, false
+	}
+	sum := sha256.Sum256(writer.body.Bytes())
+	return service.CachedResponse{
+		Status:      http.StatusOK,
+		ContentType: c.Writer.Header().Get("Content-Type"),
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Body:        writer.body.Bytes(),
+	}, true
+}
+
+func writeCachedResponse(c *gin.Context, response service.CachedResponse) {
+	c.Header("ETag", response.ETag)
+	// clients may keep the response, but have to revalidate it with If-None-Match
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), response.ETag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(response.Status, response.ContentType, response.Body)
+}
+
+// etagMatches compares the entity tags of If-None-Match weakly, as RFC 9110 asks
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferingWriter holds the body back, so the response can still be replaced
+// by a 304 once its ETag is known
+type bufferingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// WriteHeaderNow keeps the status to be written with the body
+func (w *bufferingWriter) WriteHeaderNow() {}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+type ShowtimeHandler struct {
+	app *app.App
+}
+
+func NewShowtimeHandler(app *app.App) *ShowtimeHandler {
+	return &ShowtimeHandler{app: app}
+}
+
+type showtimeResponse struct {
+	ID      uint      `json:"id"`
+	MovieID uint      `json:"movie_id"`
+	HallID  uint      `json:"hall_id"`
+	StartAt time.Time `json:"start_at"`
+}
+
+func newShowtimeResponse(showtime *model.Showtime) showtimeResponse {
+	return showtimeResponse{
+		ID:      showtime.ID,
+		MovieID: showtime.MovieID,
+		HallID:  showtime.HallID,
+		StartAt: showtime.StartAt,
+	}
+}
+
+// start_at is RFC 3339, e.g. 2026-05-01T20:15:00+02:00
+type showtimeRequest struct {
+	MovieID uint      `json:"movie_id"`
+	HallID  uint      `json:"hall_id"`
+	StartAt time.Time `json:"start_at"`
+}
+
+// List returns all showtimes, or those filtered by one of
+// ?movie_id=, ?hall_id= and ?cinema_id=
+func (h *ShowtimeHandler) List(c *gin.Context) {
+	movieID, ok := idQuery(c, "movie_id")
+	if !ok {
+		return
+	}
+	hallID, ok := idQuery(c, "hall_id")
+	if !ok {
+		return
+	}
+	cinemaID, ok := idQuery(c, "cinema_id")
+	if !ok {
+		return
+	}
+
+	var showtimes []model.Showtime
+	var err error
+	switch {
+	case movieID != 0 && hallID == 0 && cinemaID == 0:
+		showtimes, err = h.app.ShowtimeService.GetShowtimesByMovieID(movieID)
+	case hallID != 0 && movieID == 0 && cinemaID == 0:
+		showtimes, err = h.app.ShowtimeService.GetShowtimesByHallID(hallID)
+	case cinemaID != 0 && movieID == 0 && hallID == 0:
+		showtimes, err = h.app.ShowtimeService.GetShowtimesByCinemaID(cinemaID)
+	case movieID == 0 && hallID == 0 && cinemaID == 0:
+		showtimes, err = h.app.ShowtimeService.GetAllShowtimes()
+	default:
+		httperror.InvalidRequest(c, "only one of movie_id, hall_id and cinema_id can be given")
+		return
+	}
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]showtimeResponse, 0, len(showtimes))
+	for i := range showtimes {
+		response = append(response, newShowtimeResponse(&showtimes[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ShowtimeHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	showtime, err := h.app.ShowtimeService.GetShowtimeByID(id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newShowtimeResponse(showtime))
+}
+
+func (h *ShowtimeHandler) Create(c *gin.Context) {
+	var request showtimeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	showtime, err := h.app.ShowtimeService.CreateShowtime(actor, request.MovieID, request.StartAt, request.HallID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newShowtimeResponse(showtime))
+}
+
+type seatStateResponse struct {
+	SeatID uint               `json:"seat_id"`
+	Status service.SeatStatus `json:"status"`
+}
+
+type seatAvailabilityResponse struct {
+	ShowtimeID uint                `json:"showtime_id"`
+	Seats      []seatStateResponse `json:"seats"`
+}
+
+func newSeatAvailabilityResponse(showtimeID uint, seats []service.SeatState) seatAvailabilityResponse {
+	response := seatAvailabilityResponse{
+		ShowtimeID: showtimeID,
+		Seats:      make([]seatStateResponse, 0, len(seats)),
+	}
+	for _, seat := range seats {
+		response.Seats = append(response.Seats, seatStateResponse{SeatID: seat.SeatID, Status: seat.Status})
+	}
+	return response
+}
+
+// seatEventsKeepAlive is how often an idle event stream writes a comment,
+// so proxies don't close the connection
+const seatEventsKeepAlive = 15 * time.Second
+
+// Seats returns the status of every seat of the showtime
+func (h *ShowtimeHandler) Seats(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	seats, err := h.app.ReservationService.GetSeatAvailability(id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newSeatAvailabilityResponse(id, seats))
+}
+
+// SeatEvents streams server-sent "seats" events, the first one has every seat of the showtime,
+// the next ones the seats whose status changed
+func (h *ShowtimeHandler) SeatEvents(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// the watcher hands the changes over, so only this goroutine writes the response
+	changes := make(chan []service.SeatState)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- h.app.ReservationService.WatchSeatAvailability(ctx, id,
+			func(seats []service.SeatState) error {
+				select {
+				case changes <- seats:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+	}()
+
+	// nothing is written until the first event, so a missing showtime is still a JSON error
+	var seats []service.SeatState
+	select {
+	case seats = <-changes:
+	case err := <-watchErr:
+		if err != nil {
+			httperror.Write(c, err)
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	keepAlive := time.NewTicker(seatEventsKeepAlive)
+	defer keepAlive.Stop()
+	c.SSEvent("seats", newSeatAvailabilityResponse(id, seats))
+	c.Writer.Flush()
+	for {
+		select {
+		case seats = <-changes:
+			c.SSEvent("seats", newSeatAvailabilityResponse(id, seats))
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-watchErr:
+			// the stream can't go on without the availability, the client reconnects
+			return
+		case <-ctx.Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
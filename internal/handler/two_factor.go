@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// TwoFactorHandler lets the signed-in user set up TOTP. Sessions of roles requiring
+// two factors that were started without a code only have the permissions of a user,
+// which are enough to enroll
+type TwoFactorHandler struct {
+	app *app.App
+}
+
+func NewTwoFactorHandler(app *app.App) *TwoFactorHandler {
+	return &TwoFactorHandler{app: app}
+}
+
+type twoFactorStatusResponse struct {
+	Enabled bool `json:"enabled"`
+	// whether the role of the user needs two factors for its permissions
+	Required bool `json:"required"`
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// recovery codes are shown once, each signs in once instead of a TOTP code
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// twoFactor returns the service, it responds 404 and returns false
+// when two factors are not configured
+func (h *TwoFactorHandler) twoFactor(c *gin.Context) (service.TwoFactorService, bool) {
+	if h.app.TwoFactorService == nil {
+		httperror.Write(c, service.ErrNotFound)
+		return nil, false
+	}
+	return h.app.TwoFactorService, true
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	twoFactor, ok := h.twoFactor(c)
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	user, err := h.app.UserService.GetUserByID(actor.UserID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	enabled, err := twoFactor.IsEnabled(actor.UserID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, twoFactorStatusResponse{Enabled: enabled, Required: twoFactor.Required(user.Role)})
+}
+
+// Enroll creates the secret for the authenticator app, it is enforced once confirmed
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	twoFactor, ok := h.twoFactor(c)
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	enrollment, err := twoFactor.BeginEnrollment(actor, actor.UserID)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// Confirm enables two factors with a code of the authenticator app,
+// the next login needs a code to get the permissions of a role requiring them
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	twoFactor, ok := h.twoFactor(c)
+	if !ok {
+		return
+	}
+	var request twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	codes, err := twoFactor.ConfirmEnrollment(actor, actor.UserID, request.Code)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the old ones stop working
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	twoFactor, ok := h.twoFactor(c)
+	if !ok {
+		return
+	}
+	var request twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	codes, err := twoFactor.RegenerateRecoveryCodes(actor, actor.UserID, request.Code)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	twoFactor, ok := h.twoFactor(c)
+	if !ok {
+		return
+	}
+	var request twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := twoFactor.Disable(actor, actor.UserID, request.Code); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Reset removes the two factors of a user who lost the authenticator and the recovery codes
+func (h *TwoFactorHandler) Reset(c *gin.Context) {
+	twoFactor, ok := h.twoFactor(c)
+	if !ok {
+		return
+	}
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := twoFactor.Reset(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+// UserHandler manages accounts, users manage their own,
+// the others need the users:manage permission
+type UserHandler struct {
+	app *app.App
+}
+
+func NewUserHandler(app *app.App) *UserHandler {
+	return &UserHandler{app: app}
+}
+
+type profileRequest struct {
+	Email       string `json:"email"`
+	DisplayName string `json:"display_name"`
+	Phone       string `json:"phone"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type roleRequest struct {
+	Role model.UserRole `json:"role"`
+}
+
+type statusRequest struct {
+	Status model.UserStatus `json:"status"`
+}
+
+// UpdateProfile replaces the profile, a changed email has to be verified again
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request profileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	user, err := h.app.UserService.UpdateProfile(actor, id, model.UserProfile{
+		Email:       request.Email,
+		DisplayName: request.DisplayName,
+		Phone:       request.Phone,
+	})
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// ChangePassword needs the current password and ends every session of the user
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request changePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.UserService.ChangePassword(actor, id, request.CurrentPassword, request.NewPassword); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Delete cancels the upcoming reservations of the user and anonymizes the account
+func (h *UserHandler) Delete(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.UserService.DeleteUser(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request roleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.UserService.AssignRole(actor, id, request.Role); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetStatus suspends or reinstates the user
+func (h *UserHandler) SetStatus(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	var request statusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.UserService.SetUserStatus(actor, id, request.Status); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Unlock lifts the lockout after repeated failed logins
+func (h *UserHandler) Unlock(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.UserService.UnlockAccount(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+type WaitingRoomHandler struct {
+	app *app.App
+}
+
+func NewWaitingRoomHandler(app *app.App) *WaitingRoomHandler {
+	return &WaitingRoomHandler{app: app}
+}
+
+// a room has one of showtime_id and movie_id
+type waitingRoomResponse struct {
+	ID               uint      `json:"id"`
+	ShowtimeID       *uint     `json:"showtime_id,omitempty"`
+	MovieID          *uint     `json:"movie_id,omitempty"`
+	AdmitPerMinute   int       `json:"admit_per_minute"`
+	AdmissionMinutes int       `json:"admission_minutes"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func newWaitingRoomResponse(room *model.WaitingRoom) waitingRoomResponse {
+	return waitingRoomResponse{
+		ID:               room.ID,
+		ShowtimeID:       room.ShowtimeID,
+		MovieID:          room.MovieID,
+		AdmitPerMinute:   room.AdmitPerMinute,
+		AdmissionMinutes: room.AdmissionMinutes,
+		CreatedAt:        room.CreatedAt,
+	}
+}
+
+// a room is for one of showtime_id and movie_id, admission_minutes defaults to 10
+type waitingRoomRequest struct {
+	ShowtimeID       *uint `json:"showtime_id"`
+	MovieID          *uint `json:"movie_id"`
+	AdmitPerMinute   int   `json:"admit_per_minute"`
+	AdmissionMinutes int   `json:"admission_minutes"`
+}
+
+// admission_token and admission_expires_at are only set once admitted
+type queueTicketResponse struct {
+	WaitingRoomID      uint       `json:"waiting_room_id"`
+	Ticket             string     `json:"ticket"`
+	Admitted           bool       `json:"admitted"`
+	Position           int        `json:"position"`
+	QueueLength        int        `json:"queue_length"`
+	AdmissionToken     string     `json:"admission_token,omitempty"`
+	AdmissionExpiresAt *time.Time `json:"admission_expires_at,omitempty"`
+}
+
+func newQueueTicketResponse(ticket *service.QueueTicket) queueTicketResponse {
+	response := queueTicketResponse{
+		WaitingRoomID: ticket.RoomID,
+		Ticket:        ticket.Ticket,
+		Admitted:      ticket.AdmissionToken != "",
+		Position:      ticket.Position,
+		QueueLength:   ticket.QueueLength,
+	}
+	if response.Admitted {
+		response.AdmissionToken = ticket.AdmissionToken
+		response.AdmissionExpiresAt = &ticket.AdmissionExpiresAt
+	}
+	return response
+}
+
+func (h *WaitingRoomHandler) List(c *gin.Context) {
+	rooms, err := h.app.WaitingRoomService.GetWaitingRooms()
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	response := make([]waitingRoomResponse, 0, len(rooms))
+	for i := range rooms {
+		response = append(response, newWaitingRoomResponse(&rooms[i]))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WaitingRoomHandler) Get(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	room, err := h.app.WaitingRoomService.GetWaitingRoomByID(id)
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newWaitingRoomResponse(room))
+}
+
+func (h *WaitingRoomHandler) Create(c *gin.Context) {
+	var request waitingRoomRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		invalidBody(c, err)
+		return
+	}
+	actor, _ := actorOf(c)
+	room := &model.WaitingRoom{
+		ShowtimeID:       request.ShowtimeID,
+		MovieID:          request.MovieID,
+		AdmitPerMinute:   request.AdmitPerMinute,
+		AdmissionMinutes: request.AdmissionMinutes,
+	}
+	if err := h.app.WaitingRoomService.OpenWaitingRoom(actor, room); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newWaitingRoomResponse(room))
+}
+
+func (h *WaitingRoomHandler) Delete(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	if err := h.app.WaitingRoomService.CloseWaitingRoom(actor, id); err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Join queues the caller, it may be admitted right away while the room is not full
+func (h *WaitingRoomHandler) Join(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	ticket, err := h.app.WaitingRoomService.Join(actor, id, c.ClientIP())
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, newQueueTicketResponse(ticket))
+}
+
+// Poll returns the place of the ticket, a ticket that isn't polled
+// for a minute loses its place
+func (h *WaitingRoomHandler) Poll(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	actor, _ := actorOf(c)
+	ticket, err := h.app.WaitingRoomService.Poll(actor, id, c.Param("ticket"))
+	if err != nil {
+		httperror.Write(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newQueueTicketResponse(ticket))
+}
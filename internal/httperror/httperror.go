@@ -0,0 +1,140 @@
+// Package httperror is where errors become HTTP responses,
+// every failed request is answered with the same envelope:
+//
+//	{"error": {"code": "seat_blocked", "message": "the seat is blocked", "details": {"seat_id": 7}}}
+//
+// Clients branch on the code, which is stable, and show the message.
+// Errors without a code are internal and their text is not sent
+package httperror
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+type Body struct {
+	Error Payload `json:"error"`
+}
+
+type Payload struct {
+	Code    service.Code   `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// statuses maps the codes to the status of the response,
+// codes that are not listed are internal server errors
+var statuses = map[service.Code]int{
+	service.CodeInvalidInput:         http.StatusBadRequest,
+	service.CodeInvalidToken:         http.StatusBadRequest,
+	service.CodeCaptchaRequired:      http.StatusBadRequest,
+	service.CodeCaptchaInvalid:       http.StatusBadRequest,
+	service.CodeTwoFactorNotEnabled:  http.StatusBadRequest,
+	auth.CodeInvalidState:            http.StatusBadRequest,
+	service.CodeInvalidCredential:    http.StatusUnauthorized,
+	service.CodeTwoFactorRequired:    http.StatusUnauthorized,
+	service.CodeInvalidTwoFactorCode: http.StatusUnauthorized,
+	auth.CodeInvalidSessionToken:     http.StatusUnauthorized,
+	auth.CodeSessionRevoked:          http.StatusUnauthorized,
+	service.CodeForbidden:            http.StatusForbidden,
+	service.CodeUserNotVerified:      http.StatusForbidden,
+	service.CodeUserSuspended:        http.StatusForbidden,
+	service.CodeAdmissionRequired:    http.StatusForbidden,
+	service.CodeAdmissionInvalid:     http.StatusForbidden,
+	service.CodeNotFound:             http.StatusNotFound,
+	service.CodeShowtimeNotFound:     http.StatusNotFound,
+	service.CodeSeatNotFound:         http.StatusNotFound,
+	auth.CodeUnknownProvider:         http.StatusNotFound,
+	service.CodeAlreadyExists:        http.StatusConflict,
+	service.CodeVersionConflict:      http.StatusConflict,
+	service.CodeRelatedResources:     http.StatusConflict,
+	service.CodeAlreadyReserved:      http.StatusConflict,
+	service.CodeAlreadyVerified:      http.StatusConflict,
+	service.CodeNoTicketsAvailable:   http.StatusConflict,
+	service.CodeSeatBlocked:          http.StatusConflict,
+	service.CodeSeatTaken:            http.StatusConflict,
+	service.CodeHallUnderMaintenance: http.StatusConflict,
+	service.CodeIdempotencyPending:   http.StatusConflict,
+	service.CodeIdempotencyKeyReused: http.StatusUnprocessableEntity,
+	service.CodeAccountLocked:        http.StatusTooManyRequests,
+	service.CodeTooManyAttempts:      http.StatusTooManyRequests,
+	service.CodeRateLimited:          http.StatusTooManyRequests,
+}
+
+// Status returns the HTTP status of the code
+func Status(code service.Code) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Codes returns every code with its status, for the API documentation
+func Codes() map[service.Code]int {
+	codes := make(map[service.Code]int, len(statuses)+1)
+	for code, status := range statuses {
+		codes[code] = status
+	}
+	codes[service.CodeInternal] = http.StatusInternalServerError
+	return codes
+}
+
+// PayloadOf translates err: a *service.ValidationError lists its fields in the details,
+// a *service.RetryAfterError adds retry_after seconds,
+// and any other *service.Error is sent as it is
+func PayloadOf(err error) Payload {
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return Payload{
+			Code:    service.CodeInvalidInput,
+			Message: service.ErrInvalidInput.Message,
+			Details: map[string]any{"fields": validationErr.Fields},
+		}
+	}
+	var serviceErr *service.Error
+	if !errors.As(err, &serviceErr) {
+		return Payload{
+			Code:    service.CodeInternal,
+			Message: "internal server error",
+		}
+	}
+	var retryErr *service.RetryAfterError
+	if errors.As(err, &retryErr) {
+		serviceErr = serviceErr.WithDetail("retry_after", retryAfterSeconds(retryErr))
+	}
+	return Payload{
+		Code:    serviceErr.Code,
+		Message: serviceErr.Message,
+		Details: serviceErr.Details,
+	}
+}
+
+// Write aborts the request with the envelope of err
+// and adds err to the context for the ErrorLogger
+func Write(c *gin.Context, err error) {
+	_ = c.Error(err)
+
+	var retryErr *service.RetryAfterError
+	if errors.As(err, &retryErr) {
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(retryErr)))
+	}
+	payload := PayloadOf(err)
+	c.AbortWithStatusJSON(Status(payload.Code), Body{Error: payload})
+}
+
+// InvalidRequest is Write for a body or parameter that can't be decoded,
+// message is sent to the client
+func InvalidRequest(c *gin.Context, message string) {
+	Write(c, service.NewError(service.CodeInvalidInput, message))
+}
+
+func retryAfterSeconds(err *service.RetryAfterError) int {
+	return max(int(math.Ceil(err.RetryAfter.Seconds())), 1)
+}
@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages, Send returns once the message is accepted for delivery
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var ErrInvalidMessage = errors.New("invalid mail message")
+
+func (m Message) validate() error {
+	if strings.TrimSpace(m.To) == "" {
+		return ErrInvalidMessage
+	}
+	// header injection
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidMessage
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package mail
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MemoryOutbox keeps the sent messages, for tests and local development
+type MemoryOutbox struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+var _ Mailer = (*MemoryOutbox)(nil)
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (o *MemoryOutbox) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (o *MemoryOutbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Message(nil), o.messages...)
+}
+
+// Last returns the latest message sent to the address
+func (o *MemoryOutbox) Last(to string) (Message, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if o.messages[i].To == to {
+			return o.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+type fileOutbox struct {
+	dir string
+	mu  sync.Mutex
+	seq int
+}
+
+var _ Mailer = (*fileOutbox)(nil)
+
+// NewFileOutbox writes each message as a JSON file in dir instead of sending it
+func NewFileOutbox(dir string) (*fileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileOutbox{
+		dir: dir,
+	}, nil
+}
+
+func (o *fileOutbox) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	o.seq++
+	name := fmt.Sprintf("%s-%04d.json", time.Now().UTC().Format("20060102T150405.000000"), o.seq)
+	o.mu.Unlock()
+	// messages may hold tokens, keep them private to the server user
+	return os.WriteFile(filepath.Join(o.dir, name), data, 0o600)
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+)
+
+// ZapLogger logs every request at INFO level once it is handled
+func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		query := c.Request.URL.RawQuery
+
+		c.Next()
+
+		logger.Info("request",
+			zap.String("method", c.Request.Method),
+			zap.String("path", path),
+			zap.String("query", query),
+			zap.Int("status", c.Writer.Status()),
+			zap.Int("size", c.Writer.Size()),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()),
+			zap.Duration("latency", time.Since(start)),
+		)
+	}
+}
+
+// ErrorLogger logs the errors handlers added with ctx.Error(err),
+// at ERROR level for server errors and at WARN level otherwise
+func ErrorLogger(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		level := zapcore.WarnLevel
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = zapcore.ErrorLevel
+		}
+		for _, err := range c.Errors {
+			logger.Log(level, "request failed",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Int("status", c.Writer.Status()),
+				zap.Error(err.Err),
+			)
+		}
+	}
+}
+
+// Recovery turns a panic into a 500 response and logs it with the stack
+func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logger.Error("panic while handling request",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Any("panic", recovered),
+			zap.Stack("stack"),
+		)
+		httperror.Write(c, fmt.Errorf("panic: %v", recovered))
+	})
+}
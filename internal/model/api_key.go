@@ -0,0 +1,66 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// APIKey is a machine credential for partner integrations and kiosks.
+// The key is shown once at creation, only Prefix, which identifies it in lists and logs,
+// and the SHA-256 hash of the whole key are stored.
+// RateLimit is the number of requests allowed per minute
+type APIKey struct {
+	ID          uint           `gorm:"primaryKey"`
+	Name        string         `gorm:"size:100;not null"`
+	Prefix      string         `gorm:"size:16;not null;uniqueIndex"`
+	SecretHash  string         `gorm:"size:64;not null"`
+	Scopes      PermissionList `gorm:"type:jsonb;not null"`
+	RateLimit   int            `gorm:"not null;check:rate_limit > 0"`
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+	CreatedByID uint `gorm:"not null;index"`
+	CreatedAt   time.Time
+
+	CreatedBy User `gorm:"foreignKey:CreatedByID"`
+}
+
+// IsUsable reports whether the key is neither revoked nor expired at the time
+func (k *APIKey) IsUsable(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
+
+// PermissionList is stored as a JSON array
+type PermissionList []Permission
+
+func (l PermissionList) Has(permission Permission) bool {
+	return slices.Contains(l, permission)
+}
+
+func (l PermissionList) Value() (driver.Value, error) {
+	if l == nil {
+		l = PermissionList{}
+	}
+	data, err := json.Marshal([]Permission(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *PermissionList) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]Permission)(l))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]Permission)(l))
+	default:
+		return fmt.Errorf("unsupported type %T for PermissionList", value)
+	}
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// HallLayout describes the seating plan of a hall row by row.
+// Bookable seats are numbered 1..SeatCount() in row-major order,
+// which is the SeatID used by Reservation.
+type HallLayout struct {
+	Rows []LayoutRow `json:"rows"`
+}
+
+type LayoutRow struct {
+	Label  string       `json:"label"`
+	Curved bool         `json:"curved,omitempty"`
+	Cells  []LayoutCell `json:"cells"`
+}
+
+// LayoutCell is one position in a row, either a seat or an empty space.
+type LayoutCell struct {
+	Kind     CellKind     `json:"kind"`
+	Category SeatCategory `json:"category,omitempty"`
+}
+
+type CellKind string
+
+const (
+	CellSeat  CellKind = "seat"
+	CellAisle CellKind = "aisle"
+	CellGap   CellKind = "gap"
+)
+
+type SeatCategory string
+
+const (
+	SeatStandard   SeatCategory = "standard"
+	SeatPremium    SeatCategory = "premium"
+	SeatWheelchair SeatCategory = "wheelchair"
+	SeatCompanion  SeatCategory = "companion"
+)
+
+// LayoutSeat is a bookable seat resolved from a HallLayout.
+type LayoutSeat struct {
+	ID       uint         `json:"id"`
+	Row      string       `json:"row"`
+	Number   int          `json:"number"`
+	Category SeatCategory `json:"category"`
+}
+
+// RectangularLayout builds a layout of rows x cols standard seats,
+// rows are labelled A, B, ..., Z, AA, AB, ...
+func RectangularLayout(rows, cols int) HallLayout {
+	layout := HallLayout{Rows: make([]LayoutRow, 0, rows)}
+	for i := range rows {
+		row := LayoutRow{Label: RowLabel(i), Cells: make([]LayoutCell, 0, cols)}
+		for range cols {
+			row.Cells = append(row.Cells, LayoutCell{Kind: CellSeat, Category: SeatStandard})
+		}
+		layout.Rows = append(layout.Rows, row)
+	}
+	return layout
+}
+
+// RowLabel returns the spreadsheet-style label of the zero-based row index.
+func RowLabel(index int) string {
+	label := ""
+	for index >= 0 {
+		label = string(rune('A'+index%26)) + label
+		index = index/26 - 1
+	}
+	return label
+}
+
+// Value stores the layout as JSON.
+func (l HallLayout) Value() (driver.Value, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *HallLayout) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*l = HallLayout{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("unsupported type %T for HallLayout", value)
+	}
+}
+
+func (l HallLayout) IsEmpty() bool {
+	return len(l.Rows) == 0
+}
+
+// SeatCount returns the number of bookable seats, wheelchair spaces included.
+func (l HallLayout) SeatCount() int {
+	count := 0
+	for _, row := range l.Rows {
+		count += row.SeatCount()
+	}
+	return count
+}
+
+// Width returns the number of cells of the widest row.
+func (l HallLayout) Width() int {
+	width := 0
+	for _, row := range l.Rows {
+		width = max(width, len(row.Cells))
+	}
+	return width
+}
+
+// Seats lists the bookable seats in SeatID order.
+func (l HallLayout) Seats() []LayoutSeat {
+	seats := make([]LayoutSeat, 0, l.SeatCount())
+	for _, row := range l.Rows {
+		number := 0
+		for _, cell := range row.Cells {
+			if cell.Kind != CellSeat {
+				continue
+			}
+			number++
+			seats = append(seats, LayoutSeat{
+				ID:       uint(len(seats) + 1),
+				Row:      row.Label,
+				Number:   number,
+				Category: cell.Category,
+			})
+		}
+	}
+	return seats
+}
+
+// Seat returns the bookable seat with the given SeatID.
+func (l HallLayout) Seat(id uint) (LayoutSeat, bool) {
+	seats := l.Seats()
+	if id == 0 || int(id) > len(seats) {
+		return LayoutSeat{}, false
+	}
+	return seats[id-1], true
+}
+
+func (r LayoutRow) SeatCount() int {
+	count := 0
+	for _, cell := range r.Cells {
+		if cell.Kind == CellSeat {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeatsAreNumberedAcrossAislesAndGaps(t *testing.T) {
+	layout := HallLayout{Rows: []LayoutRow{
+		{Label: "A", Cells: []LayoutCell{
+			{Kind: CellSeat, Category: SeatWheelchair},
+			{Kind: CellSeat, Category: SeatCompanion},
+			{Kind: CellAisle},
+			{Kind: CellSeat, Category: SeatStandard},
+		}},
+		{Label: "B", Cells: []LayoutCell{
+			{Kind: CellGap},
+			{Kind: CellSeat, Category: SeatPremium},
+		}},
+	}}
+
+	assert.Equal(t, 4, layout.SeatCount())
+	assert.Equal(t, 4, layout.Width())
+	assert.Equal(t, []LayoutSeat{
+		{ID: 1, Row: "A", Number: 1, Category: SeatWheelchair},
+		{ID: 2, Row: "A", Number: 2, Category: SeatCompanion},
+		{ID: 3, Row: "A", Number: 3, Category: SeatStandard},
+		{ID: 4, Row: "B", Number: 1, Category: SeatPremium},
+	}, layout.Seats())
+
+	seat, ok := layout.Seat(4)
+	require.True(t, ok)
+	assert.Equal(t, "B", seat.Row)
+	_, ok = layout.Seat(0)
+	assert.False(t, ok)
+	_, ok = layout.Seat(5)
+	assert.False(t, ok)
+}
+
+func TestRectangularLayoutLabelsRowsLikeSpreadsheetColumns(t *testing.T) {
+	layout := RectangularLayout(28, 3)
+	assert.Equal(t, 84, layout.SeatCount())
+	assert.Equal(t, "A", layout.Rows[0].Label)
+	assert.Equal(t, "Z", layout.Rows[25].Label)
+	assert.Equal(t, "AA", layout.Rows[26].Label)
+	assert.Equal(t, "AB", layout.Rows[27].Label)
+	assert.Equal(t, "BA", RowLabel(52))
+}
+
+func TestHallLayoutRoundTripsThroughTheDatabaseValue(t *testing.T) {
+	layout := RectangularLayout(2, 2)
+	layout.Rows[1].Curved = true
+	value, err := layout.Value()
+	require.NoError(t, err)
+
+	var scanned HallLayout
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, layout, scanned)
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsEmpty())
+	assert.Error(t, scanned.Scan(42))
+
+	// halls stored before layouts existed get a rectangular one
+	hall := Hall{Rows: 3, Cols: 4}
+	assert.Equal(t, 12, hall.SeatLayout().SeatCount())
+}
@@ -2,26 +2,85 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// A deleted user is anonymized and soft deleted,
+// so the reservations already used keep pointing to a row
 type User struct {
-	ID             uint     `gorm:"primaryKey"`
-	Name           string   `gorm:"size:64;not null;uniqueIndex"`
-	HashedPassword string   `gorm:"not null"`
-	Role           UserRole `gorm:"type:varchar(16);not null"`
+	ID              uint       `gorm:"primaryKey"`
+	Name            string     `gorm:"size:64;not null;uniqueIndex"`
+	HashedPassword  string     `gorm:"not null"`
+	Role            UserRole   `gorm:"type:varchar(16);not null"`
+	Email           string     `gorm:"size:255;uniqueIndex:idx_users_email,where:email <> ''"`
+	DisplayName     string     `gorm:"size:100"`
+	Phone           string     `gorm:"size:32"`
+	Status          UserStatus `gorm:"type:varchar(16);not null;default:active"`
+	EmailVerifiedAt *time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// UserProfile holds the fields users edit themselves
+type UserProfile struct {
+	Email       string
+	DisplayName string
+	Phone       string
+}
+
+// UserStatus gates what an account may do,
+// only active users can reserve and suspended users cannot log in,
+// accounts created before verification existed default to active
+type UserStatus string
+
+const (
+	UserUnverified UserStatus = "unverified"
+	UserActive     UserStatus = "active"
+	UserSuspended  UserStatus = "suspended"
+)
+
+func (s UserStatus) IsValid() bool {
+	switch s {
+	case UserUnverified, UserActive, UserSuspended:
+		return true
+	}
+	return false
 }
 
 type UserRole string
 
 const (
-	RoleUser  UserRole = "user"
-	RoleAdmin UserRole = "admin"
+	RoleUser       UserRole = "user"
+	RoleAdmin      UserRole = "admin"
+	RoleBoxOffice  UserRole = "box_office"
+	RoleProgrammer UserRole = "programmer"
+	RoleFinance    UserRole = "finance"
 )
 
 type Movie struct {
 	ID          uint   `gorm:"primaryKey"`
 	Title       string `gorm:"size:100;not null;uniqueIndex"`
 	Description string `gorm:"type:text"`
+	// 0 when it isn't known
+	RuntimeMinutes uint `gorm:"not null;default:0"`
+	Version        uint `gorm:"not null;default:1"`
+}
+
+// DefaultRuntime is what a showtime of a movie without a runtime is assumed to last
+const DefaultRuntime = 3 * time.Hour
+
+func (m *Movie) Runtime() time.Duration {
+	if m.RuntimeMinutes == 0 {
+		return DefaultRuntime
+	}
+	return time.Duration(m.RuntimeMinutes) * time.Minute
+}
+
+// MoviePatch is a partial update of a Movie, nil fields are left untouched.
+type MoviePatch struct {
+	Title          *string
+	Description    *string
+	RuntimeMinutes *uint
 }
 
 type Showtime struct {
@@ -34,20 +93,143 @@ type Showtime struct {
 	Hall  Hall  `gorm:"foreignKey:HallID"`
 }
 
+// EndAt is when the showtime frees the hall, Movie has to be loaded
+func (s *Showtime) EndAt() time.Time {
+	return s.StartAt.Add(s.Movie.Runtime())
+}
+
+// Reservation belongs either to a user or, for guest checkout, to the Guest contact,
+// UserID is nil for guest reservations until a registered user claims them.
+// APIKeyID is the key of the partner or kiosk that made the reservation, if any
 type Reservation struct {
-	ID         uint `gorm:"primaryKey"`
-	ShowtimeID uint `gorm:"not null;index;uniqueIndex:idx_unique_ticket"`
-	SeatID     uint `gorm:"not null;index;uniqueIndex:idx_unique_ticket"`
-	UserID     uint `gorm:"not null;index"`
+	ID         uint         `gorm:"primaryKey"`
+	ShowtimeID uint         `gorm:"not null;index;uniqueIndex:idx_unique_ticket"`
+	SeatID     uint         `gorm:"not null;index;uniqueIndex:idx_unique_ticket"`
+	UserID     *uint        `gorm:"index"`
+	Guest      GuestContact `gorm:"embedded;embeddedPrefix:guest_"`
+	APIKeyID   *uint        `gorm:"index"`
+	CreatedAt  time.Time
 
 	Showtime Showtime `gorm:"foreignKey:ShowtimeID"`
 	User     User     `gorm:"foreignKey:UserID"`
+	APIKey   APIKey   `gorm:"foreignKey:APIKeyID"`
+}
+
+// OwnerID is the user the reservation belongs to, 0 for a guest reservation
+func (r *Reservation) OwnerID() uint {
+	if r.UserID == nil {
+		return 0
+	}
+	return *r.UserID
+}
+
+func (r *Reservation) IsGuest() bool {
+	return r.UserID == nil
+}
+
+// GuestContact is how a customer without an account is reached,
+// at least one of Email and Phone is set
+type GuestContact struct {
+	Name  string `gorm:"size:100"`
+	Email string `gorm:"size:255;index"`
+	Phone string `gorm:"size:32"`
 }
 
+// Cinema is a venue owning halls, Timezone is an IANA name like "Europe/Berlin"
+type Cinema struct {
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"size:100;not null;uniqueIndex"`
+	Address      string `gorm:"size:255"`
+	Timezone     string `gorm:"size:64;not null"`
+	ContactEmail string `gorm:"size:255"`
+	ContactPhone string `gorm:"size:32"`
+}
+
+// SeatCount, Rows and Cols are derived from Layout, see ApplyLayout.
+// Name is unique within the cinema
 type Hall struct {
-	ID        uint   `gorm:"primaryKey"`
-	Name      string `gorm:"size:64;not null;uniqueIndex"`
-	SeatCount int    `gorm:"not null"`
-	Rows      int    `gorm:"not null;check:rows > 0"`
-	Cols      int    `gorm:"not null;check:cols > 0"`
+	ID        uint       `gorm:"primaryKey"`
+	CinemaID  uint       `gorm:"not null;uniqueIndex:idx_cinema_hall_name"`
+	Name      string     `gorm:"size:64;not null;uniqueIndex:idx_cinema_hall_name"`
+	SeatCount int        `gorm:"not null"`
+	Rows      int        `gorm:"not null;check:rows > 0"`
+	Cols      int        `gorm:"not null;check:cols > 0"`
+	Layout    HallLayout `gorm:"type:jsonb"`
+	Version   uint       `gorm:"not null;default:1"`
+}
+
+// ApplyLayout sets SeatCount, Rows and Cols from the layout.
+func (h *Hall) ApplyLayout() {
+	h.SeatCount = h.Layout.SeatCount()
+	h.Rows = len(h.Layout.Rows)
+	h.Cols = h.Layout.Width()
+}
+
+// SeatLayout returns Layout, or a Rows x Cols rectangular layout
+// for halls stored before layouts existed.
+func (h *Hall) SeatLayout() HallLayout {
+	if h.Layout.IsEmpty() {
+		return RectangularLayout(h.Rows, h.Cols)
+	}
+	return h.Layout
+}
+
+// HallPatch is a partial update of a Hall, nil fields are left untouched.
+// Patching Layout also rewrites the derived SeatCount, Rows and Cols.
+type HallPatch struct {
+	Name   *string
+	Layout *HallLayout
+}
+
+// SeatBlock makes a seat unavailable without deleting anything,
+// either for one showtime (ShowtimeID set)
+// or for every showtime of the hall starting within [StartAt, EndAt)
+type SeatBlock struct {
+	ID         uint  `gorm:"primaryKey"`
+	HallID     uint  `gorm:"not null;index"`
+	SeatID     uint  `gorm:"not null"`
+	ShowtimeID *uint `gorm:"index"`
+	StartAt    *time.Time
+	EndAt      *time.Time
+	Reason     string `gorm:"size:255"`
+
+	Hall Hall `gorm:"foreignKey:HallID"`
+}
+
+// HallMaintenance makes the whole hall unavailable within [StartAt, EndAt)
+type HallMaintenance struct {
+	ID      uint      `gorm:"primaryKey"`
+	HallID  uint      `gorm:"not null;index"`
+	StartAt time.Time `gorm:"not null"`
+	EndAt   time.Time `gorm:"not null;check:end_at > start_at"`
+	Reason  string    `gorm:"size:255"`
+
+	Hall Hall `gorm:"foreignKey:HallID"`
+}
+
+// WaitingRoom queues the customers booking a high-demand showtime, or any showtime of a movie,
+// and lets AdmitPerMinute of them book per minute, the room is open until it is deleted
+type WaitingRoom struct {
+	ID         uint  `gorm:"primaryKey"`
+	ShowtimeID *uint `gorm:"index"`
+	MovieID    *uint `gorm:"index"`
+	// exactly one of ShowtimeID and MovieID is set
+	AdmitPerMinute int `gorm:"not null;check:admit_per_minute > 0"`
+	// how long an admitted customer may book
+	AdmissionMinutes int `gorm:"not null;check:admission_minutes > 0"`
+	CreatedAt        time.Time
+
+	Showtime *Showtime `gorm:"foreignKey:ShowtimeID"`
+	Movie    *Movie    `gorm:"foreignKey:MovieID"`
+}
+
+// AdmissionUse records that an admission of a waiting room was booked with,
+// every admission of a ticket books once, a ticket admitted again
+// gets a new admission ending at another ExpiresAt
+type AdmissionUse struct {
+	ID            uint      `gorm:"primaryKey"`
+	WaitingRoomID uint      `gorm:"not null;uniqueIndex:idx_admission_use"`
+	Ticket        string    `gorm:"size:64;not null;uniqueIndex:idx_admission_use"`
+	ExpiresAt     time.Time `gorm:"not null;uniqueIndex:idx_admission_use"`
+	CreatedAt     time.Time
 }
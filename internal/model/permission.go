@@ -0,0 +1,66 @@
+package model
+
+// Permission is an operation a role may be allowed to perform
+type Permission string
+
+const (
+	PermManageCinemas     Permission = "cinemas:manage"
+	PermManageHalls       Permission = "halls:manage"
+	PermManageMaintenance Permission = "maintenance:manage"
+	PermManageMovies      Permission = "movies:manage"
+	PermManageShowtimes   Permission = "showtimes:manage"
+	PermReserveForOthers  Permission = "reservations:reserve_for_others"
+	PermViewReservations  Permission = "reservations:view_all"
+	PermRefund            Permission = "reservations:refund"
+	PermManageUsers       Permission = "users:manage"
+	PermManageAPIKeys     Permission = "api_keys:manage"
+)
+
+var rolePermissions = map[UserRole][]Permission{
+	RoleUser: {},
+	RoleAdmin: {
+		PermManageCinemas, PermManageHalls, PermManageMaintenance, PermManageMovies, PermManageShowtimes,
+		PermReserveForOthers, PermViewReservations, PermRefund, PermManageUsers, PermManageAPIKeys,
+	},
+	RoleBoxOffice:  {PermReserveForOthers, PermViewReservations, PermManageMaintenance},
+	RoleProgrammer: {PermManageMovies, PermManageShowtimes},
+	RoleFinance:    {PermViewReservations, PermRefund},
+}
+
+func (r UserRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions granted to the role
+func (r UserRole) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+func (r UserRole) Has(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Roles lists every valid role
+func Roles() []UserRole {
+	return []UserRole{RoleUser, RoleAdmin, RoleBoxOffice, RoleProgrammer, RoleFinance}
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range rolePermissions[RoleAdmin] {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists every permission, the admin role has them all
+func Permissions() []Permission {
+	return rolePermissions[RoleAdmin]
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// TwoFactor is the TOTP enrollment of a user, EncryptedSecret is sealed with the server key.
+// It is only enforced once EnabledAt is set, after the user proved the authenticator works.
+// LastUsedStep is the time step of the last accepted code, so a code can't be replayed
+type TwoFactor struct {
+	UserID          uint   `gorm:"primaryKey"`
+	EncryptedSecret string `gorm:"not null"`
+	EnabledAt       *time.Time
+	LastUsedStep    int64 `gorm:"not null;default:0"`
+	CreatedAt       time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// RecoveryCode is a single-use code to sign in without the authenticator,
+// only its SHA-256 hash is stored
+type RecoveryCode struct {
+	ID       uint   `gorm:"primaryKey"`
+	UserID   uint   `gorm:"not null;index"`
+	CodeHash string `gorm:"size:64;not null;uniqueIndex"`
+	UsedAt   *time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
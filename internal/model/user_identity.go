@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// UserIdentity links an account at an external OpenID Connect provider to a user,
+// a user may have several, the provider and its subject identify the account
+type UserIdentity struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	Provider  string `gorm:"size:64;not null;uniqueIndex:idx_identity_subject"`
+	Subject   string `gorm:"size:255;not null;uniqueIndex:idx_identity_subject"`
+	Email     string `gorm:"size:255"`
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// ExternalIdentity is what a provider asserted about the user who signed in
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// preferred_username or name, used to derive the name of a new user
+	Name string
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// TokenPurpose is what a UserToken may be used for
+type TokenPurpose string
+
+const (
+	TokenEmailVerification TokenPurpose = "email_verification"
+	TokenPasswordReset     TokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use secret sent to a user by email,
+// only its SHA-256 hash is stored. Email is the address it was sent to,
+// a verification token is worthless once the user changed the address
+type UserToken struct {
+	ID        uint         `gorm:"primaryKey"`
+	UserID    uint         `gorm:"not null;index"`
+	Purpose   TokenPurpose `gorm:"type:varchar(32);not null"`
+	TokenHash string       `gorm:"size:64;not null;uniqueIndex"`
+	Email     string       `gorm:"size:255;not null"`
+	ExpiresAt time.Time    `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,1152 @@
+package openapi_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/qs-lzh/movie-reservation/config"
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/auth"
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/httperror"
+	"github.com/qs-lzh/movie-reservation/internal/mail"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/openapi"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+	"github.com/qs-lzh/movie-reservation/internal/router"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData(openapi.Spec)
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	return doc
+}
+
+// newTestApp wires the services like app.New, on sqlite and an in-memory redis
+func newTestApp(t *testing.T) (*app.App, *mail.MemoryOutbox) {
+	t.Helper()
+	redis := miniredis.RunT(t)
+	redisCache := cache.NewRedisCache(redis.Addr())
+	t.Cleanup(func() { _ = redisCache.Close() })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&model.User{},
+		&model.UserToken{},
+		&model.APIKey{},
+		&model.UserIdentity{},
+		&model.TwoFactor{},
+		&model.RecoveryCode{},
+		&model.Cinema{},
+		&model.Hall{},
+		&model.Movie{},
+		&model.Showtime{},
+		&model.Reservation{},
+		&model.SeatBlock{},
+		&model.HallMaintenance{},
+		&model.WaitingRoom{},
+		&model.AdmissionUse{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	userRepo := repository.NewUserRepoGorm(db)
+	hallRepo := repository.NewHallRepoGorm(db)
+	cinemaRepo := repository.NewCinemaRepoGorm(db)
+	showtimeRepo := repository.NewShowtimeRepoGorm(db)
+	movieRepo := repository.NewMovieRepoGorm(db)
+	reservationRepo := repository.NewReservationRepoGorm(db)
+	maintenanceRepo := repository.NewHallMaintenanceRepoGorm(db)
+	seatBlockRepo := repository.NewSeatBlockRepoGorm(db)
+	outbox := mail.NewMemoryOutbox()
+
+	a := &app.App{
+		Config: &config.Config{},
+		DB:     db,
+		Cache:  redisCache,
+		Logger: zap.NewNop(),
+	}
+	a.CaptchaService = service.NewDeterministicCaptchaService(service.NewMemoryChallengeStore())
+	a.SeatEvents = service.NewRedisSeatEvents(redisCache)
+	sessions := auth.NewRedisSessionStore(redisCache)
+	a.UserService = service.NewUserService(db, userRepo, reservationRepo,
+		service.NewLoginThrottle(service.NewMemoryAttemptStore()), a.CaptchaService, sessions, a.SeatEvents)
+	a.AccountService = service.NewAccountService(db, userRepo, repository.NewUserTokenRepoGorm(db),
+		sessions, outbox, "http://localhost:8080")
+	a.APIKeyService = service.NewAPIKeyService(db, repository.NewAPIKeyRepoGorm(db), service.NewMemoryRequestCounter())
+	a.CinemaService = service.NewCinemaService(db, cinemaRepo, hallRepo)
+	a.CatalogCache = service.NewCatalogCache(cache.NewTwoTier(cache.NewLRU(1000), redisCache, time.Minute), redisCache)
+	a.CacheTTLs = map[service.CatalogEntity]time.Duration{
+		service.CatalogMovies:    time.Minute,
+		service.CatalogHalls:     time.Minute,
+		service.CatalogShowtimes: time.Minute,
+	}
+	a.ShowtimeService = service.NewShowtimeService(db, showtimeRepo, movieRepo, hallRepo, maintenanceRepo, a.CatalogCache)
+	a.HallService = service.NewHallService(db, hallRepo, cinemaRepo, a.ShowtimeService, a.CatalogCache)
+	a.MovieService = service.NewMovieService(db, movieRepo, a.ShowtimeService, a.CatalogCache)
+	a.MaintenanceService = service.NewMaintenanceService(db, seatBlockRepo, maintenanceRepo, hallRepo,
+		showtimeRepo, a.SeatEvents)
+	a.IdempotencyStore = service.NewRedisIdempotencyStore(redisCache, service.NewMemoryIdempotencyStore())
+	a.RateLimiter = service.NewRedisRateLimiter(redisCache, service.NewMemoryRateLimiter())
+	a.WaitingRoomService = service.NewWaitingRoomService(db, repository.NewWaitingRoomRepoGorm(db), showtimeRepo,
+		movieRepo, service.NewRedisWaitingQueue(redisCache, service.NewMemoryWaitingQueue()),
+		service.NewAdmissionTokens("admission-secret"), a.RateLimiter)
+	a.ReservationService = service.NewReservationService(db, reservationRepo, showtimeRepo, hallRepo,
+		seatBlockRepo, maintenanceRepo, userRepo, a.CaptchaService, service.NewLookupCodes("lookup-secret"),
+		a.SeatEvents, a.WaitingRoomService)
+	keyring, err := auth.NewKeyring("k1", "contract-test-signing-secret", "")
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	box, err := service.NewSecretBox(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if err != nil {
+		t.Fatalf("secret box: %v", err)
+	}
+	a.TwoFactorService = service.NewTwoFactorService(db, repository.NewTwoFactorRepoGorm(db),
+		repository.NewRecoveryCodeRepoGorm(db), userRepo, box, service.NewMemoryAttemptStore(),
+		[]model.UserRole{model.RoleAdmin})
+	a.TokenService = auth.NewTokenService(keyring, sessions, a.UserService, a.TwoFactorService)
+	a.IdentityService = service.NewIdentityService(db, repository.NewUserIdentityRepoGorm(db), userRepo)
+	a.OIDCService, err = auth.NewOIDCService(context.Background(), []auth.OIDCProviderConfig{{
+		Name:        "example",
+		IssuerURL:   discoveryServer(t),
+		ClientID:    "movie-reservation",
+		RedirectURL: "http://localhost:8080/auth/oidc/callback",
+	}}, auth.NewMemoryOIDCStateStore(), a.IdentityService, a.TokenService)
+	if err != nil {
+		t.Fatalf("oidc: %v", err)
+	}
+	return a, outbox
+}
+
+// discoveryServer returns the issuer URL of a provider that only serves its discovery document,
+// enough to redirect to it; the sign-in itself is tested in the auth package
+func discoveryServer(t *testing.T) string {
+	t.Helper()
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"jwks_uri":                              issuer + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	}))
+	t.Cleanup(server.Close)
+	issuer = server.URL
+	return issuer
+}
+
+// contractClient sends requests to the router and checks both the requests
+// and the responses against the spec, recording the operations it saw
+type contractClient struct {
+	t       *testing.T
+	handler http.Handler
+	spec    routers.Router
+	covered map[string]bool
+	// the headers of the last response
+	header http.Header
+}
+
+type call struct {
+	method string
+	path   string
+	token  string
+	body   any
+	header map[string]string
+	// host:port the request comes from, httptest's default if empty
+	remoteAddr string
+	// the request itself breaks the contract, e.g. a malformed body,
+	// only the response is checked
+	invalid bool
+	status  int
+	// headers the response must have
+	wantHeader map[string]string
+}
+
+func (c *contractClient) do(req call) []byte {
+	c.t.Helper()
+	var body []byte
+	switch b := req.body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			c.t.Fatalf("%s %s: marshal body: %v", req.method, req.path, err)
+		}
+	}
+
+	httpRequest := httptest.NewRequest(req.method, req.path, bytes.NewReader(body))
+	if body != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if req.token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+req.token)
+	}
+	for name, value := range req.header {
+		httpRequest.Header.Set(name, value)
+	}
+	if req.remoteAddr != "" {
+		httpRequest.RemoteAddr = req.remoteAddr
+	}
+
+	route, pathParams, err := c.spec.FindRoute(httpRequest)
+	if err != nil {
+		c.t.Fatalf("%s %s is not in the spec: %v", req.method, req.path, err)
+	}
+	c.covered[route.Method+" "+route.Path] = true
+
+	ctx := context.Background()
+	input := &openapi3filter.RequestValidationInput{
+		Request:    httpRequest,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	if !req.invalid {
+		if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+			c.t.Fatalf("%s %s: request breaks the spec: %v", req.method, req.path, err)
+		}
+		// ValidateRequest consumed the body
+		httpRequest.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	recorder := httptest.NewRecorder()
+	c.handler.ServeHTTP(recorder, httpRequest)
+	responseBody := recorder.Body.Bytes()
+	c.header = recorder.Header()
+	if recorder.Code != req.status {
+		c.t.Fatalf("%s %s: status %d, want %d: %s", req.method, req.path, recorder.Code, req.status, responseBody)
+	}
+	for name, value := range req.wantHeader {
+		if got := recorder.Header().Get(name); got != value {
+			c.t.Fatalf("%s %s: header %s is %q, want %q", req.method, req.path, name, got, value)
+		}
+	}
+
+	err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 recorder.Code,
+		Header:                 recorder.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(responseBody)),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+		},
+	})
+	if err != nil {
+		c.t.Fatalf("%s %s: response %d breaks the spec: %v\n%s", req.method, req.path, recorder.Code, err, responseBody)
+	}
+	return responseBody
+}
+
+// stream opens a server-sent event stream and returns the data of its events
+// together with the schema they must match, stop closes the stream
+func (c *contractClient) stream(path string, schema *openapi3.Schema) (events <-chan []byte, stop func()) {
+	c.t.Helper()
+	server := httptest.NewServer(c.handler)
+	ctx, cancel := context.WithCancel(context.Background())
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+	if err != nil {
+		c.t.Fatalf("GET %s: %v", path, err)
+	}
+	stop = func() {
+		cancel()
+		server.Close()
+	}
+
+	route, pathParams, err := c.spec.FindRoute(httptest.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		stop()
+		c.t.Fatalf("GET %s is not in the spec: %v", path, err)
+	}
+	c.covered[route.Method+" "+route.Path] = true
+	input := &openapi3filter.RequestValidationInput{
+		Request:    httpRequest,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	if err := openapi3filter.ValidateRequest(ctx, input); err != nil {
+		stop()
+		c.t.Fatalf("GET %s: request breaks the spec: %v", path, err)
+	}
+
+	response, err := http.DefaultClient.Do(httpRequest)
+	if err != nil {
+		stop()
+		c.t.Fatalf("GET %s: %v", path, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		stop()
+		c.t.Fatalf("GET %s: status %d, want 200", path, response.StatusCode)
+	}
+	// the body never ends, only the status and the content type are checked
+	err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 response.StatusCode,
+		Header:                 response.Header,
+		Body:                   io.NopCloser(bytes.NewReader(nil)),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			ExcludeResponseBody:   true,
+		},
+	})
+	if err != nil {
+		stop()
+		c.t.Fatalf("GET %s: response breaks the spec: %v", path, err)
+	}
+
+	data := make(chan []byte, 16)
+	go func() {
+		defer close(data)
+		defer response.Body.Close()
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			line, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			var value any
+			if err := json.Unmarshal([]byte(line), &value); err != nil {
+				c.t.Errorf("GET %s: event %s: %v", path, line, err)
+				return
+			}
+			if err := schema.VisitJSON(value); err != nil {
+				c.t.Errorf("GET %s: event breaks the spec: %v\n%s", path, err, line)
+				return
+			}
+			data <- []byte(line)
+		}
+	}()
+	return data, stop
+}
+
+// nextEvent waits for the next event of a stream
+func nextEvent[T any](t *testing.T, events <-chan []byte) T {
+	t.Helper()
+	select {
+	case data, ok := <-events:
+		if !ok {
+			t.Fatalf("event stream closed")
+		}
+		return decode[T](t, data)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event")
+	}
+	panic("unreachable")
+}
+
+func decode[T any](t *testing.T, body []byte) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+	return v
+}
+
+type seatAvailability struct {
+	ShowtimeID uint `json:"showtime_id"`
+	Seats      []struct {
+		SeatID uint   `json:"seat_id"`
+		Status string `json:"status"`
+	} `json:"seats"`
+}
+
+func errorCode(t *testing.T, body []byte) service.Code {
+	t.Helper()
+	return decode[httperror.Body](t, body).Error.Code
+}
+
+// lastMailToken returns the token of the link in the last mail sent to the address
+func lastMailToken(t *testing.T, outbox *mail.MemoryOutbox, to string) string {
+	t.Helper()
+	messages := outbox.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].To != to {
+			continue
+		}
+		match := regexp.MustCompile(`token=([A-Za-z0-9_\-]+)`).FindStringSubmatch(messages[i].Body)
+		if match == nil {
+			t.Fatalf("no token in the mail to %s: %s", to, messages[i].Body)
+		}
+		return match[1]
+	}
+	t.Fatalf("no mail to %s", to)
+	return ""
+}
+
+// TestContract exercises every operation of the spec and validates
+// the requests and the responses against it
+func TestContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := loadSpec(t)
+	specRouter, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("spec router: %v", err)
+	}
+	a, outbox := newTestApp(t)
+	c := &contractClient{
+		t:       t,
+		handler: router.New(a),
+		spec:    specRouter,
+		covered: map[string]bool{},
+	}
+
+	c.do(call{method: "GET", path: "/openapi.yaml", status: 200})
+	// the deterministic captcha solves every challenge with the same answer
+	solveCaptcha := func() map[string]any {
+		challenge := decode[map[string]any](t, c.do(call{method: "GET", path: "/captcha", status: 200}))
+		return map[string]any{
+			"challenge_id": challenge["id"],
+			"x":            service.DeterministicCaptchaAnswer.X,
+			"y":            service.DeterministicCaptchaAnswer.Y,
+		}
+	}
+
+	// accounts
+	const password = "Correct-Horse-9"
+	c.do(call{method: "POST", path: "/auth/register", status: 201, body: map[string]any{
+		"name": "alice", "password": password, "email": "alice@example.com",
+		"captcha": solveCaptcha(),
+	}})
+	c.do(call{method: "POST", path: "/auth/register", status: 201, body: map[string]any{
+		"name": "bob", "password": password, "email": "bob@example.com", "display_name": "Bob",
+		"captcha": solveCaptcha(),
+	}})
+	body := c.do(call{method: "POST", path: "/auth/register", status: 409, body: map[string]any{
+		"name": "alice", "password": password, "email": "alice2@example.com",
+		"captcha": solveCaptcha(),
+	}})
+	if code := errorCode(t, body); code != service.CodeAlreadyExists {
+		t.Fatalf("duplicate name: code %s", code)
+	}
+	body = c.do(call{method: "POST", path: "/auth/register", status: 400, body: map[string]any{
+		"name": "carol", "password": "short", "email": "carol@example.com",
+		"captcha": solveCaptcha(),
+	}})
+	if fields := decode[httperror.Body](t, body).Error.Details["fields"]; fields == nil {
+		t.Fatalf("invalid input without fields: %s", body)
+	}
+
+	alice, err := a.UserService.GetUserByName("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.UserService.AssignRole(service.SystemActor, alice.ID, model.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	c.do(call{method: "POST", path: "/auth/login", status: 401, body: map[string]any{
+		"name": "alice", "password": "wrong-password-1",
+	}})
+	tokens := decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: map[string]any{
+		"name": "alice", "password": password,
+	}}))
+	adminToken := tokens["access_token"].(string)
+	refreshToken := tokens["refresh_token"].(string)
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: map[string]any{
+		"name": "bob", "password": password,
+	}}))
+	bobToken := tokens["access_token"].(string)
+
+	c.do(call{method: "GET", path: "/auth/me", status: 401})
+	c.do(call{method: "GET", path: "/auth/me", token: "not-a-token", status: 401})
+	c.do(call{method: "GET", path: "/auth/me", token: adminToken, status: 200})
+
+	c.do(call{method: "POST", path: "/auth/verify-email/send", token: adminToken, status: 202})
+	c.do(call{method: "POST", path: "/auth/verify-email", status: 400, body: map[string]any{"token": "nope"}})
+	c.do(call{method: "POST", path: "/auth/verify-email", status: 204, body: map[string]any{
+		"token": lastMailToken(t, outbox, "alice@example.com"),
+	}})
+	body = c.do(call{method: "POST", path: "/auth/verify-email/send", token: adminToken, status: 409})
+	if code := errorCode(t, body); code != service.CodeAlreadyVerified {
+		t.Fatalf("verified twice: code %s", code)
+	}
+
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/refresh", status: 200, body: map[string]any{
+		"refresh_token": refreshToken,
+	}}))
+	c.do(call{method: "POST", path: "/auth/logout", status: 204, body: map[string]any{
+		"refresh_token": tokens["refresh_token"],
+	}})
+	c.do(call{method: "POST", path: "/auth/refresh", status: 401, body: map[string]any{
+		"refresh_token": tokens["refresh_token"],
+	}})
+	// the logout ended the session of adminToken
+	c.do(call{method: "GET", path: "/auth/me", token: adminToken, status: 401})
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: map[string]any{
+		"name": "alice", "password": password,
+	}}))
+	adminToken = tokens["access_token"].(string)
+
+	// admins only get their permissions in sessions started with a second factor,
+	// without one they can still enroll
+	status := decode[map[string]any](t, c.do(call{method: "GET", path: "/auth/2fa", token: adminToken, status: 200}))
+	if status["enabled"] != false || status["required"] != true {
+		t.Fatalf("two factors of a new admin: %v", status)
+	}
+	c.do(call{method: "GET", path: "/api-keys", token: adminToken, status: 403})
+	c.do(call{method: "POST", path: "/auth/2fa/confirm", token: adminToken, status: 400,
+		body: map[string]any{"code": "123456"}})
+	enrollment := decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/2fa/enroll", token: adminToken,
+		status: 201}))
+	c.do(call{method: "POST", path: "/auth/2fa/confirm", token: adminToken, status: 401,
+		body: map[string]any{"code": "abcdef"}})
+	totpCode, err := totp.GenerateCode(enrollment["secret"].(string), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovery := decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/2fa/confirm", token: adminToken,
+		status: 200, body: map[string]any{"code": totpCode}}))["recovery_codes"].([]any)
+	c.do(call{method: "POST", path: "/auth/2fa/enroll", token: adminToken, status: 409})
+	body = c.do(call{method: "POST", path: "/auth/login", status: 401, body: map[string]any{
+		"name": "alice", "password": password,
+	}})
+	if code := errorCode(t, body); code != service.CodeTwoFactorRequired {
+		t.Fatalf("login without the second factor: code %s", code)
+	}
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: map[string]any{
+		"name": "alice", "password": password, "otp": recovery[0],
+	}}))
+	adminToken = tokens["access_token"].(string)
+	c.do(call{method: "GET", path: "/api-keys", token: adminToken, status: 200})
+	c.do(call{method: "POST", path: "/auth/2fa/recovery-codes", token: adminToken, status: 401,
+		body: map[string]any{"code": recovery[0]}})
+	c.do(call{method: "POST", path: "/auth/2fa/recovery-codes", token: adminToken, status: 200,
+		body: map[string]any{"code": recovery[1]}})
+	c.do(call{method: "DELETE", path: fmt.Sprintf("/users/%d/2fa", alice.ID), token: adminToken, status: 403})
+
+	c.do(call{method: "POST", path: "/auth/password-reset", status: 202, body: map[string]any{
+		"email": "nobody@example.com",
+	}})
+	c.do(call{method: "POST", path: "/auth/password-reset", status: 202, body: map[string]any{
+		"email": "bob@example.com",
+	}})
+	c.do(call{method: "POST", path: "/auth/password-reset/confirm", status: 400, body: map[string]any{
+		"token": "nope", "password": "Another-Horse-9",
+	}})
+	c.do(call{method: "POST", path: "/auth/password-reset/confirm", status: 204, body: map[string]any{
+		"token": lastMailToken(t, outbox, "bob@example.com"), "password": "Another-Horse-9",
+	}})
+	// the reset signed bob out everywhere
+	c.do(call{method: "GET", path: "/auth/me", token: bobToken, status: 401})
+	bobLogin := map[string]any{"name": "bob", "password": "Another-Horse-9"}
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: bobLogin}))
+	otherDevice := tokens["refresh_token"].(string)
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: bobLogin}))
+	c.do(call{method: "POST", path: "/auth/logout-all", status: 401})
+	c.do(call{method: "POST", path: "/auth/logout-all", token: tokens["access_token"].(string), status: 204})
+	c.do(call{method: "POST", path: "/auth/refresh", status: 401, body: map[string]any{"refresh_token": otherDevice}})
+	c.do(call{method: "GET", path: "/auth/me", token: tokens["access_token"].(string), status: 401})
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: bobLogin}))
+	bobToken = tokens["access_token"].(string)
+
+	// accounts of users
+	bob, err := a.UserService.GetUserByName("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobPath := fmt.Sprintf("/users/%d", bob.ID)
+	alicePath := fmt.Sprintf("/users/%d", alice.ID)
+	profile := decode[map[string]any](t, c.do(call{method: "PUT", path: bobPath + "/profile", token: bobToken,
+		status: 200, body: map[string]any{"email": "bob@example.com", "display_name": "Bobby"}}))
+	if profile["display_name"] != "Bobby" {
+		t.Fatalf("profile after update: %v", profile)
+	}
+	c.do(call{method: "PUT", path: alicePath + "/profile", token: bobToken, status: 403,
+		body: map[string]any{"email": "mallory@example.com"}})
+	c.do(call{method: "PUT", path: bobPath + "/profile", token: adminToken, status: 409,
+		body: map[string]any{"email": "alice@example.com", "display_name": "Bobby"}})
+	c.do(call{method: "POST", path: bobPath + "/password", token: bobToken, status: 401,
+		body: map[string]any{"current_password": "wrong-password-1", "new_password": password}})
+	c.do(call{method: "POST", path: bobPath + "/password", token: bobToken, status: 204,
+		body: map[string]any{"current_password": "Another-Horse-9", "new_password": password}})
+	// the change signed bob out everywhere
+	c.do(call{method: "GET", path: "/auth/me", token: bobToken, status: 401})
+	bobLogin["password"] = password
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: bobLogin}))
+	bobToken = tokens["access_token"].(string)
+
+	c.do(call{method: "PUT", path: bobPath + "/role", token: bobToken, status: 403, body: map[string]any{"role": "admin"}})
+	c.do(call{method: "PUT", path: alicePath + "/role", token: adminToken, status: 403,
+		body: map[string]any{"role": "user"}})
+	c.do(call{method: "PUT", path: bobPath + "/role", token: adminToken, status: 400, invalid: true,
+		body: map[string]any{"role": "emperor"}})
+	c.do(call{method: "PUT", path: bobPath + "/role", token: adminToken, status: 204,
+		body: map[string]any{"role": "box_office"}})
+	// the tokens carry the role, bob signs in again to get the new one
+	c.do(call{method: "GET", path: "/auth/me", token: bobToken, status: 401})
+	c.do(call{method: "PUT", path: bobPath + "/role", token: adminToken, status: 204, body: map[string]any{"role": "user"}})
+	c.do(call{method: "PUT", path: "/users/999/role", token: adminToken, status: 404, body: map[string]any{"role": "user"}})
+	c.do(call{method: "PUT", path: bobPath + "/status", token: adminToken, status: 204,
+		body: map[string]any{"status": "suspended"}})
+	body = c.do(call{method: "POST", path: "/auth/login", status: 403, body: bobLogin})
+	if code := errorCode(t, body); code != service.CodeUserSuspended {
+		t.Fatalf("suspended login: code %s", code)
+	}
+	c.do(call{method: "PUT", path: bobPath + "/status", token: adminToken, status: 204,
+		body: map[string]any{"status": "unverified"}})
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: bobLogin}))
+	bobToken = tokens["access_token"].(string)
+	c.do(call{method: "POST", path: alicePath + "/unlock", token: bobToken, status: 403})
+	c.do(call{method: "POST", path: bobPath + "/unlock", token: adminToken, status: 204})
+
+	c.do(call{method: "POST", path: "/auth/2fa/disable", token: bobToken, status: 400, body: map[string]any{"code": "123456"}})
+	enrollBob := func() []any {
+		enrollment := decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/2fa/enroll", token: bobToken,
+			status: 201}))
+		code, err := totp.GenerateCode(enrollment["secret"].(string), time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/2fa/confirm", token: bobToken,
+			status: 200, body: map[string]any{"code": code}}))["recovery_codes"].([]any)
+	}
+	bobRecovery := enrollBob()
+	c.do(call{method: "POST", path: "/auth/2fa/disable", token: bobToken, status: 204,
+		body: map[string]any{"code": bobRecovery[0]}})
+	enrollBob()
+	c.do(call{method: "DELETE", path: alicePath + "/2fa", token: bobToken, status: 403})
+	c.do(call{method: "DELETE", path: bobPath + "/2fa", token: adminToken, status: 204})
+	// bob logs in without a code again
+	c.do(call{method: "POST", path: "/auth/login", status: 200, body: bobLogin})
+
+	// identities at OpenID Connect providers
+	providers := decode[map[string]any](t, c.do(call{method: "GET", path: "/auth/oidc/providers", status: 200}))
+	if fmt.Sprint(providers["providers"]) != "[example]" {
+		t.Fatalf("identity providers: %v", providers)
+	}
+	c.do(call{method: "GET", path: "/auth/oidc/example/login", status: 302})
+	if location := c.header.Get("Location"); !strings.Contains(location, "/authorize?") ||
+		!strings.Contains(location, "code_challenge_method=S256") {
+		t.Fatalf("redirect to the provider: %s", location)
+	}
+	c.do(call{method: "GET", path: "/auth/oidc/nowhere/login", status: 404})
+	c.do(call{method: "POST", path: "/auth/oidc/example/link", status: 401})
+	link := decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/oidc/example/link", token: bobToken,
+		status: 200}))
+	if !strings.Contains(link["url"].(string), "state=") {
+		t.Fatalf("link URL: %v", link)
+	}
+	body = c.do(call{method: "GET", path: "/auth/oidc/callback?state=forged&code=abc", status: 400})
+	if code := errorCode(t, body); code != auth.CodeInvalidState {
+		t.Fatalf("callback with a forged state: code %s", code)
+	}
+	c.do(call{method: "GET", path: "/auth/oidc/callback?error=access_denied", status: 400, invalid: true})
+	err = a.IdentityService.LinkIdentity(service.SystemActor, bob.ID, model.ExternalIdentity{
+		Provider: "example", Subject: "248289761001", Email: "bob@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	identities := decode[[]map[string]any](t, c.do(call{method: "GET", path: bobPath + "/identities", token: bobToken,
+		status: 200}))
+	if len(identities) != 1 || identities[0]["subject"] != "248289761001" {
+		t.Fatalf("identities of bob: %v", identities)
+	}
+	c.do(call{method: "GET", path: alicePath + "/identities", token: bobToken, status: 403})
+	identityPath := fmt.Sprintf("%s/identities/%v", bobPath, identities[0]["id"])
+	c.do(call{method: "DELETE", path: fmt.Sprintf("%s/identities/%v", alicePath, identities[0]["id"]), token: adminToken,
+		status: 404})
+	c.do(call{method: "DELETE", path: identityPath, token: bobToken, status: 204})
+	c.do(call{method: "DELETE", path: identityPath, token: bobToken, status: 404})
+
+	c.do(call{method: "POST", path: "/auth/register", status: 201, body: map[string]any{
+		"name": "dave", "password": password, "email": "dave@example.com", "captcha": solveCaptcha(),
+	}})
+	tokens = decode[map[string]any](t, c.do(call{method: "POST", path: "/auth/login", status: 200, body: map[string]any{
+		"name": "dave", "password": password,
+	}}))
+	dave, err := a.UserService.GetUserByName("dave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	davePath := fmt.Sprintf("/users/%d", dave.ID)
+	c.do(call{method: "DELETE", path: davePath, token: bobToken, status: 403})
+	c.do(call{method: "DELETE", path: davePath, token: tokens["access_token"].(string), status: 204})
+	c.do(call{method: "POST", path: "/auth/login", status: 401, body: map[string]any{
+		"name": "dave", "password": password,
+	}})
+	c.do(call{method: "DELETE", path: davePath, token: adminToken, status: 404})
+
+	// API keys
+	keyRequest := map[string]any{"name": "kiosk", "scopes": []string{"api_keys:manage"}}
+	c.do(call{method: "POST", path: "/api-keys", token: bobToken, status: 403, body: keyRequest})
+	c.do(call{method: "POST", path: "/api-keys", token: adminToken, status: 400,
+		body: map[string]any{"name": "kiosk", "scopes": []string{"api_keys:manage"},
+			"expires_at": time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}})
+	apiKey := decode[map[string]any](t, c.do(call{method: "POST", path: "/api-keys", token: adminToken,
+		status: 201, body: keyRequest}))
+	keyHeader := map[string]string{"X-API-Key": apiKey["key"].(string)}
+	if listed := decode[[]map[string]any](t, c.do(call{method: "GET", path: "/api-keys", header: keyHeader,
+		status: 200})); len(listed) != 1 || listed[0]["key"] != nil {
+		t.Fatalf("listed keys: %v", listed)
+	}
+	c.do(call{method: "GET", path: "/api-keys", token: bobToken, status: 403})
+	apiKeyPath := fmt.Sprintf("/api-keys/%d", int(apiKey["id"].(float64)))
+	c.do(call{method: "DELETE", path: apiKeyPath, token: adminToken, status: 204})
+	c.do(call{method: "GET", path: "/api-keys", header: keyHeader, status: 401})
+	c.do(call{method: "DELETE", path: apiKeyPath, token: adminToken, status: 404})
+
+	// catalogue
+	c.do(call{method: "POST", path: "/cinemas", token: bobToken, status: 403,
+		body: map[string]any{"name": "Odeon", "timezone": "UTC"}})
+	c.do(call{method: "POST", path: "/cinemas", token: adminToken, status: 400,
+		body: map[string]any{"name": "Odeon", "timezone": "Mars/Olympus"}})
+	cinema := decode[map[string]any](t, c.do(call{method: "POST", path: "/cinemas", token: adminToken, status: 201,
+		body: map[string]any{"name": "Odeon", "timezone": "UTC"}}))
+	cinemaPath := fmt.Sprintf("/cinemas/%d", int(cinema["id"].(float64)))
+	c.do(call{method: "POST", path: "/cinemas", token: adminToken, status: 409,
+		body: map[string]any{"name": "Odeon", "timezone": "UTC"}})
+	c.do(call{method: "GET", path: "/cinemas", status: 200})
+	c.do(call{method: "GET", path: cinemaPath, status: 200})
+	c.do(call{method: "GET", path: "/cinemas/999", status: 404})
+	c.do(call{method: "PUT", path: cinemaPath, token: adminToken, status: 200,
+		body: map[string]any{"name": "Odeon", "address": "1 Main St", "timezone": "Europe/Berlin"}})
+	closed := decode[map[string]any](t, c.do(call{method: "POST", path: "/cinemas", token: adminToken, status: 201,
+		body: map[string]any{"name": "Roxy", "timezone": "UTC"}}))
+	c.do(call{method: "DELETE", path: fmt.Sprintf("/cinemas/%d", int(closed["id"].(float64))), token: adminToken,
+		status: 204})
+
+	c.do(call{method: "POST", path: "/movies", status: 401, body: map[string]any{"title": "Dune"}})
+	c.do(call{method: "POST", path: "/movies", token: bobToken, status: 403, body: map[string]any{"title": "Dune"}})
+	c.do(call{method: "POST", path: "/movies", token: adminToken, status: 400, body: "{bad", invalid: true})
+	c.do(call{method: "POST", path: "/movies", token: adminToken, status: 400, body: map[string]any{"title": " "}})
+	movie := decode[map[string]any](t, c.do(call{method: "POST", path: "/movies", token: adminToken, status: 201,
+		body: map[string]any{"title": "Dune", "description": "Sand"}}))
+	movieID := int(movie["id"].(float64))
+	c.do(call{method: "POST", path: "/movies", token: adminToken, status: 409, body: map[string]any{"title": "Dune"}})
+	c.do(call{method: "GET", path: "/movies", status: 200})
+	c.do(call{method: "GET", path: "/movies?cinema_id=abc", status: 400, invalid: true})
+	moviePath := fmt.Sprintf("/movies/%d", movieID)
+	c.do(call{method: "GET", path: moviePath, status: 200, wantHeader: map[string]string{"X-Cache": "MISS"}})
+	etag := c.header.Get("ETag")
+	c.do(call{method: "GET", path: moviePath, status: 200,
+		wantHeader: map[string]string{"X-Cache": "HIT", "ETag": etag}})
+	c.do(call{method: "GET", path: moviePath, header: map[string]string{"If-None-Match": etag}, status: 304})
+	c.do(call{method: "GET", path: "/movies/999", status: 404})
+	c.do(call{method: "PUT", path: moviePath, token: adminToken, status: 200,
+		body: map[string]any{"title": "Dune: Part One", "description": "Sand", "version": 1}})
+	// the update invalidated the cached movie
+	updated := decode[map[string]any](t, c.do(call{method: "GET", path: moviePath, status: 200,
+		header: map[string]string{"If-None-Match": etag}, wantHeader: map[string]string{"X-Cache": "MISS"}}))
+	if updated["title"] != "Dune: Part One" || c.header.Get("ETag") == etag {
+		t.Fatalf("movie after update: %v, ETag %s", updated, c.header.Get("ETag"))
+	}
+	body = c.do(call{method: "PATCH", path: fmt.Sprintf("/movies/%d", movieID), token: adminToken, status: 409,
+		body: map[string]any{"title": "Dune 2", "version": 1}})
+	if code := errorCode(t, body); code != service.CodeVersionConflict {
+		t.Fatalf("stale patch: code %s", code)
+	}
+	c.do(call{method: "PATCH", path: fmt.Sprintf("/movies/%d", movieID), token: adminToken, status: 200,
+		body: map[string]any{"description": "Spice", "version": 2}})
+
+	hall := decode[map[string]any](t, c.do(call{method: "POST", path: "/halls", token: adminToken, status: 201,
+		body: map[string]any{"cinema_id": cinema["id"], "name": "Hall 1", "rows": 2, "cols": 3}}))
+	hallPath := fmt.Sprintf("/halls/%d", int(hall["id"].(float64)))
+	spare := decode[map[string]any](t, c.do(call{method: "POST", path: "/halls", token: adminToken, status: 201,
+		body: map[string]any{"cinema_id": cinema["id"], "name": "Hall 2", "layout": map[string]any{
+			"rows": []any{
+				map[string]any{"label": "A", "cells": []any{
+					map[string]any{"kind": "seat", "category": "standard"},
+					map[string]any{"kind": "aisle"},
+					map[string]any{"kind": "seat", "category": "wheelchair"},
+				}},
+			},
+		}}}))
+	sparePath := fmt.Sprintf("/halls/%d", int(spare["id"].(float64)))
+	c.do(call{method: "GET", path: "/halls", status: 200})
+	c.do(call{method: "GET", path: fmt.Sprintf("/halls?cinema_id=%d", int(cinema["id"].(float64))), status: 200})
+	c.do(call{method: "GET", path: hallPath, status: 200})
+	c.do(call{method: "PUT", path: hallPath, token: adminToken, status: 200,
+		body: map[string]any{"cinema_id": cinema["id"], "name": "Hall 1", "rows": 3, "cols": 4, "version": 1}})
+	c.do(call{method: "PATCH", path: sparePath, token: adminToken, status: 200,
+		body: map[string]any{"name": "Studio", "version": 1}})
+	c.do(call{method: "DELETE", path: sparePath, token: adminToken, status: 204})
+	c.do(call{method: "GET", path: sparePath, status: 404})
+	body = c.do(call{method: "DELETE", path: cinemaPath, token: adminToken, status: 409})
+	if code := errorCode(t, body); code != service.CodeRelatedResources {
+		t.Fatalf("cinema with halls: code %s", code)
+	}
+
+	c.do(call{method: "GET", path: "/showtimes", status: 200})
+	startAt := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	body = c.do(call{method: "POST", path: "/showtimes", token: adminToken, status: 400,
+		body: map[string]any{"movie_id": 999, "hall_id": hall["id"], "start_at": startAt}})
+	if code := errorCode(t, body); code != service.CodeInvalidInput {
+		t.Fatalf("showtime of a missing movie: code %s", code)
+	}
+	showtime := decode[map[string]any](t, c.do(call{method: "POST", path: "/showtimes", token: adminToken, status: 201,
+		body: map[string]any{"movie_id": movieID, "hall_id": hall["id"], "start_at": startAt}}))
+	showtimeID := int(showtime["id"].(float64))
+	// a window starting an hour into the showtime still clashes with it
+	showtimeAt := time.Now().Add(72 * time.Hour).UTC().Truncate(time.Second)
+	c.do(call{method: "POST", path: "/maintenance-windows", token: bobToken, status: 403,
+		body: map[string]any{"hall_id": hall["id"], "start_at": showtimeAt.Add(time.Hour).Format(time.RFC3339),
+			"end_at": showtimeAt.Add(2 * time.Hour).Format(time.RFC3339)}})
+	c.do(call{method: "POST", path: "/maintenance-windows", token: adminToken, status: 400,
+		body: map[string]any{"hall_id": hall["id"], "start_at": showtimeAt.Add(time.Hour).Format(time.RFC3339),
+			"end_at": showtimeAt.Format(time.RFC3339)}})
+	maintenance := decode[map[string]any](t, c.do(call{method: "POST", path: "/maintenance-windows",
+		token: adminToken, status: 201, body: map[string]any{"hall_id": hall["id"],
+			"start_at": showtimeAt.Add(time.Hour).Format(time.RFC3339),
+			"end_at":   showtimeAt.Add(2 * time.Hour).Format(time.RFC3339), "reason": "projector"}}))
+	if windows := decode[[]any](t, c.do(call{method: "GET", path: hallPath + "/maintenance-windows",
+		status: 200})); len(windows) != 1 {
+		t.Fatalf("listed %d maintenance windows, want 1", len(windows))
+	}
+	body = c.do(call{method: "POST", path: "/showtimes", token: adminToken, status: 409,
+		body: map[string]any{"movie_id": movieID, "hall_id": hall["id"], "start_at": showtimeAt.Format(time.RFC3339)}})
+	if code := errorCode(t, body); code != service.CodeHallUnderMaintenance {
+		t.Fatalf("showtime overlapping a maintenance window: code %s", code)
+	}
+	maintenancePath := fmt.Sprintf("/maintenance-windows/%d", int(maintenance["id"].(float64)))
+	c.do(call{method: "DELETE", path: maintenancePath, token: adminToken, status: 204})
+	c.do(call{method: "DELETE", path: maintenancePath, token: adminToken, status: 404})
+	// the new showtime invalidated the cached list
+	if listed := decode[[]any](t, c.do(call{method: "GET", path: "/showtimes", status: 200})); len(listed) != 1 {
+		t.Fatalf("listed %d showtimes after creating one", len(listed))
+	}
+	c.do(call{method: "GET", path: fmt.Sprintf("/showtimes?movie_id=%d", movieID), status: 200})
+	c.do(call{method: "GET", path: fmt.Sprintf("/showtimes?movie_id=%d&hall_id=1", movieID), status: 400})
+	c.do(call{method: "GET", path: fmt.Sprintf("/showtimes/%d", showtimeID), status: 200})
+	seatsPath := fmt.Sprintf("/showtimes/%d/seats", showtimeID)
+	seats := decode[seatAvailability](t, c.do(call{method: "GET", path: seatsPath, status: 200}))
+	if len(seats.Seats) == 0 || seats.Seats[0].Status != "available" {
+		t.Fatalf("seats of a new showtime: %+v", seats)
+	}
+	c.do(call{method: "GET", path: "/showtimes/999/seats", status: 404})
+	block := decode[map[string]any](t, c.do(call{method: "POST", path: "/seat-blocks", token: adminToken,
+		status: 201, body: map[string]any{"showtime_id": showtimeID, "seat_id": 2, "reason": "broken"}}))
+	c.do(call{method: "POST", path: "/seat-blocks", token: adminToken, status: 404,
+		body: map[string]any{"showtime_id": showtimeID, "seat_id": 999}})
+	c.do(call{method: "GET", path: hallPath + "/seat-blocks", status: 200})
+	seats = decode[seatAvailability](t, c.do(call{method: "GET", path: seatsPath, status: 200}))
+	if seats.Seats[1].SeatID != 2 || seats.Seats[1].Status != "blocked" {
+		t.Fatalf("seats with a blocked seat: %+v", seats)
+	}
+	c.do(call{method: "DELETE", path: fmt.Sprintf("/seat-blocks/%d", int(block["id"].(float64))),
+		token: adminToken, status: 204})
+	c.do(call{method: "GET", path: "/showtimes/999/seats/events", status: 404})
+	seatEvents, stopSeatEvents := c.stream(seatsPath+"/events", doc.Components.Schemas["SeatAvailability"].Value)
+	defer stopSeatEvents()
+	if first := nextEvent[seatAvailability](t, seatEvents); len(first.Seats) != len(seats.Seats) {
+		t.Fatalf("first event has %d seats, want %d", len(first.Seats), len(seats.Seats))
+	}
+	body = c.do(call{method: "DELETE", path: hallPath, token: adminToken, status: 409})
+	if code := errorCode(t, body); code != service.CodeRelatedResources {
+		t.Fatalf("hall with showtimes: code %s", code)
+	}
+
+	// reservations
+	c.do(call{method: "GET", path: "/reservations", status: 401})
+	body = c.do(call{method: "POST", path: "/reservations", token: bobToken, status: 403,
+		body: map[string]any{"showtime_id": showtimeID, "seat_id": 1}})
+	if code := errorCode(t, body); code != service.CodeUserNotVerified {
+		t.Fatalf("unverified booking: code %s", code)
+	}
+	booking := map[string]any{"showtime_id": showtimeID, "seat_id": 1}
+	idempotencyKey := map[string]string{"Idempotency-Key": "booking-1"}
+	reservationBody := c.do(call{method: "POST", path: "/reservations", token: adminToken, header: idempotencyKey,
+		status: 201, body: booking})
+	reservation := decode[map[string]any](t, reservationBody)
+	// a retry gets the same reservation instead of already_reserved
+	retried := c.do(call{method: "POST", path: "/reservations", token: adminToken, header: idempotencyKey,
+		status: 201, body: booking, wantHeader: map[string]string{"Idempotent-Replayed": "true"}})
+	if !bytes.Equal(retried, reservationBody) {
+		t.Fatalf("replayed %s, want %s", retried, reservationBody)
+	}
+	// keys are scoped by the caller, the same key of another user runs its own request
+	body = c.do(call{method: "POST", path: "/reservations", token: bobToken, header: idempotencyKey,
+		status: 403, body: booking})
+	if code := errorCode(t, body); code != service.CodeUserNotVerified {
+		t.Fatalf("key of another user: code %s", code)
+	}
+	body = c.do(call{method: "POST", path: "/reservations", token: adminToken, header: idempotencyKey,
+		status: 422, body: map[string]any{"showtime_id": showtimeID, "seat_id": 2}})
+	if code := errorCode(t, body); code != service.CodeIdempotencyKeyReused {
+		t.Fatalf("key reused for another seat: code %s", code)
+	}
+	c.do(call{method: "POST", path: "/reservations", token: adminToken, invalid: true, status: 400,
+		header: map[string]string{"Idempotency-Key": strings.Repeat("k", 256)}, body: booking})
+	reservationPath := fmt.Sprintf("/reservations/%d", int(reservation["id"].(float64)))
+	changed := nextEvent[seatAvailability](t, seatEvents)
+	if len(changed.Seats) != 1 || changed.Seats[0].SeatID != 1 || changed.Seats[0].Status != "reserved" {
+		t.Fatalf("event after booking: %+v", changed)
+	}
+	body = c.do(call{method: "POST", path: "/reservations", token: adminToken, status: 409,
+		body: map[string]any{"showtime_id": showtimeID, "seat_id": 2}})
+	if code := errorCode(t, body); code != service.CodeAlreadyReserved {
+		t.Fatalf("second booking: code %s", code)
+	}
+	body = c.do(call{method: "POST", path: "/guest-reservations", status: 409, body: map[string]any{
+		"name": "Guest", "email": "guest@example.com", "showtime_id": showtimeID, "seat_id": 1,
+		"captcha": solveCaptcha(),
+	}})
+	if code := errorCode(t, body); code != service.CodeSeatTaken {
+		t.Fatalf("booking a reserved seat: code %s", code)
+	}
+	c.do(call{method: "GET", path: "/reservations", token: adminToken, status: 200})
+	c.do(call{method: "GET", path: reservationPath, token: adminToken, status: 200})
+	c.do(call{method: "GET", path: reservationPath, token: bobToken, status: 403})
+	c.do(call{method: "DELETE", path: reservationPath, token: adminToken, status: 204})
+
+	body = c.do(call{method: "POST", path: "/guest-reservations", status: 400,
+		body: map[string]any{"name": "Guest", "email": "guest@example.com", "showtime_id": showtimeID, "seat_id": 3}})
+	if code := errorCode(t, body); code != service.CodeCaptchaRequired {
+		t.Fatalf("guest booking without captcha: code %s", code)
+	}
+	guestBooking := map[string]any{"name": "Guest", "email": "guest@example.com", "showtime_id": showtimeID,
+		"seat_id": 3, "captcha": solveCaptcha()}
+	guestKey := map[string]string{"Idempotency-Key": "guest-booking-1"}
+	guestBody := c.do(call{method: "POST", path: "/guest-reservations", header: guestKey, status: 201,
+		body: guestBooking})
+	guest := decode[map[string]any](t, guestBody)
+	// the captcha was used, only the replay lets the retry succeed
+	if retried := c.do(call{method: "POST", path: "/guest-reservations", header: guestKey, status: 201,
+		body: guestBooking}); !bytes.Equal(retried, guestBody) {
+		t.Fatalf("replayed %s, want %s", retried, guestBody)
+	}
+	// another guest guessing the key doesn't get the lookup code, the request runs and its captcha is spent
+	if other := c.do(call{method: "POST", path: "/guest-reservations", header: guestKey, status: 400,
+		remoteAddr: "203.0.113.9:4711", body: guestBooking}); bytes.Contains(other, []byte("lookup_code")) {
+		t.Fatalf("replayed to another client: %s", other)
+	}
+	guestPath := "/guest-reservations/" + guest["lookup_code"].(string)
+	c.do(call{method: "GET", path: guestPath, status: 200})
+	cancelKey := map[string]string{"Idempotency-Key": "guest-cancel-1"}
+	c.do(call{method: "DELETE", path: guestPath, header: cancelKey, status: 204})
+	c.do(call{method: "DELETE", path: guestPath, header: cancelKey, status: 204,
+		wantHeader: map[string]string{"Idempotent-Replayed": "true"}})
+	c.do(call{method: "GET", path: guestPath, status: 404})
+
+	aliceGuest := decode[map[string]any](t, c.do(call{method: "POST", path: "/guest-reservations", status: 201,
+		body: map[string]any{"name": "Alice", "email": "alice@example.com", "showtime_id": showtimeID,
+			"seat_id": 4, "captcha": solveCaptcha()}}))
+	claim := decode[map[string]any](t, c.do(call{method: "POST", path: "/reservations/claim", token: adminToken,
+		status: 200}))
+	if claim["claimed"] != float64(1) {
+		t.Fatalf("claimed %v, want 1", claim["claimed"])
+	}
+	// the lookup code stops working once the account owns the reservation
+	claimedPath := "/guest-reservations/" + aliceGuest["lookup_code"].(string)
+	c.do(call{method: "GET", path: claimedPath, status: 404})
+	c.do(call{method: "DELETE", path: claimedPath, status: 404})
+
+	// waiting rooms
+	c.do(call{method: "POST", path: "/waiting-rooms", token: bobToken, status: 403,
+		body: map[string]any{"showtime_id": showtimeID, "admit_per_minute": 60}})
+	c.do(call{method: "POST", path: "/waiting-rooms", token: adminToken, status: 400,
+		body: map[string]any{"showtime_id": showtimeID, "movie_id": movieID, "admit_per_minute": 60}})
+	room := decode[map[string]any](t, c.do(call{method: "POST", path: "/waiting-rooms", token: adminToken,
+		status: 201, body: map[string]any{"showtime_id": showtimeID, "admit_per_minute": 60}}))
+	roomPath := fmt.Sprintf("/waiting-rooms/%d", int(room["id"].(float64)))
+	body = c.do(call{method: "POST", path: "/waiting-rooms", token: adminToken, status: 409,
+		body: map[string]any{"showtime_id": showtimeID, "admit_per_minute": 120}})
+	if code := errorCode(t, body); code != service.CodeAlreadyExists {
+		t.Fatalf("second room of the showtime: code %s", code)
+	}
+	c.do(call{method: "GET", path: "/waiting-rooms", status: 200})
+	c.do(call{method: "GET", path: roomPath, status: 200})
+	guestBooking = map[string]any{"name": "Carol", "email": "carol@example.com", "showtime_id": showtimeID,
+		"seat_id": 5}
+	body = c.do(call{method: "POST", path: "/guest-reservations", status: 403, body: guestBooking})
+	if code := errorCode(t, body); code != service.CodeAdmissionRequired {
+		t.Fatalf("booking without admission: code %s", code)
+	}
+	guestBooking["admission_token"] = "forged"
+	body = c.do(call{method: "POST", path: "/guest-reservations", status: 403, body: guestBooking})
+	if code := errorCode(t, body); code != service.CodeAdmissionInvalid {
+		t.Fatalf("booking with a forged admission: code %s", code)
+	}
+	ticket := decode[map[string]any](t, c.do(call{method: "POST", path: roomPath + "/tickets", status: 201}))
+	ticket = decode[map[string]any](t, c.do(call{method: "GET", path: roomPath + "/tickets/" + ticket["ticket"].(string),
+		status: 200}))
+	if ticket["admitted"] != true {
+		t.Fatalf("ticket of an empty room not admitted: %v", ticket)
+	}
+	c.do(call{method: "GET", path: roomPath + "/tickets/user-1", status: 403})
+	// a client gets a few anonymous tickets per room
+	for range 5 {
+		c.do(call{method: "POST", path: roomPath + "/tickets", remoteAddr: "198.51.100.7:1234", status: 201})
+	}
+	body = c.do(call{method: "POST", path: roomPath + "/tickets", remoteAddr: "198.51.100.7:1234", status: 429})
+	if code := errorCode(t, body); code != service.CodeRateLimited {
+		t.Fatalf("too many anonymous tickets: code %s", code)
+	}
+	guestBooking["admission_token"] = ticket["admission_token"]
+	guestBooking["captcha"] = solveCaptcha()
+	c.do(call{method: "POST", path: "/guest-reservations", status: 201, body: guestBooking})
+	// the admission is used up by the booking
+	guestBooking["email"] = "dave@example.com"
+	guestBooking["seat_id"] = 6
+	guestBooking["captcha"] = solveCaptcha()
+	body = c.do(call{method: "POST", path: "/guest-reservations", status: 403, body: guestBooking})
+	if code := errorCode(t, body); code != service.CodeAdmissionInvalid {
+		t.Fatalf("booking again with a used admission: code %s", code)
+	}
+	c.do(call{method: "DELETE", path: roomPath, token: bobToken, status: 403})
+	c.do(call{method: "DELETE", path: roomPath, token: adminToken, status: 204})
+	c.do(call{method: "GET", path: roomPath, status: 404})
+
+	var missing []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !c.covered[method+" "+path] {
+				missing = append(missing, method+" "+path)
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("operations not exercised: %v", missing)
+	}
+}
+
+// TestRateLimit exhausts the bucket of the anonymous reads
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := loadSpec(t)
+	specRouter, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("spec router: %v", err)
+	}
+	a, _ := newTestApp(t)
+	a.RateLimits = map[string]service.RateLimit{
+		"read":        {Requests: 1, Per: time.Minute, Burst: 2},
+		"credentials": {Requests: 1, Per: time.Minute, Burst: 3},
+	}
+	c := &contractClient{
+		t:       t,
+		handler: router.New(a),
+		spec:    specRouter,
+		covered: map[string]bool{},
+	}
+
+	c.do(call{method: "GET", path: "/movies", status: 200,
+		wantHeader: map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1"}})
+	c.do(call{method: "GET", path: "/movies", status: 200,
+		wantHeader: map[string]string{"RateLimit-Remaining": "0", "RateLimit-Policy": "1;w=60;burst=2"}})
+	body := c.do(call{method: "GET", path: "/movies", status: 429,
+		wantHeader: map[string]string{"RateLimit-Remaining": "0", "Retry-After": "60"}})
+	if code := errorCode(t, body); code != service.CodeRateLimited {
+		t.Fatalf("over the limit: code %s", code)
+	}
+	// other classes have their own buckets
+	c.do(call{method: "GET", path: "/captcha", status: 200})
+
+	// guessed credentials are limited by client IP before they are checked
+	for range 3 {
+		c.do(call{method: "GET", path: "/auth/me", token: "guessed-token", status: 401, remoteAddr: "203.0.113.9:4000"})
+	}
+	body = c.do(call{method: "GET", path: "/auth/me", token: "guessed-token", status: 429,
+		remoteAddr: "203.0.113.9:4000"})
+	if code := errorCode(t, body); code != service.CodeRateLimited {
+		t.Fatalf("guessing tokens: code %s", code)
+	}
+	c.do(call{method: "GET", path: "/api-keys", header: map[string]string{"X-API-Key": "guessed-key"}, status: 429,
+		remoteAddr: "203.0.113.9:4000"})
+	c.do(call{method: "GET", path: "/auth/me", token: "guessed-token", status: 401, remoteAddr: "203.0.113.10:4000"})
+}
+
+var ginParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// TestRoutesMatchSpec makes sure the spec documents exactly the routes of the router
+func TestRoutesMatchSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := loadSpec(t)
+	a, _ := newTestApp(t)
+
+	routes := map[string]bool{}
+	for _, route := range router.New(a).Routes() {
+		routes[route.Method+" "+ginParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+	operations := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			operations[method+" "+path] = true
+		}
+	}
+
+	for route := range routes {
+		if !operations[route] {
+			t.Errorf("route %s is not in the spec", route)
+		}
+	}
+	for operation := range operations {
+		if !routes[operation] {
+			t.Errorf("operation %s has no route", operation)
+		}
+	}
+}
+
+// TestErrorCodesMatchSpec makes sure the ErrorCode enum lists the codes the API can answer
+func TestErrorCodesMatchSpec(t *testing.T) {
+	doc := loadSpec(t)
+	schema := doc.Components.Schemas["ErrorCode"].Value
+
+	documented := map[string]bool{}
+	for _, value := range schema.Enum {
+		documented[value.(string)] = true
+	}
+	for code := range httperror.Codes() {
+		if !documented[string(code)] {
+			t.Errorf("error code %s is not in the spec", code)
+		}
+		delete(documented, string(code))
+	}
+	for code := range documented {
+		t.Errorf("spec documents the unknown error code %s", code)
+	}
+}
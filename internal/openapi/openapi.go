@@ -0,0 +1,19 @@
+// Package openapi holds the OpenAPI 3 document of the HTTP API.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spec is the OpenAPI document in YAML
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// Handler serves the document
+func Handler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml", Spec)
+}
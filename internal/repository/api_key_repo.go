@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type APIKeyRepo interface {
+	WithTx(tx *gorm.DB) APIKeyRepo
+	Create(key *model.APIKey) error
+	GetByID(id uint) (*model.APIKey, error)
+	GetByPrefix(prefix string) (*model.APIKey, error)
+	GetAll() ([]model.APIKey, error)
+	Revoke(id uint, at time.Time) error
+	UpdateLastUsed(id uint, at time.Time) error
+}
+
+type apiKeyRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ APIKeyRepo = (*apiKeyRepoGorm)(nil)
+
+func NewAPIKeyRepoGorm(db *gorm.DB) *apiKeyRepoGorm {
+	return &apiKeyRepoGorm{
+		db: db,
+	}
+}
+
+func (r *apiKeyRepoGorm) WithTx(tx *gorm.DB) APIKeyRepo {
+	return &apiKeyRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *apiKeyRepoGorm) Create(key *model.APIKey) error {
+	ctx := context.Background()
+	if err := gorm.G[model.APIKey](r.db).Create(ctx, key); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *apiKeyRepoGorm) GetByID(id uint) (*model.APIKey, error) {
+	ctx := context.Background()
+	key, err := gorm.G[model.APIKey](r.db).Where(&model.APIKey{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepoGorm) GetByPrefix(prefix string) (*model.APIKey, error) {
+	ctx := context.Background()
+	key, err := gorm.G[model.APIKey](r.db).Where(&model.APIKey{Prefix: prefix}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepoGorm) GetAll() ([]model.APIKey, error) {
+	ctx := context.Background()
+	keys, err := gorm.G[model.APIKey](r.db).Order("id").Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke returns gorm.ErrRecordNotFound if there is no such key not revoked yet
+func (r *apiKeyRepoGorm) Revoke(id uint, at time.Time) error {
+	ctx := context.Background()
+	rows, err := gorm.G[model.APIKey](r.db).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update(ctx, "revoked_at", at)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepoGorm) UpdateLastUsed(id uint, at time.Time) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.APIKey](r.db).Where(&model.APIKey{ID: id}).Update(ctx, "last_used_at", at)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type CinemaRepo interface {
+	WithTx(tx *gorm.DB) CinemaRepo
+	Create(cinema *model.Cinema) error
+	GetByID(id uint) (*model.Cinema, error)
+	GetByName(name string) (*model.Cinema, error)
+	DeleteByID(id uint) error
+	ListAll() ([]model.Cinema, error)
+	Update(cinema *model.Cinema) error
+}
+
+type cinemaRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ CinemaRepo = (*cinemaRepoGorm)(nil)
+
+func NewCinemaRepoGorm(db *gorm.DB) *cinemaRepoGorm {
+	return &cinemaRepoGorm{
+		db: db,
+	}
+}
+
+func (r *cinemaRepoGorm) WithTx(tx *gorm.DB) CinemaRepo {
+	return &cinemaRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *cinemaRepoGorm) Create(cinema *model.Cinema) error {
+	ctx := context.Background()
+	if err := gorm.G[model.Cinema](r.db).Create(ctx, cinema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cinemaRepoGorm) GetByID(id uint) (*model.Cinema, error) {
+	ctx := context.Background()
+	cinema, err := gorm.G[model.Cinema](r.db).Where(&model.Cinema{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &cinema, nil
+}
+
+func (r *cinemaRepoGorm) GetByName(name string) (*model.Cinema, error) {
+	ctx := context.Background()
+	cinema, err := gorm.G[model.Cinema](r.db).Where(&model.Cinema{Name: name}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &cinema, nil
+}
+
+func (r *cinemaRepoGorm) DeleteByID(id uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.Cinema](r.db).Where(&model.Cinema{ID: id}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *cinemaRepoGorm) ListAll() ([]model.Cinema, error) {
+	ctx := context.Background()
+	cinemas, err := gorm.G[model.Cinema](r.db).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cinemas, nil
+}
+
+// before use Update, please confirm the existance of the cinema
+func (r *cinemaRepoGorm) Update(cinema *model.Cinema) error {
+	ctx := context.Background()
+	if _, err := gorm.G[model.Cinema](r.db).
+		Where(&model.Cinema{ID: cinema.ID}).
+		Select("*").Omit("id").
+		Updates(ctx, *cinema); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "errors"
+
+// ErrVersionConflict is returned by conditional updates when the stored
+// version of the record is not the one the caller read.
+var ErrVersionConflict = errors.New("record version conflict")
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type HallMaintenanceRepo interface {
+	WithTx(tx *gorm.DB) HallMaintenanceRepo
+	Create(maintenance *model.HallMaintenance) error
+	GetByID(id uint) (*model.HallMaintenance, error)
+	DeleteByID(id uint) error
+	GetByHallID(hallID uint) ([]model.HallMaintenance, error)
+	GetOverlapping(hallID uint, startAt, endAt time.Time) ([]model.HallMaintenance, error)
+}
+
+type hallMaintenanceRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ HallMaintenanceRepo = (*hallMaintenanceRepoGorm)(nil)
+
+func NewHallMaintenanceRepoGorm(db *gorm.DB) *hallMaintenanceRepoGorm {
+	return &hallMaintenanceRepoGorm{
+		db: db,
+	}
+}
+
+func (r *hallMaintenanceRepoGorm) WithTx(tx *gorm.DB) HallMaintenanceRepo {
+	return &hallMaintenanceRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *hallMaintenanceRepoGorm) Create(maintenance *model.HallMaintenance) error {
+	ctx := context.Background()
+	if err := gorm.G[model.HallMaintenance](r.db).Create(ctx, maintenance); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *hallMaintenanceRepoGorm) GetByID(id uint) (*model.HallMaintenance, error) {
+	ctx := context.Background()
+	maintenance, err := gorm.G[model.HallMaintenance](r.db).Where(&model.HallMaintenance{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &maintenance, nil
+}
+
+func (r *hallMaintenanceRepoGorm) DeleteByID(id uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.HallMaintenance](r.db).Where(&model.HallMaintenance{ID: id}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *hallMaintenanceRepoGorm) GetByHallID(hallID uint) ([]model.HallMaintenance, error) {
+	ctx := context.Background()
+	maintenances, err := gorm.G[model.HallMaintenance](r.db).Where(&model.HallMaintenance{HallID: hallID}).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return maintenances, nil
+}
+
+// GetOverlapping returns the maintenance windows of the hall overlapping [startAt, endAt)
+func (r *hallMaintenanceRepoGorm) GetOverlapping(hallID uint, startAt, endAt time.Time) ([]model.HallMaintenance, error) {
+	ctx := context.Background()
+	maintenances, err := gorm.G[model.HallMaintenance](r.db).
+		Where("hall_id = ? AND start_at < ? AND end_at > ?", hallID, endAt, startAt).
+		Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return maintenances, nil
+}
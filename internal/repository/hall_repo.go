@@ -5,16 +5,19 @@ import (
 
 	"github.com/qs-lzh/movie-reservation/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type HallRepo interface {
 	WithTx(tx *gorm.DB) HallRepo
 	Create(hall *model.Hall) error
 	GetByID(id uint) (*model.Hall, error)
-	GetByName(name string) (*model.Hall, error)
+	GetByName(cinemaID uint, name string) (*model.Hall, error)
+	GetByCinemaID(cinemaID uint) ([]model.Hall, error)
 	DeleteByID(id uint) error
 	ListAll() ([]model.Hall, error)
 	Update(*model.Hall) error
+	Patch(id uint, version uint, patch model.HallPatch) error
 }
 
 type hallRepoGorm struct {
@@ -53,15 +56,25 @@ func (r *hallRepoGorm) GetByID(id uint) (*model.Hall, error) {
 	return &hall, nil
 }
 
-func (r *hallRepoGorm) GetByName(name string) (*model.Hall, error) {
+// GetByName finds the hall by name within the cinema
+func (r *hallRepoGorm) GetByName(cinemaID uint, name string) (*model.Hall, error) {
 	ctx := context.Background()
-	hall, err := gorm.G[model.Hall](r.db).Where(&model.Hall{Name: name}).First(ctx)
+	hall, err := gorm.G[model.Hall](r.db).Where(&model.Hall{CinemaID: cinemaID, Name: name}).First(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return &hall, nil
 }
 
+func (r *hallRepoGorm) GetByCinemaID(cinemaID uint) ([]model.Hall, error) {
+	ctx := context.Background()
+	halls, err := gorm.G[model.Hall](r.db).Where(&model.Hall{CinemaID: cinemaID}).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return halls, nil
+}
+
 func (r *hallRepoGorm) DeleteByID(id uint) error {
 	ctx := context.Background()
 	_, err := gorm.G[model.Hall](r.db).Where(&model.Hall{ID: id}).Delete(ctx)
@@ -81,10 +94,57 @@ func (r *hallRepoGorm) ListAll() ([]model.Hall, error) {
 }
 
 // before use Update, please confirm the existance of the hall
+//
+// Update writes every column of hall only if the stored version still equals
+// hall.Version, otherwise ErrVersionConflict is returned.
+// On success hall.Version is set to the new version.
 func (r *hallRepoGorm) Update(hall *model.Hall) error {
 	ctx := context.Background()
-	if _, err := gorm.G[model.Hall](r.db).Updates(ctx, *hall); err != nil {
+	updated := *hall
+	updated.Version = hall.Version + 1
+	rows, err := gorm.G[model.Hall](r.db).
+		Where("id = ? AND version = ?", hall.ID, hall.Version).
+		Select("*").Omit("id").
+		Updates(ctx, updated)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrVersionConflict
+	}
+	hall.Version = updated.Version
+	return nil
+}
+
+// Patch sets only the non-nil fields of patch, zero values included,
+// if the stored version still equals version.
+func (r *hallRepoGorm) Patch(id uint, version uint, patch model.HallPatch) error {
+	ctx := context.Background()
+	assignments := []clause.Assigner{
+		clause.Assignment{Column: clause.Column{Name: "version"}, Value: gorm.Expr("version + 1")},
+	}
+	if patch.Name != nil {
+		assignments = append(assignments, clause.Assignment{Column: clause.Column{Name: "name"}, Value: *patch.Name})
+	}
+	if patch.Layout != nil {
+		derived := model.Hall{Layout: *patch.Layout}
+		derived.ApplyLayout()
+		assignments = append(assignments,
+			clause.Assignment{Column: clause.Column{Name: "layout"}, Value: derived.Layout},
+			clause.Assignment{Column: clause.Column{Name: "seat_count"}, Value: derived.SeatCount},
+			clause.Assignment{Column: clause.Column{Name: "rows"}, Value: derived.Rows},
+			clause.Assignment{Column: clause.Column{Name: "cols"}, Value: derived.Cols},
+		)
+	}
+	rows, err := gorm.G[model.Hall](r.db).
+		Where("id = ? AND version = ?", id, version).
+		Set(assignments...).
+		Update(ctx)
+	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return ErrVersionConflict
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/qs-lzh/movie-reservation/internal/model"
 )
@@ -15,7 +16,9 @@ type MovieRepo interface {
 	GetByTitle(title string) (*model.Movie, error)
 	DeleteByID(id uint) error
 	ListAll() ([]model.Movie, error)
-	Update(model.Movie) error
+	GetByCinemaID(cinemaID uint) ([]model.Movie, error)
+	Update(*model.Movie) error
+	Patch(id uint, version uint, patch model.MoviePatch) error
 }
 
 type movieRepoGorm struct {
@@ -80,12 +83,66 @@ func (r *movieRepoGorm) ListAll() ([]model.Movie, error) {
 	return movies, nil
 }
 
+// GetByCinemaID returns the movies having a showtime in the cinema
+func (r *movieRepoGorm) GetByCinemaID(cinemaID uint) ([]model.Movie, error) {
+	ctx := context.Background()
+	hallIDs := r.db.Model(&model.Hall{}).Select("id").Where("cinema_id = ?", cinemaID)
+	movieIDs := r.db.Model(&model.Showtime{}).Select("movie_id").Where("hall_id IN (?)", hallIDs)
+	movies, err := gorm.G[model.Movie](r.db).Where("id IN (?)", movieIDs).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
 // before use Update, please confirm the existance of the movie
-func (r *movieRepoGorm) Update(movie model.Movie) error {
+//
+// Update writes every column of movie only if the stored version still equals
+// movie.Version, otherwise ErrVersionConflict is returned.
+// On success movie.Version is set to the new version.
+func (r *movieRepoGorm) Update(movie *model.Movie) error {
 	ctx := context.Background()
-	if _, err := gorm.G[model.Movie](r.db).Updates(ctx, movie); err != nil {
+	updated := *movie
+	updated.Version = movie.Version + 1
+	rows, err := gorm.G[model.Movie](r.db).
+		Where("id = ? AND version = ?", movie.ID, movie.Version).
+		Select("*").Omit("id").
+		Updates(ctx, updated)
+	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return ErrVersionConflict
+	}
+	movie.Version = updated.Version
+	return nil
+}
 
+// Patch sets only the non-nil fields of patch, zero values included,
+// if the stored version still equals version.
+func (r *movieRepoGorm) Patch(id uint, version uint, patch model.MoviePatch) error {
+	ctx := context.Background()
+	assignments := []clause.Assigner{
+		clause.Assignment{Column: clause.Column{Name: "version"}, Value: gorm.Expr("version + 1")},
+	}
+	if patch.Title != nil {
+		assignments = append(assignments, clause.Assignment{Column: clause.Column{Name: "title"}, Value: *patch.Title})
+	}
+	if patch.Description != nil {
+		assignments = append(assignments, clause.Assignment{Column: clause.Column{Name: "description"}, Value: *patch.Description})
+	}
+	if patch.RuntimeMinutes != nil {
+		assignments = append(assignments, clause.Assignment{Column: clause.Column{Name: "runtime_minutes"}, Value: *patch.RuntimeMinutes})
+	}
+	rows, err := gorm.G[model.Movie](r.db).
+		Where("id = ? AND version = ?", id, version).
+		Set(assignments...).
+		Update(ctx)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrVersionConflict
+	}
 	return nil
 }
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type RecoveryCodeRepo interface {
+	WithTx(tx *gorm.DB) RecoveryCodeRepo
+	CreateBatch(codes []model.RecoveryCode) error
+	// Use marks the unused code of the user as used,
+	// it returns gorm.ErrRecordNotFound if there is no such code
+	Use(userID uint, codeHash string, at time.Time) error
+	CountUnused(userID uint) (int64, error)
+	DeleteByUserID(userID uint) error
+}
+
+type recoveryCodeRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ RecoveryCodeRepo = (*recoveryCodeRepoGorm)(nil)
+
+func NewRecoveryCodeRepoGorm(db *gorm.DB) *recoveryCodeRepoGorm {
+	return &recoveryCodeRepoGorm{
+		db: db,
+	}
+}
+
+func (r *recoveryCodeRepoGorm) WithTx(tx *gorm.DB) RecoveryCodeRepo {
+	return &recoveryCodeRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *recoveryCodeRepoGorm) CreateBatch(codes []model.RecoveryCode) error {
+	ctx := context.Background()
+	if err := gorm.G[model.RecoveryCode](r.db).CreateInBatches(ctx, &codes, len(codes)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepoGorm) Use(userID uint, codeHash string, at time.Time) error {
+	ctx := context.Background()
+	rows, err := gorm.G[model.RecoveryCode](r.db).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update(ctx, "used_at", at)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepoGorm) CountUnused(userID uint) (int64, error) {
+	ctx := context.Background()
+	count, err := gorm.G[model.RecoveryCode](r.db).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(ctx, "*")
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *recoveryCodeRepoGorm) DeleteByUserID(userID uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.RecoveryCode](r.db).Where(&model.RecoveryCode{UserID: userID}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
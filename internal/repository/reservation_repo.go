@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -15,6 +16,10 @@ type ReservationRepo interface {
 	DeleteByID(id uint) error
 	GetByUserID(userID uint) ([]model.Reservation, error)
 	GetByShowtimeID(showtimeID uint) ([]model.Reservation, error)
+	GetUpcomingByUserID(userID uint, after time.Time) ([]model.Reservation, error)
+	CountByUserIDSince(userID uint, since time.Time) (int64, error)
+	GetGuestByEmail(email string) ([]model.Reservation, error)
+	ClaimGuestByEmail(email string, userID uint) (int, error)
 }
 
 type reservationRepoGorm struct {
@@ -63,7 +68,7 @@ func (r *reservationRepoGorm) DeleteByID(id uint) error {
 
 func (r *reservationRepoGorm) GetByUserID(userID uint) ([]model.Reservation, error) {
 	ctx := context.Background()
-	reservations, err := gorm.G[model.Reservation](r.db).Where(&model.Reservation{UserID: userID}).Find(ctx)
+	reservations, err := gorm.G[model.Reservation](r.db).Where(&model.Reservation{UserID: &userID}).Find(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -78,3 +83,55 @@ func (r *reservationRepoGorm) GetByShowtimeID(showtimeID uint) ([]model.Reservat
 	}
 	return reservations, nil
 }
+
+// GetUpcomingByUserID returns the reservations of the user
+// for showtimes starting after the given time
+func (r *reservationRepoGorm) GetUpcomingByUserID(userID uint, after time.Time) ([]model.Reservation, error) {
+	ctx := context.Background()
+	reservations, err := gorm.G[model.Reservation](r.db).
+		Where(&model.Reservation{UserID: &userID}).
+		Where("showtime_id IN (?)", r.db.Model(&model.Showtime{}).Select("id").Where("start_at > ?", after)).
+		Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// CountByUserIDSince counts the reservations the user made since the given time
+func (r *reservationRepoGorm) CountByUserIDSince(userID uint, since time.Time) (int64, error) {
+	ctx := context.Background()
+	count, err := gorm.G[model.Reservation](r.db).
+		Where(&model.Reservation{UserID: &userID}).
+		Where("created_at >= ?", since).
+		Count(ctx, "*")
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetGuestByEmail returns the unclaimed guest reservations made with the email
+func (r *reservationRepoGorm) GetGuestByEmail(email string) ([]model.Reservation, error) {
+	ctx := context.Background()
+	reservations, err := gorm.G[model.Reservation](r.db).
+		Where("user_id IS NULL AND guest_email = ?", email).
+		Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// ClaimGuestByEmail gives the unclaimed guest reservations made with the email to the user
+// and returns how many were claimed
+func (r *reservationRepoGorm) ClaimGuestByEmail(email string, userID uint) (int, error) {
+	ctx := context.Background()
+	rows, err := gorm.G[model.Reservation](r.db).
+		Where("user_id IS NULL AND guest_email = ?", email).
+		Update(ctx, "user_id", userID)
+	if err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
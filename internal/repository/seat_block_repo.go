@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type SeatBlockRepo interface {
+	WithTx(tx *gorm.DB) SeatBlockRepo
+	Create(block *model.SeatBlock) error
+	GetByID(id uint) (*model.SeatBlock, error)
+	DeleteByID(id uint) error
+	GetByHallID(hallID uint) ([]model.SeatBlock, error)
+	GetActive(hallID, showtimeID uint, at time.Time) ([]model.SeatBlock, error)
+}
+
+type seatBlockRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ SeatBlockRepo = (*seatBlockRepoGorm)(nil)
+
+func NewSeatBlockRepoGorm(db *gorm.DB) *seatBlockRepoGorm {
+	return &seatBlockRepoGorm{
+		db: db,
+	}
+}
+
+func (r *seatBlockRepoGorm) WithTx(tx *gorm.DB) SeatBlockRepo {
+	return &seatBlockRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *seatBlockRepoGorm) Create(block *model.SeatBlock) error {
+	ctx := context.Background()
+	if err := gorm.G[model.SeatBlock](r.db).Create(ctx, block); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *seatBlockRepoGorm) GetByID(id uint) (*model.SeatBlock, error) {
+	ctx := context.Background()
+	block, err := gorm.G[model.SeatBlock](r.db).Where(&model.SeatBlock{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *seatBlockRepoGorm) DeleteByID(id uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.SeatBlock](r.db).Where(&model.SeatBlock{ID: id}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *seatBlockRepoGorm) GetByHallID(hallID uint) ([]model.SeatBlock, error) {
+	ctx := context.Background()
+	blocks, err := gorm.G[model.SeatBlock](r.db).Where(&model.SeatBlock{HallID: hallID}).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// GetActive returns the blocks of the showtime
+// and the date range blocks of the hall covering the time at
+func (r *seatBlockRepoGorm) GetActive(hallID, showtimeID uint, at time.Time) ([]model.SeatBlock, error) {
+	ctx := context.Background()
+	blocks, err := gorm.G[model.SeatBlock](r.db).
+		Where("showtime_id = ?", showtimeID).
+		Or("hall_id = ? AND showtime_id IS NULL AND start_at <= ? AND end_at > ?", hallID, at, at).
+		Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
@@ -15,6 +15,7 @@ type ShowtimeRepo interface {
 	DeleteByID(id uint) error
 	GetByMovieID(movieID uint) ([]model.Showtime, error)
 	GetByHallID(hallID uint) ([]model.Showtime, error)
+	GetByCinemaID(cinemaID uint) ([]model.Showtime, error)
 	DeleteByMovieID(movieID uint) error
 	ListAll() ([]model.Showtime, error)
 }
@@ -47,7 +48,7 @@ func (r *showtimeRepoGorm) Create(showtime *model.Showtime) error {
 
 func (r *showtimeRepoGorm) GetByID(id uint) (*model.Showtime, error) {
 	ctx := context.Background()
-	showtime, err := gorm.G[model.Showtime](r.db).Where(&model.Showtime{ID: id}).First(ctx)
+	showtime, err := gorm.G[model.Showtime](r.db).Preload("Movie", nil).Where(&model.Showtime{ID: id}).First(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +75,19 @@ func (r *showtimeRepoGorm) GetByMovieID(movieID uint) ([]model.Showtime, error)
 
 func (r *showtimeRepoGorm) GetByHallID(hallID uint) ([]model.Showtime, error) {
 	ctx := context.Background()
-	showtimes, err := gorm.G[model.Showtime](r.db).Where(&model.Showtime{HallID: hallID}).Find(ctx)
+	showtimes, err := gorm.G[model.Showtime](r.db).Preload("Movie", nil).Where(&model.Showtime{HallID: hallID}).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return showtimes, nil
+}
+
+// GetByCinemaID returns the showtimes in every hall of the cinema
+func (r *showtimeRepoGorm) GetByCinemaID(cinemaID uint) ([]model.Showtime, error) {
+	ctx := context.Background()
+	showtimes, err := gorm.G[model.Showtime](r.db).
+		Where("hall_id IN (?)", r.db.Model(&model.Hall{}).Select("id").Where("cinema_id = ?", cinemaID)).
+		Find(ctx)
 	if err != nil {
 		return nil, err
 	}
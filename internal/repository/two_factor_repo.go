@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type TwoFactorRepo interface {
+	WithTx(tx *gorm.DB) TwoFactorRepo
+	Create(twoFactor *model.TwoFactor) error
+	GetByUserID(userID uint) (*model.TwoFactor, error)
+	Update(twoFactor *model.TwoFactor) error
+	// UseStep records the time step of an accepted code,
+	// it returns gorm.ErrRecordNotFound if the step is not after the last one
+	UseStep(userID uint, step int64) error
+	DeleteByUserID(userID uint) error
+}
+
+type twoFactorRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ TwoFactorRepo = (*twoFactorRepoGorm)(nil)
+
+func NewTwoFactorRepoGorm(db *gorm.DB) *twoFactorRepoGorm {
+	return &twoFactorRepoGorm{
+		db: db,
+	}
+}
+
+func (r *twoFactorRepoGorm) WithTx(tx *gorm.DB) TwoFactorRepo {
+	return &twoFactorRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *twoFactorRepoGorm) Create(twoFactor *model.TwoFactor) error {
+	ctx := context.Background()
+	if err := gorm.G[model.TwoFactor](r.db).Create(ctx, twoFactor); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *twoFactorRepoGorm) GetByUserID(userID uint) (*model.TwoFactor, error) {
+	ctx := context.Background()
+	twoFactor, err := gorm.G[model.TwoFactor](r.db).Where(&model.TwoFactor{UserID: userID}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepoGorm) Update(twoFactor *model.TwoFactor) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.TwoFactor](r.db).
+		Where("user_id = ?", twoFactor.UserID).
+		Select("*").Omit("user_id", "created_at").
+		Updates(ctx, *twoFactor)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *twoFactorRepoGorm) UseStep(userID uint, step int64) error {
+	ctx := context.Background()
+	rows, err := gorm.G[model.TwoFactor](r.db).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update(ctx, "last_used_step", step)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *twoFactorRepoGorm) DeleteByUserID(userID uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.TwoFactor](r.db).Where(&model.TwoFactor{UserID: userID}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type UserIdentityRepo interface {
+	WithTx(tx *gorm.DB) UserIdentityRepo
+	Create(identity *model.UserIdentity) error
+	GetByID(id uint) (*model.UserIdentity, error)
+	GetBySubject(provider, subject string) (*model.UserIdentity, error)
+	GetByUserID(userID uint) ([]model.UserIdentity, error)
+	DeleteByID(id uint) error
+}
+
+type userIdentityRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ UserIdentityRepo = (*userIdentityRepoGorm)(nil)
+
+func NewUserIdentityRepoGorm(db *gorm.DB) *userIdentityRepoGorm {
+	return &userIdentityRepoGorm{
+		db: db,
+	}
+}
+
+func (r *userIdentityRepoGorm) WithTx(tx *gorm.DB) UserIdentityRepo {
+	return &userIdentityRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *userIdentityRepoGorm) Create(identity *model.UserIdentity) error {
+	ctx := context.Background()
+	if err := gorm.G[model.UserIdentity](r.db).Create(ctx, identity); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *userIdentityRepoGorm) GetByID(id uint) (*model.UserIdentity, error) {
+	ctx := context.Background()
+	identity, err := gorm.G[model.UserIdentity](r.db).Where(&model.UserIdentity{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepoGorm) GetBySubject(provider, subject string) (*model.UserIdentity, error) {
+	ctx := context.Background()
+	identity, err := gorm.G[model.UserIdentity](r.db).
+		Where(&model.UserIdentity{Provider: provider, Subject: subject}).
+		First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepoGorm) GetByUserID(userID uint) ([]model.UserIdentity, error) {
+	ctx := context.Background()
+	identities, err := gorm.G[model.UserIdentity](r.db).Where(&model.UserIdentity{UserID: userID}).Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *userIdentityRepoGorm) DeleteByID(id uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.UserIdentity](r.db).Where(&model.UserIdentity{ID: id}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
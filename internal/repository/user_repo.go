@@ -11,7 +11,12 @@ type UserRepo interface {
 	WithTx(tx *gorm.DB) UserRepo
 	Create(user *model.User) error
 	DeleteByName(name string) error
+	DeleteByID(id uint) error
 	GetByName(name string) (*model.User, error)
+	GetByID(id uint) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
+	Update(user *model.User) error
+	CountByRole(role model.UserRole) (int64, error)
 }
 
 type userRepoGorm struct {
@@ -50,6 +55,16 @@ func (r *userRepoGorm) DeleteByName(name string) error {
 	return nil
 }
 
+// DeleteByID soft deletes the user
+func (r *userRepoGorm) DeleteByID(id uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.User](r.db).Where(&model.User{ID: id}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *userRepoGorm) GetByName(name string) (*model.User, error) {
 	ctx := context.Background()
 	user, err := gorm.G[model.User](r.db).Where(model.User{Name: name}).First(ctx)
@@ -58,3 +73,38 @@ func (r *userRepoGorm) GetByName(name string) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *userRepoGorm) GetByID(id uint) (*model.User, error) {
+	ctx := context.Background()
+	user, err := gorm.G[model.User](r.db).Where(&model.User{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepoGorm) GetByEmail(email string) (*model.User, error) {
+	ctx := context.Background()
+	user, err := gorm.G[model.User](r.db).Where(&model.User{Email: email}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// before use Update, please confirm the existance of the user
+func (r *userRepoGorm) Update(user *model.User) error {
+	ctx := context.Background()
+	if _, err := gorm.G[model.User](r.db).
+		Where(&model.User{ID: user.ID}).
+		Select("*").Omit("id", "deleted_at").
+		Updates(ctx, *user); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *userRepoGorm) CountByRole(role model.UserRole) (int64, error) {
+	ctx := context.Background()
+	return gorm.G[model.User](r.db).Where(&model.User{Role: role}).Count(ctx, "id")
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type UserTokenRepo interface {
+	WithTx(tx *gorm.DB) UserTokenRepo
+	Create(token *model.UserToken) error
+	GetByHash(purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error)
+	MarkUsed(id uint, at time.Time) error
+	DeleteUnused(userID uint, purpose model.TokenPurpose) error
+}
+
+type userTokenRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ UserTokenRepo = (*userTokenRepoGorm)(nil)
+
+func NewUserTokenRepoGorm(db *gorm.DB) *userTokenRepoGorm {
+	return &userTokenRepoGorm{
+		db: db,
+	}
+}
+
+func (r *userTokenRepoGorm) WithTx(tx *gorm.DB) UserTokenRepo {
+	return &userTokenRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *userTokenRepoGorm) Create(token *model.UserToken) error {
+	ctx := context.Background()
+	if err := gorm.G[model.UserToken](r.db).Create(ctx, token); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *userTokenRepoGorm) GetByHash(purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error) {
+	ctx := context.Background()
+	token, err := gorm.G[model.UserToken](r.db).
+		Where(&model.UserToken{Purpose: purpose, TokenHash: tokenHash}).
+		First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed returns gorm.ErrRecordNotFound if the token was already used,
+// so of two concurrent uses only one succeeds
+func (r *userTokenRepoGorm) MarkUsed(id uint, at time.Time) error {
+	ctx := context.Background()
+	rows, err := gorm.G[model.UserToken](r.db).
+		Where("id = ? AND used_at IS NULL", id).
+		Update(ctx, "used_at", at)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUnused drops the unused tokens of the user for the purpose,
+// it is called before a new one is issued so only the latest works
+func (r *userTokenRepoGorm) DeleteUnused(userID uint, purpose model.TokenPurpose) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.UserToken](r.db).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
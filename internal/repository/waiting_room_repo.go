@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+type WaitingRoomRepo interface {
+	WithTx(tx *gorm.DB) WaitingRoomRepo
+	Create(room *model.WaitingRoom) error
+	GetByID(id uint) (*model.WaitingRoom, error)
+	GetAll() ([]model.WaitingRoom, error)
+	DeleteByID(id uint) error
+	GetForShowtime(showtimeID, movieID uint) ([]model.WaitingRoom, error)
+	// CreateUse records the use of an admission and reports whether
+	// it was recorded, false if the admission was used already
+	CreateUse(use *model.AdmissionUse) (bool, error)
+	DeleteUses(roomID uint) error
+}
+
+type waitingRoomRepoGorm struct {
+	db *gorm.DB
+}
+
+var _ WaitingRoomRepo = (*waitingRoomRepoGorm)(nil)
+
+func NewWaitingRoomRepoGorm(db *gorm.DB) *waitingRoomRepoGorm {
+	return &waitingRoomRepoGorm{
+		db: db,
+	}
+}
+
+func (r *waitingRoomRepoGorm) WithTx(tx *gorm.DB) WaitingRoomRepo {
+	return &waitingRoomRepoGorm{
+		db: tx,
+	}
+}
+
+func (r *waitingRoomRepoGorm) Create(room *model.WaitingRoom) error {
+	ctx := context.Background()
+	if err := gorm.G[model.WaitingRoom](r.db).Create(ctx, room); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *waitingRoomRepoGorm) GetByID(id uint) (*model.WaitingRoom, error) {
+	ctx := context.Background()
+	room, err := gorm.G[model.WaitingRoom](r.db).Where(&model.WaitingRoom{ID: id}).First(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (r *waitingRoomRepoGorm) GetAll() ([]model.WaitingRoom, error) {
+	ctx := context.Background()
+	rooms, err := gorm.G[model.WaitingRoom](r.db).Order("id").Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *waitingRoomRepoGorm) DeleteByID(id uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.WaitingRoom](r.db).Where(&model.WaitingRoom{ID: id}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetForShowtime returns the rooms of the showtime and those of its movie
+func (r *waitingRoomRepoGorm) GetForShowtime(showtimeID, movieID uint) ([]model.WaitingRoom, error) {
+	ctx := context.Background()
+	rooms, err := gorm.G[model.WaitingRoom](r.db).
+		Where("showtime_id = ? OR movie_id = ?", showtimeID, movieID).
+		Order("id").
+		Find(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *waitingRoomRepoGorm) CreateUse(use *model.AdmissionUse) (bool, error) {
+	ctx := context.Background()
+	result := gorm.WithResult()
+	// the unique index decides between concurrent bookings with the same admission
+	if err := gorm.G[model.AdmissionUse](r.db, clause.OnConflict{DoNothing: true}, result).Create(ctx, use); err != nil {
+		return false, err
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *waitingRoomRepoGorm) DeleteUses(roomID uint) error {
+	ctx := context.Background()
+	_, err := gorm.G[model.AdmissionUse](r.db).Where(&model.AdmissionUse{WaitingRoomID: roomID}).Delete(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
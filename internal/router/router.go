@@ -0,0 +1,213 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/qs-lzh/movie-reservation/internal/app"
+	"github.com/qs-lzh/movie-reservation/internal/handler"
+	"github.com/qs-lzh/movie-reservation/internal/middleware"
+	"github.com/qs-lzh/movie-reservation/internal/openapi"
+	"github.com/qs-lzh/movie-reservation/internal/service"
+)
+
+// New builds the REST API, the services check the permissions of the actor,
+// the router only makes sure the routes that act for a user have one
+func New(app *app.App) *gin.Engine {
+	r := gin.New()
+	// the config checked the proxies, and without any the client IP is the remote address
+	if err := r.SetTrustedProxies(app.Config.TrustedProxies); err != nil {
+		panic(err)
+	}
+	r.Use(
+		middleware.Recovery(app.Logger),
+		middleware.ZapLogger(app.Logger),
+		middleware.ErrorLogger(app.Logger),
+		// before Authenticate nobody is known yet, the credentials are limited by client IP,
+		// so guessing tokens and API keys is limited whether they are valid or not
+		handler.RateLimit(app.RateLimiter, app.RateLimits, credentialsClass),
+		handler.Authenticate(app.TokenService, app.APIKeyService),
+		handler.RateLimit(app.RateLimiter, app.RateLimits, routeClass),
+	)
+	signedIn := handler.RequireActor()
+
+	r.GET("/openapi.yaml", openapi.Handler)
+
+	authHandler := handler.NewAuthHandler(app)
+	r.GET("/captcha", authHandler.Captcha)
+	authGroup := r.Group("/auth")
+	{
+		authGroup.POST("/register", authHandler.Register)
+		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/logout-all", signedIn, authHandler.LogoutAll)
+		authGroup.GET("/me", signedIn, authHandler.Me)
+		authGroup.POST("/verify-email/send", signedIn, authHandler.SendEmailVerification)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/password-reset", authHandler.RequestPasswordReset)
+		authGroup.POST("/password-reset/confirm", authHandler.ResetPassword)
+	}
+	// sessions of admins started without a code have the permissions of a user, enough to enroll
+	twoFactorHandler := handler.NewTwoFactorHandler(app)
+	twoFactor := authGroup.Group("/2fa", signedIn)
+	{
+		twoFactor.GET("", twoFactorHandler.Status)
+		twoFactor.POST("/enroll", twoFactorHandler.Enroll)
+		twoFactor.POST("/confirm", twoFactorHandler.Confirm)
+		twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+		twoFactor.POST("/disable", twoFactorHandler.Disable)
+	}
+
+	// signing in at an OpenID Connect provider, the callback is its registered redirect URL
+	oidcHandler := handler.NewOIDCHandler(app)
+	oidc := authGroup.Group("/oidc")
+	{
+		oidc.GET("/providers", oidcHandler.Providers)
+		oidc.GET("/callback", oidcHandler.Callback)
+		oidc.GET("/:provider/login", oidcHandler.Login)
+		oidc.POST("/:provider/link", signedIn, oidcHandler.Link)
+	}
+
+	// users manage their own account, the accounts of others need users:manage
+	userHandler := handler.NewUserHandler(app)
+	users := r.Group("/users", signedIn)
+	{
+		users.DELETE("/:id", userHandler.Delete)
+		users.PUT("/:id/profile", userHandler.UpdateProfile)
+		users.POST("/:id/password", userHandler.ChangePassword)
+		users.PUT("/:id/role", userHandler.AssignRole)
+		users.PUT("/:id/status", userHandler.SetStatus)
+		users.POST("/:id/unlock", userHandler.Unlock)
+		users.DELETE("/:id/2fa", twoFactorHandler.Reset)
+		users.GET("/:id/identities", oidcHandler.ListIdentities)
+		users.DELETE("/:id/identities/:identity_id", oidcHandler.UnlinkIdentity)
+	}
+
+	// keys of partner integrations, sent in the X-API-Key header
+	apiKeyHandler := handler.NewAPIKeyHandler(app)
+	apiKeys := r.Group("/api-keys", signedIn)
+	{
+		apiKeys.GET("", apiKeyHandler.List)
+		apiKeys.POST("", apiKeyHandler.Create)
+		apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
+	}
+
+	// the catalogue reads are cached for the TTL of the first entity, until a change
+	// of one of the entities they are built from, the filters by cinema read halls and showtimes
+	cached := func(entities ...service.CatalogEntity) gin.HandlerFunc {
+		return handler.CacheResponse(app.CatalogCache, app.CacheTTLs[entities[0]], entities...)
+	}
+
+	cinemaHandler := handler.NewCinemaHandler(app)
+	cinemas := r.Group("/cinemas")
+	{
+		cinemas.GET("", cinemaHandler.List)
+		cinemas.GET("/:id", cinemaHandler.Get)
+		cinemas.POST("", signedIn, cinemaHandler.Create)
+		cinemas.PUT("/:id", signedIn, cinemaHandler.Update)
+		cinemas.DELETE("/:id", signedIn, cinemaHandler.Delete)
+	}
+
+	movieHandler := handler.NewMovieHandler(app)
+	movies := r.Group("/movies")
+	{
+		movies.GET("", cached(service.CatalogMovies, service.CatalogShowtimes, service.CatalogHalls),
+			movieHandler.List)
+		movies.GET("/:id", cached(service.CatalogMovies), movieHandler.Get)
+		movies.POST("", signedIn, movieHandler.Create)
+		movies.PUT("/:id", signedIn, movieHandler.Update)
+		movies.PATCH("/:id", signedIn, movieHandler.Patch)
+	}
+
+	hallHandler := handler.NewHallHandler(app)
+	halls := r.Group("/halls")
+	{
+		halls.GET("", cached(service.CatalogHalls), hallHandler.List)
+		halls.GET("/:id", cached(service.CatalogHalls), hallHandler.Get)
+		halls.POST("", signedIn, hallHandler.Create)
+		halls.PUT("/:id", signedIn, hallHandler.Update)
+		halls.PATCH("/:id", signedIn, hallHandler.Patch)
+		halls.DELETE("/:id", signedIn, hallHandler.Delete)
+	}
+
+	// blocked seats and halls under maintenance can't be booked
+	maintenanceHandler := handler.NewMaintenanceHandler(app)
+	halls.GET("/:id/seat-blocks", maintenanceHandler.ListSeatBlocks)
+	halls.GET("/:id/maintenance-windows", maintenanceHandler.ListMaintenanceWindows)
+	r.POST("/seat-blocks", signedIn, maintenanceHandler.BlockSeat)
+	r.DELETE("/seat-blocks/:id", signedIn, maintenanceHandler.UnblockSeat)
+	r.POST("/maintenance-windows", signedIn, maintenanceHandler.ScheduleMaintenance)
+	r.DELETE("/maintenance-windows/:id", signedIn, maintenanceHandler.CancelMaintenance)
+
+	showtimeHandler := handler.NewShowtimeHandler(app)
+	showtimes := r.Group("/showtimes")
+	{
+		showtimes.GET("", cached(service.CatalogShowtimes, service.CatalogHalls), showtimeHandler.List)
+		showtimes.GET("/:id", cached(service.CatalogShowtimes), showtimeHandler.Get)
+		showtimes.GET("/:id/seats", showtimeHandler.Seats)
+		showtimes.GET("/:id/seats/events", showtimeHandler.SeatEvents)
+		showtimes.POST("", signedIn, showtimeHandler.Create)
+	}
+
+	// while a showtime has an open waiting room, booking needs an admission token from it
+	waitingRoomHandler := handler.NewWaitingRoomHandler(app)
+	waitingRooms := r.Group("/waiting-rooms")
+	{
+		waitingRooms.GET("", waitingRoomHandler.List)
+		waitingRooms.GET("/:id", waitingRoomHandler.Get)
+		waitingRooms.POST("", signedIn, waitingRoomHandler.Create)
+		waitingRooms.DELETE("/:id", signedIn, waitingRoomHandler.Delete)
+		waitingRooms.POST("/:id/tickets", waitingRoomHandler.Join)
+		waitingRooms.GET("/:id/tickets/:ticket", waitingRoomHandler.Poll)
+	}
+
+	// retried booking requests with an Idempotency-Key get the response of the first one.
+	// The service doesn't take payments yet, there are no payment endpoints,
+	// when they come they need it as much as the bookings
+	idempotent := handler.Idempotent(app.IdempotencyStore)
+	reservationHandler := handler.NewReservationHandler(app)
+	reservations := r.Group("/reservations", signedIn)
+	{
+		reservations.GET("", reservationHandler.List)
+		reservations.POST("", idempotent, reservationHandler.Create)
+		reservations.POST("/claim", idempotent, reservationHandler.Claim)
+		reservations.GET("/:id", reservationHandler.Get)
+		reservations.DELETE("/:id", idempotent, reservationHandler.Cancel)
+	}
+	guestReservations := r.Group("/guest-reservations")
+	{
+		guestReservations.POST("", idempotent, reservationHandler.CreateGuest)
+		guestReservations.GET("/:code", reservationHandler.GetGuest)
+		guestReservations.DELETE("/:code", idempotent, reservationHandler.CancelGuest)
+	}
+
+	return r
+}
+
+// routeClass groups the routes sharing a rate limit: signing in and the captcha,
+// booking and cancelling seats, and the other reads and writes
+func routeClass(c *gin.Context) string {
+	path := c.FullPath()
+	switch {
+	case path == "/captcha" || strings.HasPrefix(path, "/auth/"):
+		return "auth"
+	case c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead:
+		return "read"
+	case strings.HasPrefix(path, "/reservations") || strings.HasPrefix(path, "/guest-reservations"):
+		return "booking"
+	default:
+		return "write"
+	}
+}
+
+// credentialsClass is the class of the requests carrying an access token or an API key,
+// the others are not limited before they are authenticated
+func credentialsClass(c *gin.Context) string {
+	if c.GetHeader("Authorization") != "" || c.GetHeader("X-API-Key") != "" {
+		return "credentials"
+	}
+	return ""
+}
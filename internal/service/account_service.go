@@ -0,0 +1,229 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/mail"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// lifetime of the tokens sent by email
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+// AccountService verifies email addresses and resets forgotten passwords
+// with single-use tokens sent by email
+type AccountService interface {
+	// SendEmailVerification mails a verification link to the user's address,
+	// earlier verification tokens stop working
+	SendEmailVerification(userID uint) error
+	// VerifyEmail marks the address verified and activates an unverified user
+	VerifyEmail(token string) error
+	// RequestPasswordReset mails a reset link if a user has the address,
+	// it succeeds for unknown addresses too, so they can't be probed
+	RequestPasswordReset(email string) error
+	ResetPasswordWithToken(token, newPassword string) error
+}
+
+type accountService struct {
+	db        *gorm.DB
+	userRepo  repository.UserRepo
+	tokenRepo repository.UserTokenRepo
+	sessions  SessionRevoker
+	mailer    mail.Mailer
+	// links in the emails point there, e.g. https://tickets.example.com
+	publicURL string
+	now       func() time.Time
+}
+
+var _ AccountService = (*accountService)(nil)
+
+func NewAccountService(db *gorm.DB, userRepo repository.UserRepo, tokenRepo repository.UserTokenRepo,
+	sessions SessionRevoker, mailer mail.Mailer, publicURL string) *accountService {
+	return &accountService{
+		db:        db,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		sessions:  sessions,
+		mailer:    mailer,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		now:       time.Now,
+	}
+}
+
+func (s *accountService) SendEmailVerification(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if user.Email == "" {
+		v := &validator{}
+		v.add("email", RuleRequired, "must be set before it can be verified")
+		return v.err()
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	token, err := s.issueToken(user, model.TokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nopen this link to verify your email address:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, s.link("/verify-email", token), emailVerificationTTL),
+	})
+}
+
+func (s *accountService) VerifyEmail(token string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		userToken, user, err := s.consumeTokenTx(tx, model.TokenEmailVerification, token)
+		if err != nil {
+			return err
+		}
+		if user.Email != userToken.Email {
+			return ErrInvalidToken
+		}
+		verifiedAt := s.now()
+		user.EmailVerifiedAt = &verifiedAt
+		if user.Status == model.UserUnverified {
+			user.Status = model.UserActive
+		}
+		return s.userRepo.WithTx(tx).Update(user)
+	})
+}
+
+func (s *accountService) RequestPasswordReset(email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		v := &validator{}
+		v.add("email", RuleRequired, "must not be empty")
+		return v.err()
+	}
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueToken(user, model.TokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nopen this link to choose a new password:\n\n%s\n\n"+
+			"The link expires in %s. If you didn't ask for it, ignore this email.\n",
+			user.Name, s.link("/reset-password", token), passwordResetTTL),
+	})
+}
+
+// ResetPasswordWithToken sets the password and ends every session of the user,
+// the token is only used up if the new password passes the policy
+func (s *accountService) ResetPasswordWithToken(token, newPassword string) error {
+	var userID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		_, user, err := s.consumeTokenTx(tx, model.TokenPasswordReset, token)
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+		return setPasswordTx(s.userRepo.WithTx(tx), user, "new_password", newPassword)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.sessions.BumpGeneration(userID)
+	return err
+}
+
+// issueToken replaces the unused tokens of the purpose with a new one
+// and returns the secret, only its hash is stored
+func (s *accountService) issueToken(user *model.User, purpose model.TokenPurpose, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.tokenRepo.WithTx(tx).DeleteUnused(user.ID, purpose); err != nil {
+			return err
+		}
+		return s.tokenRepo.WithTx(tx).Create(&model.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: hashToken(token),
+			Email:     user.Email,
+			ExpiresAt: s.now().Add(ttl),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeTokenTx marks the token used and returns it with its user,
+// unknown, used and expired tokens all give ErrInvalidToken
+func (s *accountService) consumeTokenTx(tx *gorm.DB, purpose model.TokenPurpose, token string) (*model.UserToken, *model.User, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidToken
+	}
+	userToken, err := s.tokenRepo.WithTx(tx).GetByHash(purpose, hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+	now := s.now()
+	if userToken.UsedAt != nil || !now.Before(userToken.ExpiresAt) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err := s.tokenRepo.WithTx(tx).MarkUsed(userToken.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.WithTx(tx).GetByID(userToken.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+	return userToken, user, nil
+}
+
+func (s *accountService) link(path, token string) string {
+	return s.publicURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
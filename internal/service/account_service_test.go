@@ -0,0 +1,110 @@
+package service
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/mail"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+func (e *testEnv) accountService(outbox *mail.MemoryOutbox) *accountService {
+	return NewAccountService(e.db, e.users, repository.NewUserTokenRepoGorm(e.db), e.sessions, outbox,
+		"http://localhost:8080/")
+}
+
+var linkPattern = regexp.MustCompile(`http://localhost:8080/\S+`)
+
+// mailedToken returns the token of the link in the last message to the address
+func mailedToken(t *testing.T, outbox *mail.MemoryOutbox, to string) string {
+	t.Helper()
+	msg, ok := outbox.Last(to)
+	require.True(t, ok, "no mail to %s", to)
+	link, err := url.Parse(linkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestEmailVerificationTokensAreSingleUseAndExpire(t *testing.T) {
+	env := newTestEnv(t)
+	outbox := mail.NewMemoryOutbox()
+	accounts := env.accountService(outbox)
+	user := env.createUser(t, "alice", model.RoleUser)
+	require.NoError(t, env.db.Model(user).Updates(map[string]any{
+		"status": model.UserUnverified, "email_verified_at": nil,
+	}).Error)
+
+	// a new link replaces the earlier one
+	require.NoError(t, accounts.SendEmailVerification(user.ID))
+	first := mailedToken(t, outbox, user.Email)
+	require.NoError(t, accounts.SendEmailVerification(user.ID))
+	second := mailedToken(t, outbox, user.Email)
+	assert.ErrorIs(t, accounts.VerifyEmail(first), ErrInvalidToken)
+
+	require.NoError(t, accounts.VerifyEmail(second))
+	assert.ErrorIs(t, accounts.VerifyEmail(second), ErrInvalidToken)
+	verified, err := env.users.GetByID(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.UserActive, verified.Status)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+	assert.ErrorIs(t, accounts.SendEmailVerification(user.ID), ErrAlreadyVerified)
+
+	// a link opened after its lifetime doesn't verify
+	require.NoError(t, env.db.Model(user).Update("email_verified_at", nil).Error)
+	require.NoError(t, accounts.SendEmailVerification(user.ID))
+	token := mailedToken(t, outbox, user.Email)
+	accounts.now = func() time.Time { return time.Now().Add(emailVerificationTTL) }
+	assert.ErrorIs(t, accounts.VerifyEmail(token), ErrInvalidToken)
+
+	assert.ErrorIs(t, accounts.VerifyEmail(""), ErrInvalidToken)
+	assert.ErrorIs(t, accounts.VerifyEmail("forged"), ErrInvalidToken)
+}
+
+func TestPasswordResetTokensAreSingleUseAndExpire(t *testing.T) {
+	env := newTestEnv(t)
+	outbox := mail.NewMemoryOutbox()
+	accounts := env.accountService(outbox)
+	users := env.userService()
+	user := env.createUser(t, "alice", model.RoleUser)
+
+	// unknown addresses succeed without mail
+	require.NoError(t, accounts.RequestPasswordReset("nobody@example.com"))
+	assert.Empty(t, outbox.Messages())
+
+	require.NoError(t, accounts.RequestPasswordReset(" Alice@Example.com "))
+	token := mailedToken(t, outbox, user.Email)
+
+	// a password the policy refuses leaves the token usable
+	var invalid *ValidationError
+	require.ErrorAs(t, accounts.ResetPasswordWithToken(token, "short"), &invalid)
+	require.NoError(t, accounts.ResetPasswordWithToken(token, "Battery-Staple-7"))
+	assert.ErrorIs(t, accounts.ResetPasswordWithToken(token, "Another-Secret-8"), ErrInvalidToken)
+	assert.Equal(t, int64(1), env.sessions.generation(user.ID))
+
+	_, err := users.Authenticate("alice", testPassword, "127.0.0.1", CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	_, err = users.Authenticate("alice", "Battery-Staple-7", "127.0.0.1", CaptchaSolution{})
+	require.NoError(t, err)
+
+	// a link opened after its lifetime doesn't reset
+	require.NoError(t, accounts.RequestPasswordReset(user.Email))
+	token = mailedToken(t, outbox, user.Email)
+	accounts.now = func() time.Time { return time.Now().Add(passwordResetTTL) }
+	assert.ErrorIs(t, accounts.ResetPasswordWithToken(token, "Another-Secret-8"), ErrInvalidToken)
+	assert.Equal(t, int64(1), env.sessions.generation(user.ID))
+
+	// the tokens of one purpose don't work for the other
+	accounts.now = time.Now
+	require.NoError(t, env.db.Model(user).Update("email_verified_at", nil).Error)
+	require.NoError(t, accounts.SendEmailVerification(user.ID))
+	token = mailedToken(t, outbox, user.Email)
+	assert.ErrorIs(t, accounts.ResetPasswordWithToken(token, "Another-Secret-8"), ErrInvalidToken)
+}
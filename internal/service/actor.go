@@ -0,0 +1,73 @@
+package service
+
+import (
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+// Actor is the authenticated principal a service call is made on behalf of,
+// the service layer checks its permissions before changing anything.
+// An API key actor has no user and role, only the scopes of the key
+type Actor struct {
+	UserID   uint
+	Role     model.UserRole
+	APIKeyID uint
+	Scopes   model.PermissionList
+}
+
+// SystemActor is used by internal jobs and bootstrapping, it may do anything
+var SystemActor = Actor{Role: model.RoleAdmin}
+
+func ActorOf(user *model.User) Actor {
+	return Actor{
+		UserID: user.ID,
+		Role:   user.Role,
+	}
+}
+
+func ActorOfAPIKey(key *model.APIKey) Actor {
+	return Actor{
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}
+}
+
+func (a Actor) Can(permission model.Permission) bool {
+	if a.APIKeyID != 0 {
+		return a.Scopes.Has(permission)
+	}
+	return a.Role.Has(permission)
+}
+
+// apiKeyID returns the key of an API key actor, nil otherwise
+func (a Actor) apiKeyID() *uint {
+	if a.APIKeyID == 0 {
+		return nil
+	}
+	id := a.APIKeyID
+	return &id
+}
+
+// authorizeSelfOr lets actors act on their own resources,
+// for the resources of other users the permission is required
+func authorizeSelfOr(actor Actor, ownerID uint, permission model.Permission) error {
+	if actor.UserID != 0 && actor.UserID == ownerID {
+		return nil
+	}
+	return authorize(actor, permission)
+}
+
+// authorizeSelf only lets users act on their own account, e.g. on its second factor
+func authorizeSelf(actor Actor, userID uint) error {
+	if actor.UserID == 0 || actor.UserID != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorize returns ErrForbidden unless the actor has the permission
+func authorize(actor Actor, permission model.Permission) error {
+	if !actor.Can(permission) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// AdmissionClaims is what an admission token lets its holder do:
+// book in the waiting room until ExpiresAt, Subject is the ticket it was issued to
+type AdmissionClaims struct {
+	RoomID    uint      `json:"room"`
+	Subject   string    `json:"sub"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+// AdmissionTokens signs the tokens handed to the customers admitted by a waiting room,
+// they are checked without a lookup when booking
+type AdmissionTokens struct {
+	secret []byte
+}
+
+func NewAdmissionTokens(secret string) *AdmissionTokens {
+	return &AdmissionTokens{
+		secret: []byte(secret),
+	}
+}
+
+func (t *AdmissionTokens) Sign(claims AdmissionClaims) string {
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(t.mac(encoded))
+}
+
+// Verify returns the claims of a token signed with the secret that has not expired
+func (t *AdmissionTokens) Verify(token string) (AdmissionClaims, bool) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return AdmissionClaims{}, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, t.mac(encoded)) {
+		return AdmissionClaims{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return AdmissionClaims{}, false
+	}
+	var claims AdmissionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || !time.Now().Before(claims.ExpiresAt) {
+		return AdmissionClaims{}, false
+	}
+	return claims, true
+}
+
+func (t *AdmissionTokens) mac(payload string) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	h.Write([]byte("admission:" + payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// API keys look like "mrk_1a2b3c4d_<secret>", the part before the second underscore is the prefix
+const (
+	apiKeyPrefix           = "mrk_"
+	defaultAPIKeyRateLimit = 600
+	maxAPIKeyRateLimit     = 100000
+	maxAPIKeyNameLen       = 100
+	apiKeyRateWindow       = time.Minute
+	// the last-used time is written at most this often per key
+	apiKeyLastUsedPrecision = time.Minute
+)
+
+// APIKeyService manages the API keys of partner integrations
+// and authenticates the requests made with them
+type APIKeyService interface {
+	// CreateAPIKey returns the key and its secret, which is not stored and can't be shown again.
+	// The actor can only grant scopes it has, rateLimit 0 means the default
+	CreateAPIKey(actor Actor, name string, scopes []model.Permission, expiresAt *time.Time,
+		rateLimit int) (*model.APIKey, string, error)
+	RevokeAPIKey(actor Actor, id uint) error
+	GetAPIKeys(actor Actor) ([]model.APIKey, error)
+	// Authenticate returns the actor of the key, ErrInvalidCredential for an unknown,
+	// revoked or expired key, and a *RetryAfterError matching ErrRateLimited
+	// once the key made more than its RateLimit requests in the current minute
+	Authenticate(secret string) (Actor, error)
+}
+
+type apiKeyService struct {
+	db      *gorm.DB
+	repo    repository.APIKeyRepo
+	counter RequestCounter
+	now     func() time.Time
+}
+
+var _ APIKeyService = (*apiKeyService)(nil)
+
+func NewAPIKeyService(db *gorm.DB, apiKeyRepo repository.APIKeyRepo, counter RequestCounter) *apiKeyService {
+	return &apiKeyService{
+		db:      db,
+		repo:    apiKeyRepo,
+		counter: counter,
+		now:     time.Now,
+	}
+}
+
+func (s *apiKeyService) CreateAPIKey(actor Actor, name string, scopes []model.Permission, expiresAt *time.Time,
+	rateLimit int) (*model.APIKey, string, error) {
+	if err := authorize(actor, model.PermManageAPIKeys); err != nil {
+		return nil, "", err
+	}
+	if rateLimit == 0 {
+		rateLimit = defaultAPIKeyRateLimit
+	}
+	name = strings.TrimSpace(name)
+	v := &validator{}
+	v.text("name", name, maxAPIKeyNameLen)
+	if len(scopes) == 0 {
+		v.add("scopes", RuleRequired, "must not be empty")
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			v.add("scopes", RuleOneOf, "must be one of %v", model.Permissions())
+			break
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		v.add("expires_at", RuleFuture, "must be in the future")
+	}
+	v.between("rate_limit", rateLimit, 1, maxAPIKeyRateLimit)
+	if err := v.err(); err != nil {
+		return nil, "", err
+	}
+	// no escalation through keys
+	for _, scope := range scopes {
+		if !actor.Can(scope) {
+			return nil, "", ErrForbidden
+		}
+	}
+
+	prefix, secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &model.APIKey{
+		Name:        name,
+		Prefix:      prefix,
+		SecretHash:  hashToken(secret),
+		Scopes:      model.PermissionList(scopes),
+		RateLimit:   rateLimit,
+		ExpiresAt:   expiresAt,
+		CreatedByID: actor.UserID,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(actor Actor, id uint) error {
+	if err := authorize(actor, model.PermManageAPIKeys); err != nil {
+		return err
+	}
+	if err := s.repo.Revoke(id, s.now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *apiKeyService) GetAPIKeys(actor Actor) ([]model.APIKey, error) {
+	if err := authorize(actor, model.PermManageAPIKeys); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll()
+}
+
+func (s *apiKeyService) Authenticate(secret string) (Actor, error) {
+	prefix, ok := apiKeyPrefixOf(secret)
+	if !ok {
+		return Actor{}, ErrInvalidCredential
+	}
+	key, err := s.repo.GetByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return Actor{}, ErrInvalidCredential
+		}
+		return Actor{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return Actor{}, ErrInvalidCredential
+	}
+	now := s.now()
+	if !key.IsUsable(now) {
+		return Actor{}, ErrInvalidCredential
+	}
+
+	// fixed one minute windows, counted per key
+	window := now.Truncate(apiKeyRateWindow)
+	count, err := s.counter.Incr("apikey:rate:"+strconv.FormatUint(uint64(key.ID), 10)+":"+
+		strconv.FormatInt(window.Unix(), 10), apiKeyRateWindow)
+	if err != nil {
+		return Actor{}, err
+	}
+	if count > int64(key.RateLimit) {
+		return Actor{}, &RetryAfterError{Err: ErrRateLimited, RetryAfter: window.Add(apiKeyRateWindow).Sub(now)}
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedPrecision {
+		if err := s.repo.UpdateLastUsed(key.ID, now); err != nil {
+			return Actor{}, err
+		}
+	}
+	return ActorOfAPIKey(key), nil
+}
+
+func newAPIKeySecret() (prefix, secret string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+	prefix = apiKeyPrefix + hex.EncodeToString(id)
+	return prefix, prefix + "_" + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// apiKeyPrefixOf returns the prefix of a key in the format of newAPIKeySecret
+func apiKeyPrefixOf(secret string) (string, bool) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return "", false
+	}
+	i := strings.IndexByte(secret[len(apiKeyPrefix):], '_')
+	if i <= 0 {
+		return "", false
+	}
+	return secret[:len(apiKeyPrefix)+i], true
+}
+
+// RequestCounter counts requests in fixed windows, a counter expires after ttl
+type RequestCounter interface {
+	Incr(key string, ttl time.Duration) (int64, error)
+}
+
+type redisRequestCounter struct {
+	cache cache.Store
+}
+
+var _ RequestCounter = (*redisRequestCounter)(nil)
+
+func NewRedisRequestCounter(cache cache.Store) *redisRequestCounter {
+	return &redisRequestCounter{
+		cache: cache,
+	}
+}
+
+func (c *redisRequestCounter) Incr(key string, ttl time.Duration) (int64, error) {
+	return c.cache.IncrWithTTL(key, ttl)
+}
+
+type memoryRequestCounter struct {
+	mu       sync.Mutex
+	counters map[string]memoryCount
+}
+
+type memoryCount struct {
+	count     int64
+	expiresAt time.Time
+}
+
+var _ RequestCounter = (*memoryRequestCounter)(nil)
+
+func NewMemoryRequestCounter() *memoryRequestCounter {
+	return &memoryRequestCounter{
+		counters: make(map[string]memoryCount),
+	}
+}
+
+func (c *memoryRequestCounter) Incr(key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	counter, ok := c.counters[key]
+	if !ok || now.After(counter.expiresAt) {
+		// drop the expired counters while the lock is held anyway
+		for k, other := range c.counters {
+			if now.After(other.expiresAt) {
+				delete(c.counters, k)
+			}
+		}
+		counter = memoryCount{expiresAt: now.Add(ttl)}
+	}
+	counter.count++
+	c.counters[key] = counter
+	return counter.count, nil
+}
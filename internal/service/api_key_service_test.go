@@ -0,0 +1,125 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+func (e *testEnv) apiKeyService() *apiKeyService {
+	return NewAPIKeyService(e.db, repository.NewAPIKeyRepoGorm(e.db), NewMemoryRequestCounter())
+}
+
+// fieldRules returns the rule of every field of a validation error
+func fieldRules(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	rules := make(map[string]string)
+	for _, field := range validationErr.Fields {
+		rules[field.Field] = field.Rule
+	}
+	return rules
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	env := newTestEnv(t)
+	keys := env.apiKeyService()
+	admin := ActorOf(env.createUser(t, "admin", model.RoleAdmin))
+	boxOffice := ActorOf(env.createUser(t, "clerk", model.RoleBoxOffice))
+
+	_, _, err := keys.CreateAPIKey(admin, "kiosk", nil, nil, 0)
+	assert.Equal(t, map[string]string{"scopes": RuleRequired}, fieldRules(t, err))
+	_, _, err = keys.CreateAPIKey(admin, "kiosk", []model.Permission{"tickets:print"}, nil, 0)
+	assert.Equal(t, map[string]string{"scopes": RuleOneOf}, fieldRules(t, err))
+	_, _, err = keys.CreateAPIKey(boxOffice, "kiosk", []model.Permission{model.PermReserveForOthers}, nil, 0)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	key, secret, err := keys.CreateAPIKey(admin, " kiosk ", []model.Permission{
+		model.PermReserveForOthers, model.PermManageAPIKeys,
+	}, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "kiosk", key.Name)
+	assert.Equal(t, defaultAPIKeyRateLimit, key.RateLimit)
+	assert.NotContains(t, key.SecretHash, secret)
+	prefix, ok := apiKeyPrefixOf(secret)
+	require.True(t, ok)
+	assert.Equal(t, key.Prefix, prefix)
+
+	// the key can do what its scopes allow and nothing its creator's role adds
+	actor, err := keys.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, actor.APIKeyID)
+	assert.True(t, actor.Can(model.PermReserveForOthers))
+	assert.False(t, actor.Can(model.PermManageMovies))
+
+	// nor can it mint keys with more scopes than its own
+	_, _, err = keys.CreateAPIKey(actor, "wider", []model.Permission{model.PermManageMovies}, nil, 0)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, _, err = keys.CreateAPIKey(actor, "narrower", []model.Permission{model.PermReserveForOthers}, nil, 0)
+	require.NoError(t, err)
+}
+
+func TestAPIKeysExpireAndCanBeRevoked(t *testing.T) {
+	env := newTestEnv(t)
+	keys := env.apiKeyService()
+	admin := ActorOf(env.createUser(t, "admin", model.RoleAdmin))
+	scopes := []model.Permission{model.PermViewReservations}
+
+	past := time.Now().Add(-time.Minute)
+	_, _, err := keys.CreateAPIKey(admin, "kiosk", scopes, &past, 0)
+	assert.Equal(t, map[string]string{"expires_at": RuleFuture}, fieldRules(t, err))
+
+	expiresAt := time.Now().Add(time.Hour)
+	key, secret, err := keys.CreateAPIKey(admin, "kiosk", scopes, &expiresAt, 0)
+	require.NoError(t, err)
+	_, err = keys.Authenticate(secret)
+	require.NoError(t, err)
+	keys.now = func() time.Time { return expiresAt }
+	_, err = keys.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	keys.now = time.Now
+
+	key, secret, err = keys.CreateAPIKey(admin, "till", scopes, nil, 0)
+	require.NoError(t, err)
+	require.NoError(t, keys.RevokeAPIKey(admin, key.ID))
+	_, err = keys.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	assert.ErrorIs(t, keys.RevokeAPIKey(admin, key.ID+100), ErrNotFound)
+
+	// a forged secret with a real prefix is refused
+	_, err = keys.Authenticate(key.Prefix + "_forged")
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	_, err = keys.Authenticate("not-a-key")
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+}
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	env := newTestEnv(t)
+	keys := env.apiKeyService()
+	admin := ActorOf(env.createUser(t, "admin", model.RoleAdmin))
+	_, secret, err := keys.CreateAPIKey(admin, "kiosk", []model.Permission{model.PermViewReservations}, nil, 2)
+	require.NoError(t, err)
+
+	now := time.Now().Truncate(apiKeyRateWindow).Add(45 * time.Second)
+	keys.now = func() time.Time { return now }
+	for range 2 {
+		_, err = keys.Authenticate(secret)
+		require.NoError(t, err)
+	}
+	_, err = keys.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	var retry *RetryAfterError
+	require.ErrorAs(t, err, &retry)
+	assert.Equal(t, 15*time.Second, retry.RetryAfter)
+
+	// the next minute starts a new window
+	now = now.Add(15 * time.Second)
+	_, err = keys.Authenticate(secret)
+	require.NoError(t, err)
+}
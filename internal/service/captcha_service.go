@@ -0,0 +1,270 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wenlng/go-captcha-assets/resources/images"
+	"github.com/wenlng/go-captcha-assets/resources/tiles"
+	"github.com/wenlng/go-captcha/v2/slide"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+const (
+	captchaTTL = 2 * time.Minute
+	// how far, in pixels, the answer may be from the tile position
+	captchaPadding = 5
+)
+
+// CaptchaChallenge is a slide puzzle: the tile has to be dragged
+// to its place in the master image, both images are base64 data URIs
+type CaptchaChallenge struct {
+	ID          string    `json:"id"`
+	MasterImage string    `json:"master_image"`
+	TileImage   string    `json:"tile_image"`
+	TileX       int       `json:"tile_x"`
+	TileY       int       `json:"tile_y"`
+	TileWidth   int       `json:"tile_width"`
+	TileHeight  int       `json:"tile_height"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// CaptchaSolution is the position the tile was dragged to,
+// the zero value means no challenge was solved
+type CaptchaSolution struct {
+	ChallengeID string
+	X           int
+	Y           int
+}
+
+func (s CaptchaSolution) IsEmpty() bool {
+	return s.ChallengeID == ""
+}
+
+// CaptchaService issues challenges and verifies their solutions,
+// a challenge can be verified once, whatever the outcome
+type CaptchaService interface {
+	Generate() (*CaptchaChallenge, error)
+	// Verify returns ErrCaptchaRequired for an empty solution
+	// and ErrCaptchaInvalid for a wrong, used or expired one
+	Verify(solution CaptchaSolution) error
+}
+
+// captchaAnswer is what is stored for a challenge until it is verified
+type captchaAnswer struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// ChallengeStore keeps the answers of unverified challenges
+type ChallengeStore interface {
+	Save(id string, answer captchaAnswer, ttl time.Duration) error
+	// Take removes the answer and reports whether it was there
+	Take(id string) (captchaAnswer, bool, error)
+}
+
+type redisChallengeStore struct {
+	cache cache.Store
+}
+
+var _ ChallengeStore = (*redisChallengeStore)(nil)
+
+func NewRedisChallengeStore(cache cache.Store) *redisChallengeStore {
+	return &redisChallengeStore{
+		cache: cache,
+	}
+}
+
+func captchaKey(id string) string {
+	return "captcha:" + id
+}
+
+func (s *redisChallengeStore) Save(id string, answer captchaAnswer, ttl time.Duration) error {
+	return s.cache.Set(captchaKey(id), answer, ttl)
+}
+
+func (s *redisChallengeStore) Take(id string) (captchaAnswer, bool, error) {
+	var answer captchaAnswer
+	if err := s.cache.GetDelete(captchaKey(id), &answer); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return captchaAnswer{}, false, nil
+		}
+		return captchaAnswer{}, false, err
+	}
+	return answer, true, nil
+}
+
+type memoryChallengeStore struct {
+	mu      sync.Mutex
+	answers map[string]memoryChallenge
+}
+
+type memoryChallenge struct {
+	answer    captchaAnswer
+	expiresAt time.Time
+}
+
+var _ ChallengeStore = (*memoryChallengeStore)(nil)
+
+func NewMemoryChallengeStore() *memoryChallengeStore {
+	return &memoryChallengeStore{
+		answers: make(map[string]memoryChallenge),
+	}
+}
+
+func (s *memoryChallengeStore) Save(id string, answer captchaAnswer, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.answers[id] = memoryChallenge{answer: answer, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryChallengeStore) Take(id string) (captchaAnswer, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	challenge, ok := s.answers[id]
+	delete(s.answers, id)
+	if !ok || time.Now().After(challenge.expiresAt) {
+		return captchaAnswer{}, false, nil
+	}
+	return challenge.answer, true, nil
+}
+
+type slideCaptchaService struct {
+	captcha slide.Captcha
+	store   ChallengeStore
+}
+
+var _ CaptchaService = (*slideCaptchaService)(nil)
+
+// NewSlideCaptchaService builds slide puzzles from the go-captcha-assets images
+func NewSlideCaptchaService(store ChallengeStore) (*slideCaptchaService, error) {
+	backgrounds, err := images.GetImages()
+	if err != nil {
+		return nil, fmt.Errorf("load captcha backgrounds: %w", err)
+	}
+	assetTiles, err := tiles.GetTiles()
+	if err != nil {
+		return nil, fmt.Errorf("load captcha tiles: %w", err)
+	}
+	graphs := make([]*slide.GraphImage, 0, len(assetTiles))
+	for _, tile := range assetTiles {
+		graphs = append(graphs, &slide.GraphImage{
+			OverlayImage: tile.OverlayImage,
+			ShadowImage:  tile.ShadowImage,
+			MaskImage:    tile.MaskImage,
+		})
+	}
+
+	builder := slide.NewBuilder()
+	builder.SetResources(
+		slide.WithGraphImages(graphs),
+		slide.WithBackgrounds(backgrounds),
+	)
+	return &slideCaptchaService{
+		captcha: builder.Make(),
+		store:   store,
+	}, nil
+}
+
+func (s *slideCaptchaService) Generate() (*CaptchaChallenge, error) {
+	data, err := s.captcha.Generate()
+	if err != nil {
+		return nil, err
+	}
+	block := data.GetData()
+	masterImage, err := data.GetMasterImage().ToBase64()
+	if err != nil {
+		return nil, err
+	}
+	tileImage, err := data.GetTileImage().ToBase64()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newChallengeID()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(id, captchaAnswer{X: block.X, Y: block.Y}, captchaTTL); err != nil {
+		return nil, err
+	}
+	return &CaptchaChallenge{
+		ID:          id,
+		MasterImage: masterImage,
+		TileImage:   tileImage,
+		TileX:       block.TileX,
+		TileY:       block.TileY,
+		TileWidth:   block.Width,
+		TileHeight:  block.Height,
+		ExpiresAt:   time.Now().Add(captchaTTL),
+	}, nil
+}
+
+func (s *slideCaptchaService) Verify(solution CaptchaSolution) error {
+	return verifyCaptcha(s.store, solution)
+}
+
+// DeterministicCaptchaAnswer solves every challenge of the deterministic captcha service
+var DeterministicCaptchaAnswer = CaptchaSolution{X: 42, Y: 24}
+
+type deterministicCaptchaService struct {
+	store ChallengeStore
+}
+
+var _ CaptchaService = (*deterministicCaptchaService)(nil)
+
+// NewDeterministicCaptchaService is for tests and local development,
+// its challenges have no images and are all solved by DeterministicCaptchaAnswer,
+// they are single-use and expire like real ones
+func NewDeterministicCaptchaService(store ChallengeStore) *deterministicCaptchaService {
+	return &deterministicCaptchaService{
+		store: store,
+	}
+}
+
+func (s *deterministicCaptchaService) Generate() (*CaptchaChallenge, error) {
+	id, err := newChallengeID()
+	if err != nil {
+		return nil, err
+	}
+	answer := captchaAnswer{X: DeterministicCaptchaAnswer.X, Y: DeterministicCaptchaAnswer.Y}
+	if err := s.store.Save(id, answer, captchaTTL); err != nil {
+		return nil, err
+	}
+	return &CaptchaChallenge{
+		ID:        id,
+		ExpiresAt: time.Now().Add(captchaTTL),
+	}, nil
+}
+
+func (s *deterministicCaptchaService) Verify(solution CaptchaSolution) error {
+	return verifyCaptcha(s.store, solution)
+}
+
+func verifyCaptcha(store ChallengeStore, solution CaptchaSolution) error {
+	if solution.IsEmpty() {
+		return ErrCaptchaRequired
+	}
+	answer, ok, err := store.Take(solution.ChallengeID)
+	if err != nil {
+		return err
+	}
+	if !ok || !slide.Validate(solution.X, solution.Y, answer.X, answer.Y, captchaPadding) {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
+
+func newChallengeID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
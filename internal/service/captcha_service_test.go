@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestCaptchaChallengesAreSingleUse(t *testing.T) {
+	server := miniredis.RunT(t)
+	redis := cache.NewRedisCache(server.Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+	stores := map[string]ChallengeStore{
+		"memory": NewMemoryChallengeStore(),
+		"redis":  NewRedisChallengeStore(redis),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			captcha := NewDeterministicCaptchaService(store)
+			solve := func(challenge *CaptchaChallenge, dx int) CaptchaSolution {
+				return CaptchaSolution{ChallengeID: challenge.ID, X: DeterministicCaptchaAnswer.X + dx,
+					Y: DeterministicCaptchaAnswer.Y}
+			}
+
+			assert.ErrorIs(t, captcha.Verify(CaptchaSolution{}), ErrCaptchaRequired)
+			challenge, err := captcha.Generate()
+			require.NoError(t, err)
+			// close enough is solved, once
+			require.NoError(t, captcha.Verify(solve(challenge, captchaPadding)))
+			assert.ErrorIs(t, captcha.Verify(solve(challenge, 0)), ErrCaptchaInvalid)
+
+			// a wrong answer spends the challenge too
+			challenge, err = captcha.Generate()
+			require.NoError(t, err)
+			assert.ErrorIs(t, captcha.Verify(solve(challenge, captchaPadding+5)), ErrCaptchaInvalid)
+			assert.ErrorIs(t, captcha.Verify(solve(challenge, 0)), ErrCaptchaInvalid)
+
+			assert.ErrorIs(t, captcha.Verify(CaptchaSolution{ChallengeID: "forged", X: 42, Y: 24}), ErrCaptchaInvalid)
+		})
+	}
+
+	// expired challenges are refused
+	require.NoError(t, stores["memory"].Save("expired", captchaAnswer{X: 42, Y: 24}, -time.Second))
+	assert.ErrorIs(t, verifyCaptcha(stores["memory"], CaptchaSolution{ChallengeID: "expired", X: 42, Y: 24}),
+		ErrCaptchaInvalid)
+	challenge, err := NewDeterministicCaptchaService(stores["redis"]).Generate()
+	require.NoError(t, err)
+	server.FastForward(captchaTTL)
+	assert.ErrorIs(t, verifyCaptcha(stores["redis"], CaptchaSolution{ChallengeID: challenge.ID, X: 42, Y: 24}),
+		ErrCaptchaInvalid)
+}
+
+func TestRegisterAndRepeatedLoginFailuresNeedACaptcha(t *testing.T) {
+	env := newTestEnv(t)
+	captcha := NewDeterministicCaptchaService(NewMemoryChallengeStore())
+	users := NewUserService(env.db, env.users, env.reservations, NewLoginThrottle(NewMemoryAttemptStore()), captcha,
+		env.sessions, nil)
+	solved := func() CaptchaSolution {
+		challenge, err := captcha.Generate()
+		require.NoError(t, err)
+		return CaptchaSolution{ChallengeID: challenge.ID, X: DeterministicCaptchaAnswer.X, Y: DeterministicCaptchaAnswer.Y}
+	}
+
+	profile := model.UserProfile{Email: "alice@example.com"}
+	_, err := users.Register("alice", testPassword, profile, CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrCaptchaRequired)
+	solution := solved()
+	_, err = users.Register("alice", testPassword, profile, solution)
+	require.NoError(t, err)
+	// the solution can't register a second account
+	_, err = users.Register("alice2", testPassword, model.UserProfile{Email: "alice2@example.com"}, solution)
+	assert.ErrorIs(t, err, ErrCaptchaInvalid)
+
+	_, err = users.Authenticate("alice", testPassword, "192.0.2.1", CaptchaSolution{})
+	require.NoError(t, err)
+	throttle := users.loginThrottle.(*loginThrottle)
+	for range loginCaptchaThreshold {
+		require.NoError(t, throttle.RecordFailure("alice", "192.0.2.1"))
+	}
+	// waiting out the backoff, the next attempt needs a captcha
+	throttle.now = func() time.Time { return time.Now().Add(loginMaxBackoff) }
+	_, err = users.Authenticate("alice", testPassword, "192.0.2.1", CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrCaptchaRequired)
+	_, err = users.Authenticate("alice", testPassword, "192.0.2.1", solved())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// CatalogEntity is a kind of catalogue data the cached responses are built from
+type CatalogEntity string
+
+const (
+	CatalogMovies    CatalogEntity = "movies"
+	CatalogHalls     CatalogEntity = "halls"
+	CatalogShowtimes CatalogEntity = "showtimes"
+)
+
+// CachedResponse is a response of a catalogue read, ETag is derived from the body
+type CachedResponse struct {
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+}
+
+// CatalogCache keeps the responses of catalogue reads until one of the entities
+// they were built from is invalidated. MovieService, HallService and ShowtimeService
+// invalidate the entities they change
+type CatalogCache interface {
+	// GetOrLoad returns the response cached for key, or builds it with load and caches it for ttl.
+	// The versions of the entities are read before the response is built, so a response
+	// built from data changed meanwhile is never served. Concurrent misses of a key share
+	// one load, an error of load is returned to all of them and nothing is cached
+	GetOrLoad(key string, entities []CatalogEntity, ttl time.Duration,
+		load func() (CachedResponse, error)) (CachedResponse, error)
+	// Invalidate drops the responses built from the entities
+	Invalidate(entities ...CatalogEntity) error
+}
+
+// invalidateCatalog drops the cached responses after a change was committed, the change
+// is done even if that fails, the responses then expire with their TTL
+func invalidateCatalog(catalog CatalogCache, entities ...CatalogEntity) {
+	if catalog != nil {
+		_ = catalog.Invalidate(entities...)
+	}
+}
+
+// catalogCache keeps the responses under the versions of their entities, a change
+// increments the version of the entity, so a response built before the change and stored
+// after it is never read, and deletes the responses of the entity to free the cache.
+// The versions are kept apart from the responses: a version evicted or copied locally
+// would serve responses of older data, a response is only ever read under its own version
+type catalogCache struct {
+	versions  cache.CounterStore
+	cache     cache.Cache
+	responses *cache.Typed[CachedResponse]
+}
+
+var _ CatalogCache = (*catalogCache)(nil)
+
+// NewCatalogCache keeps the responses in c, which may keep local copies, like a TwoTier,
+// and the versions in the counters, which must be shared by the instances, like redis
+func NewCatalogCache(c cache.Cache, versions cache.CounterStore) *catalogCache {
+	return &catalogCache{
+		versions:  versions,
+		cache:     c,
+		responses: cache.NewTyped[CachedResponse](c),
+	}
+}
+
+func (c *catalogCache) GetOrLoad(key string, entities []CatalogEntity, ttl time.Duration,
+	load func() (CachedResponse, error)) (CachedResponse, error) {
+	versionKeys := make([]string, len(entities))
+	for i, entity := range entities {
+		versionKeys[i] = catalogVersionKey(entity)
+	}
+	versions, err := c.versions.Counters(versionKeys...)
+	if err != nil {
+		return CachedResponse{}, err
+	}
+	return c.responses.GetOrLoad(catalogResponseKey(catalogVersion(entities, versions), key), ttl, load)
+}
+
+func (c *catalogCache) Invalidate(entities ...CatalogEntity) error {
+	for _, entity := range entities {
+		if _, err := c.versions.Incr(catalogVersionKey(entity)); err != nil {
+			return err
+		}
+		if _, err := c.cache.DeletePattern("catalog:*|" + string(entity) + "=*"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func catalogVersionKey(entity CatalogEntity) string {
+	return "catalog-version:" + string(entity)
+}
+
+// catalogVersion is like "|movies=0|halls=5|"
+func catalogVersion(entities []CatalogEntity, versions []int64) string {
+	var version strings.Builder
+	version.WriteString("|")
+	for i, entity := range entities {
+		version.WriteString(string(entity) + "=" + strconv.FormatInt(versions[i], 10) + "|")
+	}
+	return version.String()
+}
+
+func catalogResponseKey(version string, key string) string {
+	return "catalog:" + version + ":" + key
+}
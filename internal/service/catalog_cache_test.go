@@ -0,0 +1,112 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+// catalogCache is the catalogue cache of an instance, with its own local copies
+func (e *testEnv) catalogCache() *catalogCache {
+	return NewCatalogCache(cache.NewTwoTier(cache.NewLRU(100), e.redis, time.Minute), e.redis)
+}
+
+// lookup returns the response cached for key and false, or loads fallback and returns it and true
+func lookup(t *testing.T, instance *catalogCache, key string, entities []CatalogEntity,
+	fallback CachedResponse) (CachedResponse, bool) {
+	t.Helper()
+	loaded := false
+	response, err := instance.GetOrLoad(key, entities, time.Hour, func() (CachedResponse, error) {
+		loaded = true
+		return fallback, nil
+	})
+	require.NoError(t, err)
+	return response, loaded
+}
+
+func TestCatalogInvalidationReachesEveryInstance(t *testing.T) {
+	env := newTestEnv(t)
+	first, second := env.catalogCache(), env.catalogCache()
+	movies := env.movieService(first)
+	movie := &model.Movie{Title: "Dune", RuntimeMinutes: 155}
+	require.NoError(t, movies.CreateMovie(SystemActor, movie))
+	response := func(etag string) CachedResponse {
+		return CachedResponse{Status: 200, ContentType: "application/json", ETag: etag, Body: []byte(`[]`)}
+	}
+	moviesResponse, hallsResponse := response(`"1"`), response(`"2"`)
+
+	_, loaded := lookup(t, first, "/movies", []CatalogEntity{CatalogMovies}, moviesResponse)
+	assert.True(t, loaded)
+	_, loaded = lookup(t, first, "/halls", []CatalogEntity{CatalogHalls}, hallsResponse)
+	assert.True(t, loaded)
+	// both instances serve the cached responses, the second one copies them locally
+	for _, instance := range []*catalogCache{first, second} {
+		cached, loaded := lookup(t, instance, "/movies", []CatalogEntity{CatalogMovies}, response(`"other"`))
+		assert.False(t, loaded)
+		assert.Equal(t, moviesResponse, cached)
+	}
+
+	// a response built before a change and stored after it is never served
+	_, err := second.GetOrLoad("/movies/1", []CatalogEntity{CatalogMovies, CatalogShowtimes}, time.Hour,
+		func() (CachedResponse, error) {
+			movie.Description = "Arrakis"
+			require.NoError(t, movies.UpdateMovie(SystemActor, movie))
+			return moviesResponse, nil
+		})
+	require.NoError(t, err)
+
+	// the change made on the first instance drops the movies on both, the halls stay
+	fresh := response(`"fresh"`)
+	for _, instance := range []*catalogCache{first, second} {
+		cached, _ := lookup(t, instance, "/movies", []CatalogEntity{CatalogMovies}, fresh)
+		assert.Equal(t, fresh, cached)
+		cached, _ = lookup(t, instance, "/movies/1", []CatalogEntity{CatalogMovies, CatalogShowtimes}, fresh)
+		assert.Equal(t, fresh, cached)
+		cached, loaded := lookup(t, instance, "/halls", []CatalogEntity{CatalogHalls}, fresh)
+		assert.False(t, loaded)
+		assert.Equal(t, hallsResponse, cached)
+	}
+}
+
+func TestCatalogMissesShareOneLoad(t *testing.T) {
+	env := newTestEnv(t)
+	catalog := env.catalogCache()
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func() (CachedResponse, error) {
+		loads.Add(1)
+		<-release
+		return CachedResponse{Status: 200, ETag: `"1"`, Body: []byte(`[]`)}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := catalog.GetOrLoad("/movies", []CatalogEntity{CatalogMovies}, time.Hour, load)
+			assert.NoError(t, err)
+			assert.Equal(t, `"1"`, response.ETag)
+		}()
+	}
+	// let the misses pile up behind the first load
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), loads.Load())
+
+	// a failed load is returned and not cached
+	_, err := catalog.GetOrLoad("/halls", []CatalogEntity{CatalogHalls}, time.Hour, func() (CachedResponse, error) {
+		return CachedResponse{}, ErrNotFound
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, loaded := lookup(t, catalog, "/halls", []CatalogEntity{CatalogHalls}, CachedResponse{Status: 200})
+	assert.True(t, loaded)
+}
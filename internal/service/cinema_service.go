@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+type CinemaService interface {
+	CreateCinema(actor Actor, cinema *model.Cinema) error
+	UpdateCinema(actor Actor, cinema *model.Cinema) error
+	DeleteCinemaByID(actor Actor, id uint) error
+	GetCinemaByID(id uint) (*model.Cinema, error)
+	GetAllCinemas() ([]model.Cinema, error)
+}
+
+type cinemaService struct {
+	db       *gorm.DB
+	repo     repository.CinemaRepo
+	hallRepo repository.HallRepo
+}
+
+var _ CinemaService = (*cinemaService)(nil)
+
+func NewCinemaService(db *gorm.DB, cinemaRepo repository.CinemaRepo, hallRepo repository.HallRepo) *cinemaService {
+	return &cinemaService{
+		db:       db,
+		repo:     cinemaRepo,
+		hallRepo: hallRepo,
+	}
+}
+
+// limits of a cinema
+const (
+	maxCinemaNameLen    = 100
+	maxCinemaAddressLen = 255
+	maxCinemaEmailLen   = 255
+	maxCinemaPhoneLen   = 32
+)
+
+func (s *cinemaService) CreateCinema(actor Actor, cinema *model.Cinema) error {
+	if err := authorize(actor, model.PermManageCinemas); err != nil {
+		return err
+	}
+	if err := prepareCinema(cinema); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkNameUnusedTx(tx, 0, cinema.Name); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).Create(cinema)
+	})
+}
+
+func (s *cinemaService) UpdateCinema(actor Actor, cinema *model.Cinema) error {
+	if err := authorize(actor, model.PermManageCinemas); err != nil {
+		return err
+	}
+	if err := prepareCinema(cinema); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		existingCinema, err := s.repo.WithTx(tx).GetByID(cinema.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if existingCinema.Name != cinema.Name {
+			if err := s.checkNameUnusedTx(tx, cinema.ID, cinema.Name); err != nil {
+				return err
+			}
+		}
+		return s.repo.WithTx(tx).Update(cinema)
+	})
+}
+
+func (s *cinemaService) DeleteCinemaByID(actor Actor, id uint) error {
+	if err := authorize(actor, model.PermManageCinemas); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// verify no related hall exists
+		relatedHalls, err := s.hallRepo.WithTx(tx).GetByCinemaID(id)
+		if err != nil {
+			return err
+		}
+		if len(relatedHalls) != 0 {
+			ids := make([]uint, 0, len(relatedHalls))
+			for _, hall := range relatedHalls {
+				ids = append(ids, hall.ID)
+			}
+			return ErrRelatedResourceExists.WithDetail("hall_ids", ids)
+		}
+		return s.repo.WithTx(tx).DeleteByID(id)
+	})
+}
+
+func (s *cinemaService) GetCinemaByID(id uint) (*model.Cinema, error) {
+	cinema, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return cinema, nil
+}
+
+func (s *cinemaService) GetAllCinemas() ([]model.Cinema, error) {
+	return s.repo.ListAll()
+}
+
+// checkNameUnusedTx returns ErrAlreadyExists if another cinema has the name
+func (s *cinemaService) checkNameUnusedTx(tx *gorm.DB, id uint, name string) error {
+	anotherCinema, err := s.repo.WithTx(tx).GetByName(name)
+	if err == nil && anotherCinema != nil && anotherCinema.ID != id {
+		return ErrAlreadyExists.WithDetail("id", anotherCinema.ID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// prepareCinema trims the input and validates it
+func prepareCinema(cinema *model.Cinema) error {
+	cinema.Name = strings.TrimSpace(cinema.Name)
+	cinema.Address = strings.TrimSpace(cinema.Address)
+	cinema.Timezone = strings.TrimSpace(cinema.Timezone)
+	cinema.ContactEmail = strings.TrimSpace(cinema.ContactEmail)
+	cinema.ContactPhone = strings.TrimSpace(cinema.ContactPhone)
+
+	v := &validator{}
+	v.text("name", cinema.Name, maxCinemaNameLen)
+	v.maxLen("address", cinema.Address, maxCinemaAddressLen)
+	if cinema.Timezone == "" {
+		v.add("timezone", RuleRequired, "must not be empty")
+	} else if _, err := time.LoadLocation(cinema.Timezone); err != nil {
+		v.add("timezone", RuleInvalid, "must be an IANA time zone name")
+	}
+	v.maxLen("contact_email", cinema.ContactEmail, maxCinemaEmailLen)
+	v.maxLen("contact_phone", cinema.ContactPhone, maxCinemaPhoneLen)
+	return v.err()
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func (e *testEnv) cinemaService() *cinemaService {
+	return NewCinemaService(e.db, e.cinemas, e.halls)
+}
+
+func TestHallNamesAreUniqueWithinTheirCinema(t *testing.T) {
+	env := newTestEnv(t)
+	cinemas := env.cinemaService()
+	halls := env.hallService()
+	odeon := &model.Cinema{Name: "Odeon", Timezone: "Europe/Berlin"}
+	require.NoError(t, cinemas.CreateCinema(SystemActor, odeon))
+	rex := &model.Cinema{Name: "Rex", Timezone: "Europe/Paris"}
+	require.NoError(t, cinemas.CreateCinema(SystemActor, rex))
+
+	odeonHall := &model.Hall{CinemaID: odeon.ID, Name: "Hall 1", Rows: 2, Cols: 2}
+	require.NoError(t, halls.CreateHall(SystemActor, odeonHall))
+	rexHall := &model.Hall{CinemaID: rex.ID, Name: "Hall 1", Rows: 2, Cols: 2}
+	require.NoError(t, halls.CreateHall(SystemActor, rexHall))
+
+	err := halls.CreateHall(SystemActor, &model.Hall{CinemaID: odeon.ID, Name: "Hall 1", Rows: 1, Cols: 1})
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	var coded *Error
+	require.ErrorAs(t, err, &coded)
+	assert.Equal(t, odeonHall.ID, coded.Details["id"])
+	err = halls.CreateHall(SystemActor, &model.Hall{CinemaID: rex.ID + 1, Name: "Hall 1", Rows: 1, Cols: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// moving a hall to a cinema with a hall of that name is refused
+	moved := *rexHall
+	moved.CinemaID = odeon.ID
+	assert.ErrorIs(t, halls.UpdateHall(SystemActor, &moved), ErrAlreadyExists)
+	moved.Name = "Hall 2"
+	require.NoError(t, halls.UpdateHall(SystemActor, &moved))
+
+	found, err := halls.GetHallByName(odeon.ID, "Hall 2")
+	require.NoError(t, err)
+	assert.Equal(t, rexHall.ID, found.ID)
+	_, err = halls.GetHallByName(rex.ID, "Hall 2")
+	assert.ErrorIs(t, err, ErrNotFound)
+	odeonHalls, err := halls.GetHallsByCinemaID(odeon.ID)
+	require.NoError(t, err)
+	assert.Len(t, odeonHalls, 2)
+}
+
+func TestCinemaWithHallsCantBeDeleted(t *testing.T) {
+	env := newTestEnv(t)
+	cinemas := env.cinemaService()
+	showtime := env.createShowtime(t)
+	hall, err := env.halls.GetByID(showtime.HallID)
+	require.NoError(t, err)
+
+	err = cinemas.DeleteCinemaByID(SystemActor, hall.CinemaID)
+	assert.ErrorIs(t, err, ErrRelatedResourceExists)
+	var coded *Error
+	require.ErrorAs(t, err, &coded)
+	assert.Equal(t, []uint{hall.ID}, coded.Details["hall_ids"])
+
+	// the catalogue of the cinema is found through its halls
+	showtimes, err := env.showtimeService().GetShowtimesByCinemaID(hall.CinemaID)
+	require.NoError(t, err)
+	require.Len(t, showtimes, 1)
+	assert.Equal(t, showtime.ID, showtimes[0].ID)
+	movies, err := env.movieService(nil).GetMoviesByCinemaID(hall.CinemaID)
+	require.NoError(t, err)
+	require.Len(t, movies, 1)
+	assert.Equal(t, showtime.MovieID, movies[0].ID)
+	movies, err = env.movieService(nil).GetMoviesByCinemaID(hall.CinemaID + 1)
+	require.NoError(t, err)
+	assert.Empty(t, movies)
+
+	empty := &model.Cinema{Name: "Rex", Timezone: "Europe/Paris"}
+	require.NoError(t, cinemas.CreateCinema(SystemActor, empty))
+	assert.ErrorIs(t, cinemas.DeleteCinemaByID(Actor{}, empty.ID), ErrForbidden)
+	require.NoError(t, cinemas.DeleteCinemaByID(SystemActor, empty.ID))
+	_, err = cinemas.GetCinemaByID(empty.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
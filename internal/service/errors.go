@@ -1,18 +1,150 @@
 package service
 
 import (
-	"errors"
+	"maps"
 )
 
+// Code identifies the kind of an Error, clients branch on it
+// so it never changes once released, unlike the message
+type Code string
+
+const (
+	CodeInternal             Code = "internal"
+	CodeInvalidInput         Code = "invalid_input"
+	CodeNotFound             Code = "not_found"
+	CodeAlreadyExists        Code = "already_exists"
+	CodeVersionConflict      Code = "version_conflict"
+	CodeRelatedResources     Code = "related_resources_exist"
+	CodeInvalidCredential    Code = "invalid_credential"
+	CodeForbidden            Code = "forbidden"
+	CodeAccountLocked        Code = "account_locked"
+	CodeTooManyAttempts      Code = "too_many_attempts"
+	CodeTwoFactorRequired    Code = "two_factor_required"
+	CodeInvalidTwoFactorCode Code = "invalid_two_factor_code"
+	CodeTwoFactorNotEnabled  Code = "two_factor_not_enabled"
+	CodeRateLimited          Code = "rate_limited"
+	CodeUserNotVerified      Code = "user_not_verified"
+	CodeUserSuspended        Code = "user_suspended"
+	CodeAlreadyVerified      Code = "already_verified"
+	CodeInvalidToken         Code = "invalid_token"
+	CodeCaptchaRequired      Code = "captcha_required"
+	CodeCaptchaInvalid       Code = "captcha_invalid"
+	CodeNoTicketsAvailable   Code = "no_tickets_available"
+	CodeShowtimeNotFound     Code = "showtime_not_found"
+	CodeAlreadyReserved      Code = "already_reserved"
+	CodeSeatNotFound         Code = "seat_not_found"
+	CodeSeatBlocked          Code = "seat_blocked"
+	CodeSeatTaken            Code = "seat_taken"
+	CodeHallUnderMaintenance Code = "hall_under_maintenance"
+	CodeIdempotencyKeyReused Code = "idempotency_key_reused"
+	CodeIdempotencyPending   Code = "idempotency_request_in_progress"
+	CodeAdmissionRequired    Code = "admission_required"
+	CodeAdmissionInvalid     Code = "admission_invalid"
+)
+
+// Error is an error the clients can act on, Details optionally
+// carries data about it, like the IDs of the conflicting resources.
+// Errors match with errors.Is when their codes are equal,
+// so an error with details still matches its sentinel
+type Error struct {
+	Code    Code
+	Message string
+	Details map[string]any
+}
+
+func NewError(code Code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail returns a copy of the error with the detail added
+func (e *Error) WithDetail(key string, value any) *Error {
+	copied := *e
+	copied.Details = make(map[string]any, len(e.Details)+1)
+	maps.Copy(copied.Details, e.Details)
+	copied.Details[key] = value
+	return &copied
+}
+
+var (
+	ErrNotFound              = NewError(CodeNotFound, "resource not found")
+	ErrAlreadyExists         = NewError(CodeAlreadyExists, "resource already exists")
+	ErrInvalidCredential     = NewError(CodeInvalidCredential, "invalid credential")
+	ErrConflict              = NewError(CodeVersionConflict, "resource was modified by another request")
+	ErrForbidden             = NewError(CodeForbidden, "permission denied")
+	ErrRelatedResourceExists = NewError(CodeRelatedResources, "resource is still used by other resources")
+)
+
+// error for login throttling, distinct from ErrInvalidCredential
+var (
+	ErrAccountLocked   = NewError(CodeAccountLocked, "account is temporarily locked")
+	ErrTooManyAttempts = NewError(CodeTooManyAttempts, "too many login attempts")
+)
+
+// error for two-factor authentication
+var (
+	ErrTwoFactorRequired    = NewError(CodeTwoFactorRequired, "a two-factor code is required")
+	ErrInvalidTwoFactorCode = NewError(CodeInvalidTwoFactorCode, "invalid two-factor code")
+	ErrTwoFactorNotEnabled  = NewError(CodeTwoFactorNotEnabled, "two-factor authentication is not enabled")
+)
+
+// error for API keys over their rate limit
+var (
+	ErrRateLimited = NewError(CodeRateLimited, "rate limit exceeded")
+)
+
+// error for account states and emailed tokens
 var (
-	ErrNotFound          = errors.New("resource not found")
-	ErrAlreadyExists     = errors.New("resource already exists")
-	ErrInvalidCredential = errors.New("invalid credential")
+	ErrUserNotVerified = NewError(CodeUserNotVerified, "the user has not verified the email address")
+	ErrUserSuspended   = NewError(CodeUserSuspended, "the user is suspended")
+	ErrAlreadyVerified = NewError(CodeAlreadyVerified, "the email address is already verified")
+	ErrInvalidToken    = NewError(CodeInvalidToken, "the token is invalid, used or expired")
+)
+
+// error for captcha
+var (
+	ErrCaptchaRequired = NewError(CodeCaptchaRequired, "a solved captcha is required")
+	ErrCaptchaInvalid  = NewError(CodeCaptchaInvalid, "the captcha solution is wrong or expired")
 )
 
 // error for reservation service
 var (
-	ErrNoTicketsAvailable = errors.New("no tickets available")
-	ErrShowtimeNotExist   = errors.New("the showtime doesn't not exist")
-	ErrAlreadyReserved    = errors.New("the user have already have the same reservation")
+	ErrNoTicketsAvailable = NewError(CodeNoTicketsAvailable, "no tickets available")
+	ErrShowtimeNotExist   = NewError(CodeShowtimeNotFound, "the showtime doesn't exist")
+	ErrAlreadyReserved    = NewError(CodeAlreadyReserved, "the user already has a reservation for the showtime")
+	ErrSeatNotExist       = NewError(CodeSeatNotFound, "the seat doesn't exist in the hall")
+	ErrSeatBlocked        = NewError(CodeSeatBlocked, "the seat is blocked")
+	ErrSeatTaken          = NewError(CodeSeatTaken, "the seat is already reserved")
+)
+
+// error for maintenance
+var (
+	ErrHallUnderMaintenance = NewError(CodeHallUnderMaintenance, "the hall is under maintenance")
+)
+
+// error for idempotency keys
+var (
+	ErrIdempotencyKeyReused = NewError(CodeIdempotencyKeyReused,
+		"the idempotency key was already used for a different request")
+	ErrIdempotencyPending = NewError(CodeIdempotencyPending,
+		"a request with the idempotency key is still in progress")
+)
+
+// error for waiting rooms
+var (
+	ErrAdmissionRequired = NewError(CodeAdmissionRequired,
+		"the showtime has a waiting room, queue there for an admission token")
+	ErrAdmissionInvalid = NewError(CodeAdmissionInvalid,
+		"the admission token is invalid, expired or for another waiting room")
 )
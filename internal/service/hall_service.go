@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/qs-lzh/movie-reservation/internal/model"
 	"github.com/qs-lzh/movie-reservation/internal/repository"
@@ -9,43 +11,78 @@ import (
 )
 
 type HallService interface {
-	CreateHall(hall *model.Hall) error
-	UpdateHall(hall *model.Hall) error
-	DeleteHallByID(id uint) error
+	CreateHall(actor Actor, hall *model.Hall) error
+	UpdateHall(actor Actor, hall *model.Hall) error
+	PatchHall(actor Actor, id uint, version uint, patch model.HallPatch) (*model.Hall, error)
+	DeleteHallByID(actor Actor, id uint) error
 	GetHallByID(id uint) (*model.Hall, error)
-	GetHallByName(name string) (*model.Hall, error)
+	GetHallByName(cinemaID uint, name string) (*model.Hall, error)
+	GetHallsByCinemaID(cinemaID uint) ([]model.Hall, error)
 	GetAllHalls() ([]model.Hall, error)
 }
 
 type hallService struct {
 	db              *gorm.DB
 	repo            repository.HallRepo
+	cinemaRepo      repository.CinemaRepo
 	showtimeService ShowtimeService
+	catalog         CatalogCache
 }
 
 var _ HallService = (*hallService)(nil)
 
-func NewHallService(db *gorm.DB, hallRepo repository.HallRepo, showtimeService ShowtimeService) *hallService {
+func NewHallService(db *gorm.DB, hallRepo repository.HallRepo, cinemaRepo repository.CinemaRepo,
+	showtimeService ShowtimeService, catalog CatalogCache) *hallService {
 	return &hallService{
 		db:              db,
 		repo:            hallRepo,
+		cinemaRepo:      cinemaRepo,
 		showtimeService: showtimeService,
+		catalog:         catalog,
 	}
 }
 
-func (s *hallService) CreateHall(hall *model.Hall) error {
-	return s.repo.Create(hall)
+// CreateHall derives SeatCount, Rows and Cols from hall.Layout,
+// a hall without layout gets a rectangular Rows x Cols one.
+// Invalid input is reported as *ValidationError
+func (s *hallService) CreateHall(actor Actor, hall *model.Hall) error {
+	if err := authorize(actor, model.PermManageHalls); err != nil {
+		return err
+	}
+	if err := prepareHall(hall); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkCinemaExistsTx(tx, hall.CinemaID); err != nil {
+			return err
+		}
+		if err := s.checkNameUnusedTx(tx, hall.CinemaID, 0, hall.Name); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).Create(hall)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateCatalog(s.catalog, CatalogHalls)
+	return nil
 }
 
-func (s *hallService) UpdateHall(hall *model.Hall) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+func (s *hallService) UpdateHall(actor Actor, hall *model.Hall) error {
+	if err := authorize(actor, model.PermManageHalls); err != nil {
+		return err
+	}
+	if err := prepareHall(hall); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// verify no related Showtime
 		relatedShowtimes, err := s.showtimeService.GetShowtimesByHallIDTx(tx, hall.ID)
 		if err != nil {
 			return err
 		}
 		if len(relatedShowtimes) != 0 {
-			return ErrRelatedResourceExists
+			return showtimesInUseError(relatedShowtimes)
 		}
 
 		// verify that the hall with this ID exists
@@ -57,35 +94,108 @@ func (s *hallService) UpdateHall(hall *model.Hall) error {
 			return err
 		}
 
-		// check if the new title is already used by another
-		// because the title needs to be unique
-		if existinghall.Name != hall.Name {
-			anotherhall, err := s.repo.WithTx(tx).GetByName(hall.Name)
-			if err == nil && anotherhall != nil && anotherhall.ID != hall.ID {
-				return ErrAlreadyExists
+		// check if the new name is already used by another
+		// because the name needs to be unique within the cinema
+		if existinghall.CinemaID != hall.CinemaID {
+			if err := s.checkCinemaExistsTx(tx, hall.CinemaID); err != nil {
+				return err
 			}
-			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		}
+		if existinghall.CinemaID != hall.CinemaID || existinghall.Name != hall.Name {
+			if err := s.checkNameUnusedTx(tx, hall.CinemaID, hall.ID, hall.Name); err != nil {
 				return err
 			}
 		}
 
-		return s.repo.WithTx(tx).Update(hall)
+		if err := s.repo.WithTx(tx).Update(hall); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return ErrConflict
+			}
+			return err
+		}
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+	invalidateCatalog(s.catalog, CatalogHalls)
+	return nil
+}
+
+// PatchHall applies the non-nil fields of patch to the hall,
+// version must be the version the caller read, or ErrConflict is returned
+func (s *hallService) PatchHall(actor Actor, id uint, version uint, patch model.HallPatch) (*model.Hall, error) {
+	if err := authorize(actor, model.PermManageHalls); err != nil {
+		return nil, err
+	}
+	if err := prepareHallPatch(&patch); err != nil {
+		return nil, err
+	}
+
+	var hall *model.Hall
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// verify no related Showtime
+		relatedShowtimes, err := s.showtimeService.GetShowtimesByHallIDTx(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(relatedShowtimes) != 0 {
+			return showtimesInUseError(relatedShowtimes)
+		}
+
+		existingHall, err := s.repo.WithTx(tx).GetByID(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// the name needs to be unique within the cinema
+		if patch.Name != nil && *patch.Name != existingHall.Name {
+			if err := s.checkNameUnusedTx(tx, existingHall.CinemaID, id, *patch.Name); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.WithTx(tx).Patch(id, version, patch); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return ErrConflict
+			}
+			return err
+		}
+
+		hall, err = s.repo.WithTx(tx).GetByID(id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateCatalog(s.catalog, CatalogHalls)
+	return hall, nil
 }
 
-func (s *hallService) DeleteHallByID(id uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+func (s *hallService) DeleteHallByID(actor Actor, id uint) error {
+	if err := authorize(actor, model.PermManageHalls); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// verify no related showtime exists
 		relatedShowtimes, err := s.showtimeService.GetShowtimesByHallIDTx(tx, id)
 		if err != nil {
 			return err
 		}
 		if len(relatedShowtimes) != 0 {
-			return ErrRelatedResourceExists
+			return showtimesInUseError(relatedShowtimes)
 		}
 
 		return s.repo.WithTx(tx).DeleteByID(id)
 	})
+	if err != nil {
+		return err
+	}
+	invalidateCatalog(s.catalog, CatalogHalls)
+	return nil
 }
 
 func (s *hallService) GetHallByID(id uint) (*model.Hall, error) {
@@ -99,8 +209,8 @@ func (s *hallService) GetHallByID(id uint) (*model.Hall, error) {
 	return hall, nil
 }
 
-func (s *hallService) GetHallByName(name string) (*model.Hall, error) {
-	hall, err := s.repo.GetByName(name)
+func (s *hallService) GetHallByName(cinemaID uint, name string) (*model.Hall, error) {
+	hall, err := s.repo.GetByName(cinemaID, name)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
@@ -110,6 +220,10 @@ func (s *hallService) GetHallByName(name string) (*model.Hall, error) {
 	return hall, nil
 }
 
+func (s *hallService) GetHallsByCinemaID(cinemaID uint) ([]model.Hall, error) {
+	return s.repo.GetByCinemaID(cinemaID)
+}
+
 func (s *hallService) GetAllHalls() ([]model.Hall, error) {
 	halls, err := s.repo.ListAll()
 	if err != nil {
@@ -117,3 +231,157 @@ func (s *hallService) GetAllHalls() ([]model.Hall, error) {
 	}
 	return halls, nil
 }
+
+func (s *hallService) checkCinemaExistsTx(tx *gorm.DB, cinemaID uint) error {
+	if _, err := s.cinemaRepo.WithTx(tx).GetByID(cinemaID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// showtimesInUseError is ErrRelatedResourceExists listing the showtimes in its details
+func showtimesInUseError(showtimes []model.Showtime) error {
+	ids := make([]uint, 0, len(showtimes))
+	for _, showtime := range showtimes {
+		ids = append(ids, showtime.ID)
+	}
+	return ErrRelatedResourceExists.WithDetail("showtime_ids", ids)
+}
+
+// checkNameUnusedTx returns ErrAlreadyExists if another hall of the cinema has the name
+func (s *hallService) checkNameUnusedTx(tx *gorm.DB, cinemaID, id uint, name string) error {
+	anotherHall, err := s.repo.WithTx(tx).GetByName(cinemaID, name)
+	if err == nil && anotherHall != nil && anotherHall.ID != id {
+		return ErrAlreadyExists.WithDetail("id", anotherHall.ID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// limits of a hall
+const (
+	maxHallNameLen = 64
+	maxHallRows    = 100
+	maxHallCols    = 100
+)
+
+// prepareHall trims the input, validates it and derives SeatCount, Rows and Cols
+// from the layout, a hall without layout gets a rectangular Rows x Cols one
+func prepareHall(hall *model.Hall) error {
+	hall.Name = strings.TrimSpace(hall.Name)
+
+	v := &validator{}
+	v.requiredID("cinema_id", hall.CinemaID)
+	v.text("name", hall.Name, maxHallNameLen)
+	if hall.Layout.IsEmpty() {
+		v.between("rows", hall.Rows, 1, maxHallRows)
+		v.between("cols", hall.Cols, 1, maxHallCols)
+		if err := v.err(); err != nil {
+			return err
+		}
+		hall.Layout = model.RectangularLayout(hall.Rows, hall.Cols)
+	} else {
+		validateHallLayout(v, hall.Layout)
+		// values given along with the layout must agree with it
+		if hall.Rows != 0 && hall.Rows != len(hall.Layout.Rows) {
+			v.add("rows", RuleMismatch, "must equal the number of layout rows (%d)", len(hall.Layout.Rows))
+		}
+		if hall.Cols != 0 && hall.Cols != hall.Layout.Width() {
+			v.add("cols", RuleMismatch, "must equal the width of the layout (%d)", hall.Layout.Width())
+		}
+	}
+	// seat count is derived, a value given by the caller must agree with it
+	if hall.SeatCount != 0 && hall.SeatCount != hall.Layout.SeatCount() {
+		v.add("seat_count", RuleMismatch, "must equal the number of bookable seats (%d)", hall.Layout.SeatCount())
+	}
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	hall.ApplyLayout()
+	return nil
+}
+
+// prepareHallPatch trims and validates the non-nil fields of patch
+func prepareHallPatch(patch *model.HallPatch) error {
+	v := &validator{}
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		patch.Name = &name
+		v.text("name", name, maxHallNameLen)
+	}
+	if patch.Layout != nil {
+		validateHallLayout(v, *patch.Layout)
+	}
+	return v.err()
+}
+
+func validateHallLayout(v *validator, layout model.HallLayout) {
+	if layout.IsEmpty() {
+		v.add("layout.rows", RuleRequired, "must have at least one row")
+		return
+	}
+	if len(layout.Rows) > maxHallRows {
+		v.add("layout.rows", RuleRange, "must have at most %d rows", maxHallRows)
+	}
+
+	labels := make(map[string]bool, len(layout.Rows))
+	for i, row := range layout.Rows {
+		rowField := fmt.Sprintf("layout.rows[%d]", i)
+		if row.Label == "" {
+			v.add(rowField+".label", RuleRequired, "must not be empty")
+		} else if labels[row.Label] {
+			v.add(rowField+".label", RuleUnique, "row label %q is used twice", row.Label)
+		}
+		labels[row.Label] = true
+
+		if len(row.Cells) > maxHallCols {
+			v.add(rowField+".cells", RuleRange, "must have at most %d cells", maxHallCols)
+		}
+		if row.SeatCount() == 0 {
+			v.add(rowField+".cells", RuleRequired, "must have at least one seat")
+		}
+
+		for j, cell := range row.Cells {
+			cellField := fmt.Sprintf("%s.cells[%d]", rowField, j)
+			switch cell.Kind {
+			case model.CellSeat:
+				switch cell.Category {
+				case model.SeatStandard, model.SeatPremium, model.SeatWheelchair:
+				case model.SeatCompanion:
+					// a companion seat sits next to a wheelchair space
+					if !hasWheelchairNeighbour(row, j) {
+						v.add(cellField+".category", RuleInvalid, "companion seat must be next to a wheelchair space")
+					}
+				default:
+					v.add(cellField+".category", RuleOneOf, "must be one of %s, %s, %s, %s",
+						model.SeatStandard, model.SeatPremium, model.SeatWheelchair, model.SeatCompanion)
+				}
+			case model.CellAisle, model.CellGap:
+				if cell.Category != "" {
+					v.add(cellField+".category", RuleInvalid, "%s can't have a seat category", cell.Kind)
+				}
+			default:
+				v.add(cellField+".kind", RuleOneOf, "must be one of %s, %s, %s",
+					model.CellSeat, model.CellAisle, model.CellGap)
+			}
+		}
+	}
+}
+
+func hasWheelchairNeighbour(row model.LayoutRow, index int) bool {
+	for _, i := range []int{index - 1, index + 1} {
+		if i < 0 || i >= len(row.Cells) {
+			continue
+		}
+		if row.Cells[i].Kind == model.CellSeat && row.Cells[i].Category == model.SeatWheelchair {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestPatchHallRefusesAStaleVersion(t *testing.T) {
+	env := newTestEnv(t)
+	halls := env.hallService()
+	cinema := &model.Cinema{Name: "Odeon", Timezone: "Europe/Berlin"}
+	require.NoError(t, env.db.Create(cinema).Error)
+	hall := &model.Hall{CinemaID: cinema.ID, Name: "Hall 1", Layout: model.RectangularLayout(2, 3)}
+	require.NoError(t, halls.CreateHall(SystemActor, hall))
+	require.EqualValues(t, 1, hall.Version)
+
+	name := "Hall A"
+	layout := model.RectangularLayout(3, 3)
+	patched, err := halls.PatchHall(SystemActor, hall.ID, 1, model.HallPatch{Layout: &layout})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, patched.Version)
+	assert.Equal(t, 9, patched.SeatCount)
+	_, err = halls.PatchHall(SystemActor, hall.ID, 1, model.HallPatch{Name: &name})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	stored, err := halls.GetHallByID(hall.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hall 1", stored.Name)
+	assert.EqualValues(t, 2, stored.Version)
+
+	patched, err = halls.PatchHall(SystemActor, hall.ID, stored.Version, model.HallPatch{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "Hall A", patched.Name)
+	assert.EqualValues(t, 3, patched.Version)
+}
+
+// layoutRow builds a row from one character per cell: s standard, p premium,
+// w wheelchair, c companion, | aisle and . gap
+func layoutRow(label, cells string) model.LayoutRow {
+	categories := map[rune]model.SeatCategory{
+		's': model.SeatStandard, 'p': model.SeatPremium, 'w': model.SeatWheelchair, 'c': model.SeatCompanion,
+	}
+	row := model.LayoutRow{Label: label}
+	for _, cell := range cells {
+		switch cell {
+		case '|':
+			row.Cells = append(row.Cells, model.LayoutCell{Kind: model.CellAisle})
+		case '.':
+			row.Cells = append(row.Cells, model.LayoutCell{Kind: model.CellGap})
+		default:
+			row.Cells = append(row.Cells, model.LayoutCell{Kind: model.CellSeat, Category: categories[cell]})
+		}
+	}
+	return row
+}
+
+func TestCreateHallDerivesTheSeatsFromTheLayout(t *testing.T) {
+	env := newTestEnv(t)
+	halls := env.hallService()
+	cinema := &model.Cinema{Name: "Odeon", Timezone: "Europe/Berlin"}
+	require.NoError(t, env.db.Create(cinema).Error)
+
+	hall := &model.Hall{CinemaID: cinema.ID, Name: "Hall 1", Layout: model.HallLayout{Rows: []model.LayoutRow{
+		layoutRow("A", "wc|ss"),
+		layoutRow("B", ".pp|ss"),
+	}}}
+	require.NoError(t, halls.CreateHall(SystemActor, hall))
+	assert.Equal(t, 8, hall.SeatCount)
+	assert.Equal(t, 2, hall.Rows)
+	assert.Equal(t, 6, hall.Cols)
+
+	// without a layout the hall gets a rectangular one
+	hall = &model.Hall{CinemaID: cinema.ID, Name: "Hall 2", Rows: 3, Cols: 4}
+	require.NoError(t, halls.CreateHall(SystemActor, hall))
+	assert.Equal(t, 12, hall.SeatCount)
+	assert.Len(t, hall.Layout.Rows, 3)
+}
+
+func TestCreateHallRejectsInvalidLayouts(t *testing.T) {
+	env := newTestEnv(t)
+	halls := env.hallService()
+	cinema := &model.Cinema{Name: "Odeon", Timezone: "Europe/Berlin"}
+	require.NoError(t, env.db.Create(cinema).Error)
+
+	tooWide := layoutRow("A", strings.Repeat("s", maxHallCols+1))
+	tooManyRows := model.RectangularLayout(maxHallRows+1, 1)
+	tests := []struct {
+		name   string
+		hall   model.Hall
+		fields []FieldError
+	}{
+		{
+			name: "rows and cols out of range",
+			hall: model.Hall{Rows: 0, Cols: maxHallCols + 1},
+			fields: []FieldError{
+				{Field: "rows", Rule: RuleRange, Message: "must be between 1 and 100"},
+				{Field: "cols", Rule: RuleRange, Message: "must be between 1 and 100"},
+			},
+		},
+		{
+			name:   "too many rows",
+			hall:   model.Hall{Layout: tooManyRows},
+			fields: []FieldError{{Field: "layout.rows", Rule: RuleRange, Message: "must have at most 100 rows"}},
+		},
+		{
+			name: "too many cells",
+			hall: model.Hall{Layout: model.HallLayout{Rows: []model.LayoutRow{tooWide}}},
+			fields: []FieldError{
+				{Field: "layout.rows[0].cells", Rule: RuleRange, Message: "must have at most 100 cells"},
+			},
+		},
+		{
+			name: "companion away from a wheelchair space",
+			hall: model.Hall{Layout: model.HallLayout{Rows: []model.LayoutRow{layoutRow("A", "w|cs")}}},
+			fields: []FieldError{{Field: "layout.rows[0].cells[2].category", Rule: RuleInvalid,
+				Message: "companion seat must be next to a wheelchair space"}},
+		},
+		{
+			name: "row without seats and a repeated label",
+			hall: model.Hall{Layout: model.HallLayout{Rows: []model.LayoutRow{
+				layoutRow("A", "ss"), layoutRow("A", ".|."),
+			}}},
+			fields: []FieldError{
+				{Field: "layout.rows[1].label", Rule: RuleUnique, Message: `row label "A" is used twice`},
+				{Field: "layout.rows[1].cells", Rule: RuleRequired, Message: "must have at least one seat"},
+			},
+		},
+		{
+			name: "rows disagreeing with the layout",
+			hall: model.Hall{Rows: 3, SeatCount: 5, Layout: model.RectangularLayout(2, 2)},
+			fields: []FieldError{
+				{Field: "rows", Rule: RuleMismatch, Message: "must equal the number of layout rows (2)"},
+				{Field: "seat_count", Rule: RuleMismatch, Message: "must equal the number of bookable seats (4)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hall := tt.hall
+			hall.CinemaID, hall.Name = cinema.ID, "Hall"
+			err := halls.CreateHall(SystemActor, &hall)
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.fields, validationErr.Fields)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// IdempotencyRecord is what is kept for an idempotency key: the hash of the request
+// that claimed it and, once that request is done, its response
+type IdempotencyRecord struct {
+	RequestHash string
+	Done        bool
+	Response    IdempotentResponse
+}
+
+// IdempotentResponse is the response replayed to the retries of a request
+type IdempotentResponse struct {
+	Status int
+	Header map[string]string
+	Body   []byte
+}
+
+// IdempotencyStore keeps IdempotencyRecords, the keys are expected to be scoped by the caller
+type IdempotencyStore interface {
+	// Claim records a pending request for the key if the key is free,
+	// otherwise it returns the record of the key and false
+	Claim(key string, requestHash string, ttl time.Duration) (IdempotencyRecord, bool, error)
+	// Complete stores the response of the request that claimed the key
+	Complete(key string, record IdempotencyRecord, ttl time.Duration) error
+	// Release frees the key, so the request can be retried
+	Release(key string) error
+}
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+}
+
+type memoryIdempotencyRecord struct {
+	IdempotencyRecord
+	expiresAt time.Time
+}
+
+var _ IdempotencyStore = (*memoryIdempotencyStore)(nil)
+
+// NewMemoryIdempotencyStore keeps the records in process,
+// for single node deployments, tests and as fallback of the redis store
+func NewMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{
+		records: make(map[string]memoryIdempotencyRecord),
+	}
+}
+
+func (s *memoryIdempotencyStore) Claim(key string, requestHash string, ttl time.Duration) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && time.Now().Before(record.expiresAt) {
+		return record.IdempotencyRecord, false, nil
+	}
+	record := IdempotencyRecord{RequestHash: requestHash}
+	s.records[key] = memoryIdempotencyRecord{
+		IdempotencyRecord: record,
+		expiresAt:         time.Now().Add(ttl),
+	}
+	return record, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(key string, record IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = memoryIdempotencyRecord{
+		IdempotencyRecord: record,
+		expiresAt:         time.Now().Add(ttl),
+	}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+type redisIdempotencyStore struct {
+	cache    cache.Store
+	fallback IdempotencyStore
+}
+
+var _ IdempotencyStore = (*redisIdempotencyStore)(nil)
+
+// NewRedisIdempotencyStore shares the records between API instances,
+// when redis fails the fallback store is used so retries to the same instance are still caught
+func NewRedisIdempotencyStore(cache cache.Store, fallback IdempotencyStore) *redisIdempotencyStore {
+	return &redisIdempotencyStore{
+		cache:    cache,
+		fallback: fallback,
+	}
+}
+
+func (s *redisIdempotencyStore) Claim(key string, requestHash string, ttl time.Duration) (IdempotencyRecord, bool, error) {
+	record := IdempotencyRecord{RequestHash: requestHash}
+	claimed, err := s.cache.SetIfAbsent(key, record, ttl)
+	if err != nil {
+		return s.fallback.Claim(key, requestHash, ttl)
+	}
+	if claimed {
+		return record, true, nil
+	}
+	var existing IdempotencyRecord
+	err = s.cache.Get(key, &existing)
+	if errors.Is(err, cache.ErrNotFound) {
+		// expired in between, the key is free again
+		return s.Claim(key, requestHash, ttl)
+	}
+	if err != nil {
+		return s.fallback.Claim(key, requestHash, ttl)
+	}
+	return existing, false, nil
+}
+
+func (s *redisIdempotencyStore) Complete(key string, record IdempotencyRecord, ttl time.Duration) error {
+	if err := s.cache.Set(key, record, ttl); err != nil {
+		return s.fallback.Complete(key, record, ttl)
+	}
+	return nil
+}
+
+func (s *redisIdempotencyStore) Release(key string) error {
+	// keys claimed while redis was down live in the fallback
+	if err := s.fallback.Release(key); err != nil {
+		return err
+	}
+	_, _ = s.cache.Delete(key)
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+func TestIdempotencyStoreClaimsAKeyOnce(t *testing.T) {
+	env := newTestEnv(t)
+	stores := map[string]IdempotencyStore{
+		"memory": NewMemoryIdempotencyStore(),
+		"redis":  NewRedisIdempotencyStore(env.redis, NewMemoryIdempotencyStore()),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			record, claimed, err := store.Claim("idempotency:1:0:booking", "hash-1", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+			assert.Equal(t, IdempotencyRecord{RequestHash: "hash-1"}, record)
+
+			// a retry while the request runs sees it pending, whatever its request
+			record, claimed, err = store.Claim("idempotency:1:0:booking", "hash-2", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, claimed)
+			assert.Equal(t, "hash-1", record.RequestHash)
+			assert.False(t, record.Done)
+			// the same key of another caller is another key
+			_, claimed, err = store.Claim("idempotency:2:0:booking", "hash-1", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+
+			record.Done = true
+			record.Response = IdempotentResponse{Status: 201, Header: map[string]string{"Location": "/reservations/1"},
+				Body: []byte(`{"id":1}`)}
+			require.NoError(t, store.Complete("idempotency:1:0:booking", record, time.Hour))
+			replayed, claimed, err := store.Claim("idempotency:1:0:booking", "hash-1", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, claimed)
+			assert.Equal(t, record, replayed)
+
+			// a released key can be claimed again
+			require.NoError(t, store.Release("idempotency:2:0:booking"))
+			_, claimed, err = store.Claim("idempotency:2:0:booking", "hash-3", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+		})
+	}
+}
+
+func TestRedisIdempotencyStoreFallsBackWhenRedisFails(t *testing.T) {
+	server := miniredis.RunT(t)
+	down := cache.NewRedisCache(server.Addr())
+	t.Cleanup(func() { _ = down.Close() })
+	server.SetError("redis is down")
+	fallback := NewMemoryIdempotencyStore()
+	store := NewRedisIdempotencyStore(down, fallback)
+
+	_, claimed, err := store.Claim("idempotency:1:0:booking", "hash-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	_, claimed, err = store.Claim("idempotency:1:0:booking", "hash-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, store.Release("idempotency:1:0:booking"))
+	_, claimed, err = fallback.Claim("idempotency:1:0:booking", "hash-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
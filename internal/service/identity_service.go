@@ -0,0 +1,266 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// IdentityService signs users in with identities of external providers
+// and manages the identities linked to a user
+type IdentityService interface {
+	// SignInWithIdentity returns the user linked to the identity. On the first sign-in
+	// it links the user with the same email if both sides verified it,
+	// and creates a user otherwise
+	SignInWithIdentity(identity model.ExternalIdentity) (*model.User, error)
+	LinkIdentity(actor Actor, userID uint, identity model.ExternalIdentity) error
+	UnlinkIdentity(actor Actor, userID, identityID uint) error
+	GetIdentities(actor Actor, userID uint) ([]model.UserIdentity, error)
+}
+
+type identityService struct {
+	db       *gorm.DB
+	repo     repository.UserIdentityRepo
+	userRepo repository.UserRepo
+	now      func() time.Time
+}
+
+var _ IdentityService = (*identityService)(nil)
+
+func NewIdentityService(db *gorm.DB, identityRepo repository.UserIdentityRepo, userRepo repository.UserRepo) *identityService {
+	return &identityService{
+		db:       db,
+		repo:     identityRepo,
+		userRepo: userRepo,
+		now:      time.Now,
+	}
+}
+
+func (s *identityService) SignInWithIdentity(identity model.ExternalIdentity) (*model.User, error) {
+	if err := validateExternalIdentity(&identity); err != nil {
+		return nil, err
+	}
+	var user *model.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		linked, err := s.repo.WithTx(tx).GetBySubject(identity.Provider, identity.Subject)
+		if err == nil {
+			user, err = s.getUserTx(tx, linked.UserID)
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		user, err = s.userForNewIdentityTx(tx, identity)
+		if err != nil {
+			return err
+		}
+		return s.createIdentityTx(tx, user.ID, identity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == model.UserSuspended {
+		return nil, ErrUserSuspended
+	}
+	return user, nil
+}
+
+// userForNewIdentityTx finds the user a new identity belongs to, or creates one.
+// Linking by email needs the address verified by the provider and by the user,
+// otherwise whoever controls either side could take over the other account
+func (s *identityService) userForNewIdentityTx(tx *gorm.DB, identity model.ExternalIdentity) (*model.User, error) {
+	if identity.Email != "" {
+		existing, err := s.userRepo.WithTx(tx).GetByEmail(identity.Email)
+		if err == nil {
+			if identity.EmailVerified && existing.EmailVerifiedAt != nil {
+				return existing, nil
+			}
+			// the user has to sign in with the password and link the identity
+			return nil, ErrAlreadyExists
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	name, err := s.uniqueNameTx(tx, identity)
+	if err != nil {
+		return nil, err
+	}
+	user := &model.User{
+		Name: name,
+		// no password, it can be set with a password reset
+		HashedPassword: "",
+		Role:           model.RoleUser,
+		Status:         model.UserUnverified,
+		Email:          identity.Email,
+	}
+	if identity.Email != "" && identity.EmailVerified {
+		verifiedAt := s.now()
+		user.Status = model.UserActive
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	if err := s.userRepo.WithTx(tx).Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// LinkIdentity links another identity to the user,
+// it returns ErrAlreadyExists if the identity is linked to any user
+func (s *identityService) LinkIdentity(actor Actor, userID uint, identity model.ExternalIdentity) error {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return err
+	}
+	if err := validateExternalIdentity(&identity); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.getUserTx(tx, userID); err != nil {
+			return err
+		}
+		_, err := s.repo.WithTx(tx).GetBySubject(identity.Provider, identity.Subject)
+		if err == nil {
+			return ErrAlreadyExists
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return s.createIdentityTx(tx, userID, identity)
+	})
+}
+
+// UnlinkIdentity removes an identity of the user,
+// the last one can't be removed while the user has no password
+func (s *identityService) UnlinkIdentity(actor Actor, userID, identityID uint) error {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		identity, err := s.repo.WithTx(tx).GetByID(identityID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if identity.UserID != userID {
+			return ErrNotFound
+		}
+
+		user, err := s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		identities, err := s.repo.WithTx(tx).GetByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) == 1 && user.HashedPassword == "" {
+			v := &validator{}
+			v.add("identity", RuleInvalid, "is the only way to sign in, set a password first")
+			return v.err()
+		}
+		return s.repo.WithTx(tx).DeleteByID(identityID)
+	})
+}
+
+func (s *identityService) GetIdentities(actor Actor, userID uint) ([]model.UserIdentity, error) {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByUserID(userID)
+}
+
+func (s *identityService) createIdentityTx(tx *gorm.DB, userID uint, identity model.ExternalIdentity) error {
+	return s.repo.WithTx(tx).Create(&model.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+}
+
+func (s *identityService) getUserTx(tx *gorm.DB, userID uint) (*model.User, error) {
+	user, err := s.userRepo.WithTx(tx).GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// uniqueNameTx derives a user name from the identity's name or email,
+// with a numeric suffix when it is taken
+func (s *identityService) uniqueNameTx(tx *gorm.DB, identity model.ExternalIdentity) (string, error) {
+	base := identity.Name
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = sanitizeUserName(base)
+
+	for i := 1; i <= 20; i++ {
+		name := base
+		if i > 1 {
+			name = base + "-" + strconv.Itoa(i)
+		}
+		_, err := s.userRepo.WithTx(tx).GetByName(name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	// a popular name, fall back to a random suffix
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base + "-" + hex.EncodeToString(b), nil
+}
+
+// sanitizeUserName keeps the characters allowed in user names
+// and leaves room for a suffix
+func sanitizeUserName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-", r)):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('.')
+		}
+	}
+	name = b.String()
+	if len(name) > maxUserNameLen-10 {
+		name = name[:maxUserNameLen-10]
+	}
+	if len(name) < minUserNameLen || strings.HasPrefix(name, deletedUserNamePrefix) {
+		name = "user" + name
+	}
+	return name
+}
+
+func validateExternalIdentity(identity *model.ExternalIdentity) error {
+	identity.Email = strings.ToLower(strings.TrimSpace(identity.Email))
+	identity.Name = strings.TrimSpace(identity.Name)
+	identity.Provider = strings.TrimSpace(identity.Provider)
+	v := &validator{}
+	v.text("provider", identity.Provider, 64)
+	v.text("subject", identity.Subject, 255)
+	v.maxLen("email", identity.Email, maxUserEmailLen)
+	return v.err()
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// AttemptRecord counts the failed logins of an account or client IP
+// since the record was last reset or expired
+type AttemptRecord struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// AttemptStore keeps AttemptRecords, a record expires ttl after its last failure
+type AttemptStore interface {
+	Get(key string) (AttemptRecord, error)
+	RecordFailure(key string, at time.Time, ttl time.Duration) (AttemptRecord, error)
+	Reset(key string) error
+}
+
+type memoryAttemptStore struct {
+	mu      sync.Mutex
+	records map[string]memoryAttempt
+}
+
+type memoryAttempt struct {
+	AttemptRecord
+	expiresAt time.Time
+}
+
+var _ AttemptStore = (*memoryAttemptStore)(nil)
+
+// NewMemoryAttemptStore keeps the records in process,
+// for single node deployments, tests and as fallback of the redis store
+func NewMemoryAttemptStore() *memoryAttemptStore {
+	return &memoryAttemptStore{
+		records: make(map[string]memoryAttempt),
+	}
+}
+
+func (s *memoryAttemptStore) Get(key string) (AttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || time.Now().After(record.expiresAt) {
+		delete(s.records, key)
+		return AttemptRecord{}, nil
+	}
+	return record.AttemptRecord, nil
+}
+
+func (s *memoryAttemptStore) RecordFailure(key string, at time.Time, ttl time.Duration) (AttemptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || time.Now().After(record.expiresAt) {
+		record = memoryAttempt{}
+	}
+	record.Failures++
+	record.LastFailure = at
+	record.expiresAt = time.Now().Add(ttl)
+	s.records[key] = record
+	return record.AttemptRecord, nil
+}
+
+func (s *memoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+type redisAttemptStore struct {
+	cache    cache.Store
+	fallback AttemptStore
+}
+
+var _ AttemptStore = (*redisAttemptStore)(nil)
+
+// NewRedisAttemptStore shares the records between API instances,
+// when redis fails the fallback store is used so logins keep being throttled
+func NewRedisAttemptStore(cache cache.Store, fallback AttemptStore) *redisAttemptStore {
+	return &redisAttemptStore{
+		cache:    cache,
+		fallback: fallback,
+	}
+}
+
+func (s *redisAttemptStore) Get(key string) (AttemptRecord, error) {
+	var record AttemptRecord
+	err := s.cache.Get(key, &record)
+	if err == nil {
+		return record, nil
+	}
+	if errors.Is(err, cache.ErrNotFound) {
+		return AttemptRecord{}, nil
+	}
+	return s.fallback.Get(key)
+}
+
+func (s *redisAttemptStore) RecordFailure(key string, at time.Time, ttl time.Duration) (AttemptRecord, error) {
+	// the counter is incremented atomically, the record is written after it
+	failures, err := s.cache.IncrWithTTL(key+":count", ttl)
+	if err != nil {
+		return s.fallback.RecordFailure(key, at, ttl)
+	}
+	record := AttemptRecord{
+		Failures:    int(failures),
+		LastFailure: at,
+	}
+	if err := s.cache.Set(key, record, ttl); err != nil {
+		return s.fallback.RecordFailure(key, at, ttl)
+	}
+	return record, nil
+}
+
+func (s *redisAttemptStore) Reset(key string) error {
+	// records written while redis was down live in the fallback
+	if err := s.fallback.Reset(key); err != nil {
+		return err
+	}
+	// while redis is down there is nothing to reset in it
+	_, _ = s.cache.Delete(key)
+	_, _ = s.cache.Delete(key + ":count")
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// login throttling policy
+const (
+	// failures of an account before each attempt has to wait,
+	// the wait doubles with every further failure up to loginMaxBackoff
+	loginBackoffThreshold = 3
+	loginBaseBackoff      = time.Second
+	loginMaxBackoff       = time.Minute
+	// failures of an account before logging in requires a solved captcha
+	loginCaptchaThreshold = 3
+	// failures of an account before it is locked for loginLockoutDuration
+	loginLockoutThreshold = 10
+	loginLockoutDuration  = 15 * time.Minute
+	// failures from one IP, over every account, before it is refused for loginAttemptWindow
+	loginIPThreshold = 50
+	// failures are forgotten this long after the last one
+	loginAttemptWindow = 15 * time.Minute
+)
+
+// RetryAfterError is returned for a throttled login or API key,
+// it matches ErrAccountLocked, ErrTooManyAttempts or ErrRateLimited with errors.Is
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// LoginThrottle counts failed logins per account and per client IP,
+// slowing down and then locking the account, and refusing noisy IPs
+type LoginThrottle interface {
+	// Check returns a *RetryAfterError if the attempt must be refused
+	Check(name, ip string) error
+	RecordFailure(name, ip string) error
+	// RequiresCaptcha reports whether the account failed often enough
+	// that the next attempt has to come with a solved captcha
+	RequiresCaptcha(name string) (bool, error)
+	// Reset forgets the failures of the account, on success or by an admin
+	Reset(name string) error
+}
+
+type loginThrottle struct {
+	store AttemptStore
+	now   func() time.Time
+}
+
+var _ LoginThrottle = (*loginThrottle)(nil)
+
+func NewLoginThrottle(store AttemptStore) *loginThrottle {
+	return &loginThrottle{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+func accountAttemptKey(name string) string {
+	return "login:failures:account:" + strings.ToLower(strings.TrimSpace(name))
+}
+
+func ipAttemptKey(ip string) string {
+	return "login:failures:ip:" + ip
+}
+
+func (t *loginThrottle) Check(name, ip string) error {
+	now := t.now()
+
+	account, err := t.store.Get(accountAttemptKey(name))
+	if err != nil {
+		return err
+	}
+	if account.Failures >= loginLockoutThreshold {
+		if wait := account.LastFailure.Add(loginLockoutDuration).Sub(now); wait > 0 {
+			return &RetryAfterError{Err: ErrAccountLocked, RetryAfter: wait}
+		}
+	} else if account.Failures >= loginBackoffThreshold {
+		backoff := min(loginBaseBackoff<<(account.Failures-loginBackoffThreshold), loginMaxBackoff)
+		if wait := account.LastFailure.Add(backoff).Sub(now); wait > 0 {
+			return &RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+
+	if ip == "" {
+		return nil
+	}
+	client, err := t.store.Get(ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if client.Failures >= loginIPThreshold {
+		if wait := client.LastFailure.Add(loginAttemptWindow).Sub(now); wait > 0 {
+			return &RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+func (t *loginThrottle) RecordFailure(name, ip string) error {
+	now := t.now()
+	// keep the account record at least as long as its lockout
+	if _, err := t.store.RecordFailure(accountAttemptKey(name), now, max(loginAttemptWindow, loginLockoutDuration)); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	_, err := t.store.RecordFailure(ipAttemptKey(ip), now, loginAttemptWindow)
+	return err
+}
+
+func (t *loginThrottle) RequiresCaptcha(name string) (bool, error) {
+	account, err := t.store.Get(accountAttemptKey(name))
+	if err != nil {
+		return false, err
+	}
+	return account.Failures >= loginCaptchaThreshold, nil
+}
+
+func (t *loginThrottle) Reset(name string) error {
+	return t.store.Reset(accountAttemptKey(name))
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"strconv"
+	"strings"
+)
+
+// length of the signature in a lookup code, 10 bytes are 16 base32 characters
+const lookupCodeMACLen = 10
+
+var lookupCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// LookupCodes signs reservation IDs into codes handed to guests,
+// like "1234-K7Q2M4XZ9PABCDEF", only the holder of a code can use it
+// since it can't be made without the secret
+type LookupCodes struct {
+	secret []byte
+}
+
+func NewLookupCodes(secret string) *LookupCodes {
+	return &LookupCodes{
+		secret: []byte(secret),
+	}
+}
+
+func (c *LookupCodes) Sign(reservationID uint) string {
+	id := strconv.FormatUint(uint64(reservationID), 10)
+	return id + "-" + lookupCodeEncoding.EncodeToString(c.mac(id))
+}
+
+// Verify returns the reservation ID of a code signed with the secret,
+// codes are read case-insensitively as people type them in
+func (c *LookupCodes) Verify(code string) (uint, bool) {
+	id, signature, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(code)), "-")
+	if !ok {
+		return 0, false
+	}
+	reservationID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	mac, err := lookupCodeEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.mac(id)) {
+		return 0, false
+	}
+	return uint(reservationID), true
+}
+
+func (c *LookupCodes) mac(id string) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write([]byte("reservation:" + id))
+	return h.Sum(nil)[:lookupCodeMACLen]
+}
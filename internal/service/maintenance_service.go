@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// MaintenanceService blocks seats and halls without deleting anything,
+// ReservationService treats them as unavailable
+// and ShowtimeService refuses to schedule into a maintenance window
+type MaintenanceService interface {
+	BlockSeat(actor Actor, block *model.SeatBlock) error
+	UnblockSeat(actor Actor, blockID uint) error
+	GetSeatBlocksByHallID(hallID uint) ([]model.SeatBlock, error)
+	ScheduleHallMaintenance(actor Actor, maintenance *model.HallMaintenance) error
+	CancelHallMaintenance(actor Actor, maintenanceID uint) error
+	GetHallMaintenancesByHallID(hallID uint) ([]model.HallMaintenance, error)
+}
+
+type maintenanceService struct {
+	db              *gorm.DB
+	seatBlockRepo   repository.SeatBlockRepo
+	maintenanceRepo repository.HallMaintenanceRepo
+	hallRepo        repository.HallRepo
+	showtimeRepo    repository.ShowtimeRepo
+	events          SeatEvents
+}
+
+var _ MaintenanceService = (*maintenanceService)(nil)
+
+func NewMaintenanceService(db *gorm.DB, seatBlockRepo repository.SeatBlockRepo,
+	maintenanceRepo repository.HallMaintenanceRepo, hallRepo repository.HallRepo,
+	showtimeRepo repository.ShowtimeRepo, seatEvents SeatEvents) *maintenanceService {
+	return &maintenanceService{
+		db:              db,
+		seatBlockRepo:   seatBlockRepo,
+		maintenanceRepo: maintenanceRepo,
+		hallRepo:        hallRepo,
+		showtimeRepo:    showtimeRepo,
+		events:          seatEvents,
+	}
+}
+
+// the reason of a seat block or a maintenance window
+const maxMaintenanceReasonLen = 255
+
+// BlockSeat blocks a seat either for block.ShowtimeID,
+// whose hall is then used as block.HallID,
+// or for block.HallID within [block.StartAt, block.EndAt)
+func (s *maintenanceService) BlockSeat(actor Actor, block *model.SeatBlock) error {
+	if err := authorize(actor, model.PermManageMaintenance); err != nil {
+		return err
+	}
+	var showtimeIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		v := &validator{}
+		if block.ShowtimeID != nil {
+			if block.StartAt != nil || block.EndAt != nil {
+				v.add("showtime_id", RuleInvalid, "can't be combined with start_at and end_at")
+				return v.err()
+			}
+			showtime, err := s.showtimeRepo.WithTx(tx).GetByID(*block.ShowtimeID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrShowtimeNotExist
+				}
+				return err
+			}
+			block.HallID = showtime.HallID
+		} else {
+			v.requiredID("hall_id", block.HallID)
+			validateTimeRange(v, block.StartAt, block.EndAt)
+		}
+		v.maxLen("reason", block.Reason, maxMaintenanceReasonLen)
+		if err := v.err(); err != nil {
+			return err
+		}
+
+		hall, err := s.hallRepo.WithTx(tx).GetByID(block.HallID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		seatLayout := hall.SeatLayout()
+		if _, ok := seatLayout.Seat(block.SeatID); !ok {
+			return ErrSeatNotExist
+		}
+
+		if err := s.seatBlockRepo.WithTx(tx).Create(block); err != nil {
+			return err
+		}
+		showtimeIDs, err = s.blockedShowtimesTx(tx, block)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	publishSeatEvents(s.events, showtimeIDs...)
+	return nil
+}
+
+func (s *maintenanceService) UnblockSeat(actor Actor, blockID uint) error {
+	if err := authorize(actor, model.PermManageMaintenance); err != nil {
+		return err
+	}
+	var showtimeIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		block, err := s.seatBlockRepo.WithTx(tx).GetByID(blockID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := s.seatBlockRepo.WithTx(tx).DeleteByID(blockID); err != nil {
+			return err
+		}
+		showtimeIDs, err = s.blockedShowtimesTx(tx, block)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	publishSeatEvents(s.events, showtimeIDs...)
+	return nil
+}
+
+func (s *maintenanceService) GetSeatBlocksByHallID(hallID uint) ([]model.SeatBlock, error) {
+	return s.seatBlockRepo.GetByHallID(hallID)
+}
+
+func (s *maintenanceService) ScheduleHallMaintenance(actor Actor, maintenance *model.HallMaintenance) error {
+	if err := authorize(actor, model.PermManageMaintenance); err != nil {
+		return err
+	}
+	v := &validator{}
+	v.requiredID("hall_id", maintenance.HallID)
+	validateTimeRange(v, &maintenance.StartAt, &maintenance.EndAt)
+	v.maxLen("reason", maintenance.Reason, maxMaintenanceReasonLen)
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	var showtimeIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.hallRepo.WithTx(tx).GetByID(maintenance.HallID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := s.maintenanceRepo.WithTx(tx).Create(maintenance); err != nil {
+			return err
+		}
+		var err error
+		showtimeIDs, err = s.showtimesOverlappingTx(tx, maintenance.HallID, maintenance.StartAt, maintenance.EndAt)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	publishSeatEvents(s.events, showtimeIDs...)
+	return nil
+}
+
+func (s *maintenanceService) CancelHallMaintenance(actor Actor, maintenanceID uint) error {
+	if err := authorize(actor, model.PermManageMaintenance); err != nil {
+		return err
+	}
+	var showtimeIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		maintenance, err := s.maintenanceRepo.WithTx(tx).GetByID(maintenanceID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := s.maintenanceRepo.WithTx(tx).DeleteByID(maintenanceID); err != nil {
+			return err
+		}
+		showtimeIDs, err = s.showtimesOverlappingTx(tx, maintenance.HallID, maintenance.StartAt, maintenance.EndAt)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	publishSeatEvents(s.events, showtimeIDs...)
+	return nil
+}
+
+// blockedShowtimesTx returns the showtimes the seat block applies to
+func (s *maintenanceService) blockedShowtimesTx(tx *gorm.DB, block *model.SeatBlock) ([]uint, error) {
+	if block.ShowtimeID != nil {
+		return []uint{*block.ShowtimeID}, nil
+	}
+	return s.showtimesWithinTx(tx, block.HallID, *block.StartAt, *block.EndAt)
+}
+
+// showtimesWithinTx returns the showtimes of the hall starting within [startAt, endAt),
+// which seat blocks apply to
+func (s *maintenanceService) showtimesWithinTx(tx *gorm.DB, hallID uint, startAt, endAt time.Time) ([]uint, error) {
+	return s.showtimesMatchingTx(tx, hallID, func(showtime *model.Showtime) bool {
+		return !showtime.StartAt.Before(startAt) && showtime.StartAt.Before(endAt)
+	})
+}
+
+// showtimesOverlappingTx returns the showtimes of the hall running at some time
+// within [startAt, endAt), which maintenance windows apply to
+func (s *maintenanceService) showtimesOverlappingTx(tx *gorm.DB, hallID uint, startAt, endAt time.Time) ([]uint, error) {
+	return s.showtimesMatchingTx(tx, hallID, func(showtime *model.Showtime) bool {
+		return showtime.StartAt.Before(endAt) && showtime.EndAt().After(startAt)
+	})
+}
+
+func (s *maintenanceService) showtimesMatchingTx(tx *gorm.DB, hallID uint, match func(*model.Showtime) bool) ([]uint, error) {
+	showtimes, err := s.showtimeRepo.WithTx(tx).GetByHallID(hallID)
+	if err != nil {
+		return nil, err
+	}
+	var showtimeIDs []uint
+	for i := range showtimes {
+		if match(&showtimes[i]) {
+			showtimeIDs = append(showtimeIDs, showtimes[i].ID)
+		}
+	}
+	return showtimeIDs, nil
+}
+
+func (s *maintenanceService) GetHallMaintenancesByHallID(hallID uint) ([]model.HallMaintenance, error) {
+	return s.maintenanceRepo.GetByHallID(hallID)
+}
+
+func validateTimeRange(v *validator, startAt, endAt *time.Time) {
+	if startAt == nil || startAt.IsZero() {
+		v.add("start_at", RuleRequired, "is required")
+	}
+	if endAt == nil || endAt.IsZero() {
+		v.add("end_at", RuleRequired, "is required")
+	}
+	if startAt != nil && endAt != nil && !endAt.After(*startAt) {
+		v.add("end_at", RuleRange, "must be after start_at")
+	}
+}
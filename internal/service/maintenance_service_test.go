@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func (e *testEnv) maintenanceService() *maintenanceService {
+	return NewMaintenanceService(e.db, e.seatBlocks, e.maintenances, e.halls, e.showtimes, nil)
+}
+
+func TestMaintenanceWindowsApplyUntilTheShowtimeEnds(t *testing.T) {
+	env := newTestEnv(t)
+	maintenance := env.maintenanceService()
+	showtimes := env.showtimeService()
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+
+	// the movie runs 155 minutes, a window starting two hours in still overlaps
+	window := &model.HallMaintenance{HallID: showtime.HallID, Reason: "projector",
+		StartAt: showtime.StartAt.Add(2 * time.Hour), EndAt: showtime.StartAt.Add(4 * time.Hour)}
+	require.NoError(t, maintenance.ScheduleHallMaintenance(SystemActor, window))
+	_, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrHallUnderMaintenance)
+	_, err = showtimes.CreateShowtime(SystemActor, showtime.MovieID, showtime.StartAt.Add(time.Hour), showtime.HallID)
+	assert.ErrorIs(t, err, ErrHallUnderMaintenance)
+	// a showtime ending when the window starts doesn't
+	_, err = showtimes.CreateShowtime(SystemActor, showtime.MovieID, window.StartAt.Add(-155*time.Minute),
+		showtime.HallID)
+	assert.NoError(t, err)
+
+	require.NoError(t, maintenance.CancelHallMaintenance(SystemActor, window.ID))
+	_, err = reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	assert.NoError(t, err)
+}
+
+func TestSeatBlocksApplyToTheShowtimesStartingWithinTheirRange(t *testing.T) {
+	env := newTestEnv(t)
+	maintenance := env.maintenanceService()
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+
+	// ending before the showtime starts, the block doesn't apply even though the day overlaps
+	before := showtime.StartAt.Add(-time.Minute)
+	early := &model.SeatBlock{HallID: showtime.HallID, SeatID: 1,
+		StartAt: ptr(showtime.StartAt.Add(-time.Hour)), EndAt: &before}
+	require.NoError(t, maintenance.BlockSeat(SystemActor, early))
+	covering := &model.SeatBlock{HallID: showtime.HallID, SeatID: 2,
+		StartAt: &before, EndAt: ptr(showtime.StartAt.Add(time.Minute))}
+	require.NoError(t, maintenance.BlockSeat(SystemActor, covering))
+
+	_, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 2, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrSeatBlocked)
+	_, err = reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	assert.NoError(t, err)
+
+	require.NoError(t, maintenance.UnblockSeat(SystemActor, covering.ID))
+	bob := env.createUser(t, "bob", model.RoleUser)
+	_, err = reservations.Reserve(ActorOf(bob), bob.ID, showtime.ID, 2, CaptchaSolution{}, "")
+	assert.NoError(t, err)
+}
+
+func TestBlockSeatRejectsInvalidBlocks(t *testing.T) {
+	env := newTestEnv(t)
+	maintenance := env.maintenanceService()
+	showtime := env.createShowtime(t)
+	now := time.Now()
+
+	var validationErr *ValidationError
+	err := maintenance.BlockSeat(SystemActor, &model.SeatBlock{SeatID: 1, ShowtimeID: &showtime.ID, StartAt: &now})
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "showtime_id", validationErr.Fields[0].Field)
+	err = maintenance.BlockSeat(SystemActor, &model.SeatBlock{HallID: showtime.HallID, SeatID: 1,
+		StartAt: &now, EndAt: &now})
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{{Field: "end_at", Rule: RuleRange, Message: "must be after start_at"}},
+		validationErr.Fields)
+
+	assert.ErrorIs(t, maintenance.BlockSeat(SystemActor, &model.SeatBlock{SeatID: 7, ShowtimeID: &showtime.ID}),
+		ErrSeatNotExist)
+	assert.ErrorIs(t, maintenance.BlockSeat(Actor{}, &model.SeatBlock{SeatID: 1, ShowtimeID: &showtime.ID}),
+		ErrForbidden)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
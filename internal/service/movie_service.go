@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/qs-lzh/movie-reservation/internal/model"
 	"github.com/qs-lzh/movie-reservation/internal/repository"
@@ -9,36 +11,221 @@ import (
 )
 
 type MovieService interface {
-	CreateMovie(movie *model.Movie) error
+	CreateMovie(actor Actor, movie *model.Movie) error
+	UpdateMovie(actor Actor, movie *model.Movie) error
+	PatchMovie(actor Actor, id uint, version uint, patch model.MoviePatch) (*model.Movie, error)
 	GetMovieByID(id uint) (*model.Movie, error)
 	GetMovieByTitle(title string) (*model.Movie, error)
 	GetAllMovies() ([]model.Movie, error)
+	GetMoviesByCinemaID(cinemaID uint) ([]model.Movie, error)
 }
 
 type movieService struct {
 	db              *gorm.DB
 	repo            repository.MovieRepo
 	showtimeService ShowtimeService
+	catalog         CatalogCache
 }
 
 var _ MovieService = (*movieService)(nil)
 
-func NewMovieService(db *gorm.DB, movieRepo repository.MovieRepo, showtimeService ShowtimeService) *movieService {
+func NewMovieService(db *gorm.DB, movieRepo repository.MovieRepo, showtimeService ShowtimeService,
+	catalog CatalogCache) *movieService {
 	return &movieService{
 		db:              db,
 		repo:            movieRepo,
 		showtimeService: showtimeService,
+		catalog:         catalog,
 	}
 }
 
-func (s *movieService) CreateMovie(movie *model.Movie) error {
-	if err := s.repo.Create(movie); err != nil {
+// limits of a movie
+const (
+	maxMovieTitleLen       = 100
+	maxMovieDescriptionLen = 10000
+	maxMovieRuntimeMinutes = 600
+)
+
+func (s *movieService) CreateMovie(actor Actor, movie *model.Movie) error {
+	if err := authorize(actor, model.PermManageMovies); err != nil {
+		return err
+	}
+	if err := prepareMovie(movie); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkTitleUnusedTx(tx, 0, movie.Title); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).Create(movie)
+	})
+	if err != nil {
+		return err
+	}
+	invalidateCatalog(s.catalog, CatalogMovies)
+	return nil
+}
+
+// UpdateMovie replaces the movie if movie.Version is still the stored version,
+// the runtime can't change while showtimes of the movie haven't ended
+func (s *movieService) UpdateMovie(actor Actor, movie *model.Movie) error {
+	if err := authorize(actor, model.PermManageMovies); err != nil {
+		return err
+	}
+	if err := prepareMovie(movie); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// verify that the movie with this ID exists
+		existingMovie, err := s.repo.WithTx(tx).GetByID(movie.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// the title needs to be unique
+		if existingMovie.Title != movie.Title {
+			if err := s.checkTitleUnusedTx(tx, movie.ID, movie.Title); err != nil {
+				return err
+			}
+		}
+		if err := s.checkRuntimeChangeTx(tx, existingMovie, movie.RuntimeMinutes); err != nil {
+			return err
+		}
+
+		if err := s.repo.WithTx(tx).Update(movie); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return ErrConflict
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	invalidateCatalog(s.catalog, CatalogMovies)
+	return nil
+}
+
+// PatchMovie applies the non-nil fields of patch to the movie,
+// version must be the version the caller read, or ErrConflict is returned.
+// Like UpdateMovie it keeps the runtime while showtimes haven't ended
+func (s *movieService) PatchMovie(actor Actor, id uint, version uint, patch model.MoviePatch) (*model.Movie, error) {
+	if err := authorize(actor, model.PermManageMovies); err != nil {
+		return nil, err
+	}
+	if err := prepareMoviePatch(&patch); err != nil {
+		return nil, err
+	}
+
+	var movie *model.Movie
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		existingMovie, err := s.repo.WithTx(tx).GetByID(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if patch.Title != nil && *patch.Title != existingMovie.Title {
+			if err := s.checkTitleUnusedTx(tx, id, *patch.Title); err != nil {
+				return err
+			}
+		}
+		if patch.RuntimeMinutes != nil {
+			if err := s.checkRuntimeChangeTx(tx, existingMovie, *patch.RuntimeMinutes); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.WithTx(tx).Patch(id, version, patch); err != nil {
+			if errors.Is(err, repository.ErrVersionConflict) {
+				return ErrConflict
+			}
+			return err
+		}
+
+		movie, err = s.repo.WithTx(tx).GetByID(id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateCatalog(s.catalog, CatalogMovies)
+	return movie, nil
+}
+
+// checkRuntimeChangeTx refuses to change the runtime while showtimes of the movie
+// haven't ended, their end would move into maintenance windows of the hall
+// or into the next showtime unchecked. It returns ErrRelatedResourceExists
+// listing those showtimes
+func (s *movieService) checkRuntimeChangeTx(tx *gorm.DB, movie *model.Movie, runtimeMinutes uint) error {
+	if runtimeMinutes == movie.RuntimeMinutes {
+		return nil
+	}
+	showtimes, err := s.showtimeService.GetShowtimesByMovieIDTx(tx, movie.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var scheduled []model.Showtime
+	for _, showtime := range showtimes {
+		if showtime.StartAt.Add(movie.Runtime()).After(now) {
+			scheduled = append(scheduled, showtime)
+		}
+	}
+	if len(scheduled) != 0 {
+		return showtimesInUseError(scheduled)
+	}
+	return nil
+}
+
+// checkTitleUnusedTx returns ErrAlreadyExists if another movie has the title
+func (s *movieService) checkTitleUnusedTx(tx *gorm.DB, id uint, title string) error {
+	anotherMovie, err := s.repo.WithTx(tx).GetByTitle(title)
+	if err == nil && anotherMovie != nil && anotherMovie.ID != id {
+		return ErrAlreadyExists.WithDetail("id", anotherMovie.ID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 	return nil
 }
 
-var ErrRelatedResourceExists = errors.New("There's are related resources, so can't change")
+// prepareMovie trims the input and validates it
+func prepareMovie(movie *model.Movie) error {
+	movie.Title = strings.TrimSpace(movie.Title)
+	movie.Description = strings.TrimSpace(movie.Description)
+
+	v := &validator{}
+	v.text("title", movie.Title, maxMovieTitleLen)
+	v.maxLen("description", movie.Description, maxMovieDescriptionLen)
+	v.between("runtime_minutes", int(movie.RuntimeMinutes), 0, maxMovieRuntimeMinutes)
+	return v.err()
+}
+
+// prepareMoviePatch trims and validates the non-nil fields of patch
+func prepareMoviePatch(patch *model.MoviePatch) error {
+	v := &validator{}
+	if patch.Title != nil {
+		title := strings.TrimSpace(*patch.Title)
+		patch.Title = &title
+		v.text("title", title, maxMovieTitleLen)
+	}
+	if patch.Description != nil {
+		description := strings.TrimSpace(*patch.Description)
+		patch.Description = &description
+		v.maxLen("description", description, maxMovieDescriptionLen)
+	}
+	if patch.RuntimeMinutes != nil {
+		v.between("runtime_minutes", int(*patch.RuntimeMinutes), 0, maxMovieRuntimeMinutes)
+	}
+	return v.err()
+}
 
 func (s *movieService) GetMovieByID(id uint) (*model.Movie, error) {
 	movie, err := s.repo.GetByID(id)
@@ -69,3 +256,8 @@ func (s *movieService) GetAllMovies() ([]model.Movie, error) {
 	}
 	return movies, nil
 }
+
+// GetMoviesByCinemaID returns the movies having a showtime in the cinema
+func (s *movieService) GetMoviesByCinemaID(cinemaID uint) ([]model.Movie, error) {
+	return s.repo.GetByCinemaID(cinemaID)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestUpdateMovieRefusesAStaleVersion(t *testing.T) {
+	env := newTestEnv(t)
+	movies := env.movieService(nil)
+	movie := &model.Movie{Title: "Dune", RuntimeMinutes: 155}
+	require.NoError(t, movies.CreateMovie(SystemActor, movie))
+	require.EqualValues(t, 1, movie.Version)
+
+	// two editors read version 1, the first one to save wins
+	first, second := *movie, *movie
+	first.Description = "Arrakis"
+	require.NoError(t, movies.UpdateMovie(SystemActor, &first))
+	assert.EqualValues(t, 2, first.Version)
+	second.Description = "Caladan"
+	assert.ErrorIs(t, movies.UpdateMovie(SystemActor, &second), ErrConflict)
+
+	stored, err := movies.GetMovieByID(movie.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Arrakis", stored.Description)
+	assert.EqualValues(t, 2, stored.Version)
+}
+
+func TestPatchMovieRefusesAStaleVersion(t *testing.T) {
+	env := newTestEnv(t)
+	movies := env.movieService(nil)
+	movie := &model.Movie{Title: "Dune", RuntimeMinutes: 155}
+	require.NoError(t, movies.CreateMovie(SystemActor, movie))
+
+	runtime := uint(166)
+	patched, err := movies.PatchMovie(SystemActor, movie.ID, 1, model.MoviePatch{RuntimeMinutes: &runtime})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, patched.Version)
+	assert.EqualValues(t, 166, patched.RuntimeMinutes)
+
+	title := "Dune: Part Two"
+	_, err = movies.PatchMovie(SystemActor, movie.ID, 1, model.MoviePatch{Title: &title})
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = movies.PatchMovie(SystemActor, movie.ID+1, 1, model.MoviePatch{Title: &title})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	stored, err := movies.GetMovieByID(movie.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", stored.Title)
+	assert.EqualValues(t, 166, stored.RuntimeMinutes)
+}
+
+func TestCreateMovieTrimsAndValidatesTheInput(t *testing.T) {
+	env := newTestEnv(t)
+	movies := env.movieService(nil)
+
+	movie := &model.Movie{Title: "  Dune  ", Description: " Arrakis ", RuntimeMinutes: 155}
+	require.NoError(t, movies.CreateMovie(SystemActor, movie))
+	assert.Equal(t, "Dune", movie.Title)
+	assert.Equal(t, "Arrakis", movie.Description)
+
+	err := movies.CreateMovie(SystemActor, &model.Movie{Title: "   ", RuntimeMinutes: maxMovieRuntimeMinutes + 1})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "title", Rule: RuleRequired, Message: "must not be empty"},
+		{Field: "runtime_minutes", Rule: RuleRange, Message: "must be between 0 and 600"},
+	}, validationErr.Fields)
+
+	// titles are unique
+	err = movies.CreateMovie(SystemActor, &model.Movie{Title: "Dune", RuntimeMinutes: 155})
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	assert.ErrorIs(t, movies.CreateMovie(Actor{}, &model.Movie{Title: "Alien"}), ErrForbidden)
+}
+
+func TestRuntimeCantChangeWhileShowtimesHaveNotEnded(t *testing.T) {
+	env := newTestEnv(t)
+	movies := env.movieService(nil)
+	showtime := env.createShowtime(t)
+	movie, err := movies.GetMovieByID(showtime.MovieID)
+	require.NoError(t, err)
+
+	runtime := uint(200)
+	_, err = movies.PatchMovie(SystemActor, movie.ID, movie.Version, model.MoviePatch{RuntimeMinutes: &runtime})
+	assert.ErrorIs(t, err, ErrRelatedResourceExists)
+	var coded *Error
+	require.ErrorAs(t, err, &coded)
+	assert.Equal(t, []uint{showtime.ID}, coded.Details["showtime_ids"])
+	updated := *movie
+	updated.RuntimeMinutes = runtime
+	assert.ErrorIs(t, movies.UpdateMovie(SystemActor, &updated), ErrRelatedResourceExists)
+
+	// the other fields can change
+	updated = *movie
+	updated.Description = "Arrakis"
+	require.NoError(t, movies.UpdateMovie(SystemActor, &updated))
+
+	// past showtimes don't hold the runtime
+	require.NoError(t, env.db.Model(showtime).Update("start_at", time.Now().Add(-24*time.Hour)).Error)
+	patched, err := movies.PatchMovie(SystemActor, movie.ID, updated.Version, model.MoviePatch{RuntimeMinutes: &runtime})
+	require.NoError(t, err)
+	assert.EqualValues(t, 200, patched.RuntimeMinutes)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// RateLimit is a token bucket: it holds Burst requests and refills Requests every Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// perSecond is the refill rate of the bucket
+func (l RateLimit) perSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateDecision tells whether a request may go on and the state of its bucket after it
+type RateDecision struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is when the next request is allowed, zero while requests are left
+	RetryAfter time.Duration
+	// Reset is when the bucket is full again
+	Reset time.Duration
+}
+
+// newRateDecision reads the decision from the tokens left in the bucket
+func newRateDecision(limit RateLimit, allowed bool, tokens float64) RateDecision {
+	rate := limit.perSecond()
+	decision := RateDecision{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsOf((float64(limit.Burst) - tokens) / rate),
+	}
+	if tokens < 1 {
+		decision.RetryAfter = secondsOf((1 - tokens) / rate)
+	}
+	return decision
+}
+
+func secondsOf(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// RateLimiter takes a token from the bucket of key for each request
+type RateLimiter interface {
+	Allow(key string, limit RateLimit) (RateDecision, error)
+}
+
+type memoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// the bucket is full again at fullAt, then it is the same as no bucket
+	fullAt time.Time
+}
+
+var _ RateLimiter = (*memoryRateLimiter)(nil)
+
+// NewMemoryRateLimiter keeps the buckets in process,
+// for single node deployments, tests and as fallback of the redis limiter
+func NewMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{
+		buckets: make(map[string]memoryBucket),
+		now:     time.Now,
+	}
+}
+
+func (l *memoryRateLimiter) Allow(key string, limit RateLimit) (RateDecision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		// drop the full buckets while the lock is held anyway
+		for k, other := range l.buckets {
+			if !now.Before(other.fullAt) {
+				delete(l.buckets, k)
+			}
+		}
+		bucket = memoryBucket{tokens: float64(limit.Burst), updatedAt: now}
+	}
+	rate := limit.perSecond()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	bucket.updatedAt = now
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	bucket.fullAt = now.Add(secondsOf((float64(limit.Burst) - bucket.tokens) / rate))
+	l.buckets[key] = bucket
+	return newRateDecision(limit, allowed, bucket.tokens), nil
+}
+
+// tokenBucketScript refills the bucket of KEYS[1] by ARGV[1] tokens per second up to ARGV[2],
+// then takes a token if there is one. It reads the clock of redis, so every API instance
+// sees the same time. It returns whether a token was taken and the tokens left
+var tokenBucketScript = cache.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(bucket[1])
+local updated_at = tonumber(bucket[2])
+if tokens == nil or updated_at == nil then
+	tokens = burst
+	updated_at = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - updated_at) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", tostring(now))
+redis.call("EXPIRE", KEYS[1], math.ceil((burst - tokens) / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+type redisRateLimiter struct {
+	cache    cache.Store
+	fallback RateLimiter
+}
+
+var _ RateLimiter = (*redisRateLimiter)(nil)
+
+// NewRedisRateLimiter shares the buckets between API instances,
+// when redis fails the fallback limiter is used so requests are still limited per instance
+func NewRedisRateLimiter(cache cache.Store, fallback RateLimiter) *redisRateLimiter {
+	return &redisRateLimiter{
+		cache:    cache,
+		fallback: fallback,
+	}
+}
+
+func (l *redisRateLimiter) Allow(key string, limit RateLimit) (RateDecision, error) {
+	result, err := l.cache.Run(tokenBucketScript, []string{key},
+		strconv.FormatFloat(limit.perSecond(), 'f', -1, 64), limit.Burst)
+	if err != nil {
+		return l.fallback.Allow(key, limit)
+	}
+	allowed, tokens, err := parseTokenBucketResult(result)
+	if err != nil {
+		return l.fallback.Allow(key, limit)
+	}
+	return newRateDecision(limit, allowed, tokens), nil
+}
+
+func parseTokenBucketResult(result any) (bool, float64, error) {
+	values, ok := result.([]any)
+	if !ok || len(values) != 2 {
+		return false, 0, errors.New("unexpected result of the token bucket script")
+	}
+	allowed, ok := values[0].(int64)
+	if !ok {
+		return false, 0, errors.New("unexpected result of the token bucket script")
+	}
+	text, ok := values[1].(string)
+	if !ok {
+		return false, 0, errors.New("unexpected result of the token bucket script")
+	}
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed == 1, tokens, nil
+}
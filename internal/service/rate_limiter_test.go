@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	// 2 requests a second, up to 3 at once
+	limit := RateLimit{Requests: 2, Per: time.Second, Burst: 3}
+	start := time.Now().Truncate(time.Second)
+
+	memory := NewMemoryRateLimiter()
+	server := miniredis.RunT(t)
+	redis := cache.NewRedisCache(server.Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+	limiters := map[string]struct {
+		limiter RateLimiter
+		setNow  func(time.Time)
+	}{
+		"memory": {memory, func(now time.Time) { memory.now = func() time.Time { return now } }},
+		"redis":  {NewRedisRateLimiter(redis, NewMemoryRateLimiter()), server.SetTime},
+	}
+	for name, tc := range limiters {
+		t.Run(name, func(t *testing.T) {
+			allow := func(at time.Duration, key string) RateDecision {
+				t.Helper()
+				tc.setNow(start.Add(at))
+				decision, err := tc.limiter.Allow(key, limit)
+				require.NoError(t, err)
+				return decision
+			}
+
+			// a fresh bucket takes the whole burst at once
+			for remaining := 2; remaining >= 0; remaining-- {
+				decision := allow(0, "client")
+				assert.True(t, decision.Allowed)
+				assert.Equal(t, remaining, decision.Remaining)
+			}
+			assert.Equal(t, RateDecision{Allowed: false, RetryAfter: 500 * time.Millisecond,
+				Reset: 1500 * time.Millisecond}, allow(0, "client"))
+			// other keys have their own bucket
+			assert.True(t, allow(0, "other").Allowed)
+
+			// half a token isn't enough
+			assert.Equal(t, RateDecision{Allowed: false, RetryAfter: 250 * time.Millisecond,
+				Reset: 1250 * time.Millisecond}, allow(250*time.Millisecond, "client"))
+			assert.Equal(t, RateDecision{Allowed: true, RetryAfter: 500 * time.Millisecond,
+				Reset: 1500 * time.Millisecond}, allow(500*time.Millisecond, "client"))
+
+			// a long pause refills up to the burst, not beyond
+			for remaining := 2; remaining >= 0; remaining-- {
+				assert.Equal(t, remaining, allow(time.Minute, "client").Remaining)
+			}
+			assert.False(t, allow(time.Minute, "client").Allowed)
+		})
+	}
+}
+
+func TestRedisRateLimiterFallsBackWhenRedisFails(t *testing.T) {
+	server := miniredis.RunT(t)
+	redis := cache.NewRedisCache(server.Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+	limiter := NewRedisRateLimiter(redis, NewMemoryRateLimiter())
+	server.SetError("redis is down")
+
+	limit := RateLimit{Requests: 1, Per: time.Hour, Burst: 2}
+	for range 2 {
+		decision, err := limiter.Allow("client", limit)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+	decision, err := limiter.Allow("client", limit)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+}
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"net/mail"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -10,46 +14,110 @@ import (
 )
 
 type ReservationService interface {
-	Reserve(userID, showtimeID, seatID uint) error
-	CancelReservation(reservationID uint) error
+	Reserve(actor Actor, userID, showtimeID, seatID uint, captcha CaptchaSolution,
+		admissionToken string) (*model.Reservation, error)
+	CancelReservation(actor Actor, reservationID uint) error
 	GetRemainingTicketsTx(tx *gorm.DB, showtime *model.Showtime) (int, error)
-	GetReservationsByUserID(userID uint) ([]model.Reservation, error)
+	GetReservationsByUserID(actor Actor, userID uint) ([]model.Reservation, error)
 	GetReservationsByUserIDTx(tx *gorm.DB, userID uint) ([]model.Reservation, error)
-	GetReservationByID(reservationID uint) (*model.Reservation, error)
+	GetReservationByID(actor Actor, reservationID uint) (*model.Reservation, error)
+	ReserveAsGuest(actor Actor, guest model.GuestContact, showtimeID, seatID uint, captcha CaptchaSolution,
+		admissionToken string) (*model.Reservation, string, error)
+	GetGuestReservation(code string) (*model.Reservation, error)
+	CancelGuestReservation(code string) error
+	ClaimGuestReservations(actor Actor, userID uint) (int, error)
+	GetSeatAvailability(showtimeID uint) ([]SeatState, error)
+	WatchSeatAvailability(ctx context.Context, showtimeID uint, send func(changes []SeatState) error) error
+}
+
+type SeatStatus string
+
+const (
+	SeatAvailable SeatStatus = "available"
+	SeatReserved  SeatStatus = "reserved"
+	// blocked seats and all seats of a hall under maintenance
+	SeatBlocked SeatStatus = "blocked"
+)
+
+type SeatState struct {
+	SeatID uint
+	Status SeatStatus
 }
 
 type reservationService struct {
-	db           *gorm.DB
-	repo         repository.ReservationRepo
-	showtimeRepo repository.ShowtimeRepo
-	hallRepo     repository.HallRepo
+	db              *gorm.DB
+	repo            repository.ReservationRepo
+	showtimeRepo    repository.ShowtimeRepo
+	hallRepo        repository.HallRepo
+	seatBlockRepo   repository.SeatBlockRepo
+	maintenanceRepo repository.HallMaintenanceRepo
+	userRepo        repository.UserRepo
+	captcha         CaptchaService
+	lookupCodes     *LookupCodes
+	events          SeatEvents
+	// nil without events
+	feeds        *seatFeeds
+	waitingRooms WaitingRoomService
 }
 
 var _ ReservationService = (*reservationService)(nil)
 
 func NewReservationService(db *gorm.DB, reservationRepo repository.ReservationRepo,
-	showtimeRepo repository.ShowtimeRepo, hallRepo repository.HallRepo) *reservationService {
-	return &reservationService{
-		db:           db,
-		repo:         reservationRepo,
-		showtimeRepo: showtimeRepo,
-		hallRepo:     hallRepo,
+	showtimeRepo repository.ShowtimeRepo, hallRepo repository.HallRepo,
+	seatBlockRepo repository.SeatBlockRepo, maintenanceRepo repository.HallMaintenanceRepo,
+	userRepo repository.UserRepo, captchaService CaptchaService, lookupCodes *LookupCodes,
+	seatEvents SeatEvents, waitingRooms WaitingRoomService) *reservationService {
+	s := &reservationService{
+		db:              db,
+		repo:            reservationRepo,
+		showtimeRepo:    showtimeRepo,
+		hallRepo:        hallRepo,
+		seatBlockRepo:   seatBlockRepo,
+		maintenanceRepo: maintenanceRepo,
+		userRepo:        userRepo,
+		captcha:         captchaService,
+		lookupCodes:     lookupCodes,
+		events:          seatEvents,
+		waitingRooms:    waitingRooms,
+	}
+	if seatEvents != nil {
+		s.feeds = newSeatFeeds(seatEvents, s.GetSeatAvailability)
 	}
+	return s
 }
 
-func (s *reservationService) Reserve(userID, showtimeID, seatID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		// check if showtime exists
-		showtime, err := s.showtimeRepo.WithTx(tx).GetByID(showtimeID)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrShowtimeNotExist
-			}
-			return err
-		}
+// high-volume booking: a user who made this many reservations
+// within the window has to solve a captcha for the next one
+const (
+	captchaBookingThreshold = 5
+	captchaBookingWindow    = time.Hour
+)
 
-		// check if there's tickets available
-		if _, err := s.GetRemainingTicketsTx(tx, showtime); err != nil {
+// Reserve books the seat for userID, booking for another user
+// requires PermReserveForOthers. The user has to be active,
+// it fails with ErrUserNotVerified or ErrUserSuspended otherwise,
+// a user booking a lot in a short time has to solve a captcha.
+// A showtime with an open waiting room needs the admission token of the user
+func (s *reservationService) Reserve(actor Actor, userID, showtimeID, seatID uint,
+	captcha CaptchaSolution, admissionToken string) (*model.Reservation, error) {
+	if err := authorizeSelfOr(actor, userID, model.PermReserveForOthers); err != nil {
+		return nil, err
+	}
+	if err := s.checkAdmission(actor, showtimeID, userID, admissionToken); err != nil {
+		return nil, err
+	}
+	if err := s.checkBookingCaptcha(actor, userID, captcha); err != nil {
+		return nil, err
+	}
+	reservation := &model.Reservation{
+		ShowtimeID: showtimeID,
+		SeatID:     seatID,
+		UserID:     &userID,
+		APIKeyID:   actor.apiKeyID(),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// check if the user may reserve
+		if err := s.checkUserActiveTx(tx, userID); err != nil {
 			return err
 		}
 
@@ -58,37 +126,425 @@ func (s *reservationService) Reserve(userID, showtimeID, seatID uint) error {
 		if err != nil {
 			return err
 		}
-		for _, reservation := range reservations {
-			if reservation.ShowtimeID == showtimeID {
-				return ErrAlreadyReserved
+		if err := checkNotReserved(reservations, showtimeID); err != nil {
+			return err
+		}
+
+		if err := s.useAdmissionTx(tx, actor, showtimeID, userID, admissionToken); err != nil {
+			return err
+		}
+		return s.reserveTx(tx, reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishSeatEvents(s.events, showtimeID)
+	return reservation, nil
+}
+
+// ReserveAsGuest books the seat for a customer without an account,
+// it returns the reservation and the lookup code to view or cancel it later.
+// Staff selling at the box office need PermReserveForOthers,
+// anyone else has to solve a captcha when the service has a CaptchaService,
+// and needs an admission token for a showtime with an open waiting room
+func (s *reservationService) ReserveAsGuest(actor Actor, guest model.GuestContact, showtimeID, seatID uint,
+	captcha CaptchaSolution, admissionToken string) (*model.Reservation, string, error) {
+	v := &validator{}
+	validateGuestContact(v, &guest)
+	if err := v.err(); err != nil {
+		return nil, "", err
+	}
+	if err := s.checkAdmission(actor, showtimeID, 0, admissionToken); err != nil {
+		return nil, "", err
+	}
+	if s.captcha != nil && !actor.Can(model.PermReserveForOthers) {
+		if err := s.captcha.Verify(captcha); err != nil {
+			return nil, "", err
+		}
+	}
+
+	reservation := &model.Reservation{
+		ShowtimeID: showtimeID,
+		SeatID:     seatID,
+		Guest:      guest,
+		APIKeyID:   actor.apiKeyID(),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// the same rule as for users, as far as the guest can be recognized
+		if guest.Email != "" {
+			reservations, err := s.repo.WithTx(tx).GetGuestByEmail(guest.Email)
+			if err != nil {
+				return err
 			}
+			if err := checkNotReserved(reservations, showtimeID); err != nil {
+				return err
+			}
+		}
+		if err := s.useAdmissionTx(tx, actor, showtimeID, 0, admissionToken); err != nil {
+			return err
+		}
+		return s.reserveTx(tx, reservation)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	publishSeatEvents(s.events, showtimeID)
+	return reservation, s.lookupCodes.Sign(reservation.ID), nil
+}
+
+// checkAdmission lets staff booking for others skip the waiting room,
+// it is checked before the captcha, which can only be used once
+func (s *reservationService) checkAdmission(actor Actor, showtimeID, userID uint, admissionToken string) error {
+	if s.waitingRooms == nil || actor.Can(model.PermReserveForOthers) {
+		return nil
+	}
+	return s.waitingRooms.CheckAdmission(showtimeID, userID, admissionToken)
+}
+
+// useAdmissionTx uses up the admission with the booking, a failed booking
+// rolls it back so the admission can be booked with again
+func (s *reservationService) useAdmissionTx(tx *gorm.DB, actor Actor, showtimeID, userID uint, admissionToken string) error {
+	if s.waitingRooms == nil || actor.Can(model.PermReserveForOthers) {
+		return nil
+	}
+	return s.waitingRooms.UseAdmissionTx(tx, showtimeID, userID, admissionToken)
+}
+
+// reserveTx checks the seat can be booked for the showtime and creates the reservation
+func (s *reservationService) reserveTx(tx *gorm.DB, reservation *model.Reservation) error {
+	// check if showtime exists
+	showtime, err := s.showtimeRepo.WithTx(tx).GetByID(reservation.ShowtimeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShowtimeNotExist
+		}
+		return err
+	}
+
+	// check if the seat is a bookable seat of the hall
+	hall, err := s.hallRepo.WithTx(tx).GetByID(showtime.HallID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	seatLayout := hall.SeatLayout()
+	if _, ok := seatLayout.Seat(reservation.SeatID); !ok {
+		return ErrSeatNotExist.WithDetail("seat_id", reservation.SeatID)
+	}
+
+	// check if the hall is under maintenance or the seat is blocked
+	blockedSeats, err := s.blockedSeatsTx(tx, showtime)
+	if err != nil {
+		return err
+	}
+	if blockedSeats[reservation.SeatID] {
+		return ErrSeatBlocked.WithDetail("seat_id", reservation.SeatID)
+	}
+
+	// check if there's tickets available
+	if _, err := s.GetRemainingTicketsTx(tx, showtime); err != nil {
+		return err
+	}
+
+	// reserve, the unique index refuses a seat reserved meanwhile
+	if err := s.repo.WithTx(tx).Create(reservation); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrSeatTaken.WithDetail("seat_id", reservation.SeatID)
 		}
+		return err
+	}
+	return nil
+}
 
-		// reserve
-		return s.repo.WithTx(tx).Create(&model.Reservation{
-			ShowtimeID: showtimeID,
-			SeatID:     seatID,
-			UserID:     userID,
-		})
+func checkNotReserved(reservations []model.Reservation, showtimeID uint) error {
+	for _, reservation := range reservations {
+		if reservation.ShowtimeID == showtimeID {
+			return ErrAlreadyReserved.WithDetail("reservation_id", reservation.ID)
+		}
+	}
+	return nil
+}
+
+// GetGuestReservation returns the reservation of the lookup code,
+// codes that are not valid give ErrNotFound, and so do the codes of
+// reservations claimed into an account since
+func (s *reservationService) GetGuestReservation(code string) (*model.Reservation, error) {
+	reservationID, ok := s.lookupCodes.Verify(code)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.getGuestReservationTx(s.db, reservationID)
+}
+
+func (s *reservationService) getGuestReservationTx(tx *gorm.DB, reservationID uint) (*model.Reservation, error) {
+	reservation, err := s.repo.WithTx(tx).GetByID(reservationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !reservation.IsGuest() {
+		// claimed, only the account can see it now
+		return nil, ErrNotFound
+	}
+	return reservation, nil
+}
+
+// CancelGuestReservation cancels the reservation of the lookup code,
+// holding the code is the proof of ownership
+func (s *reservationService) CancelGuestReservation(code string) error {
+	reservationID, ok := s.lookupCodes.Verify(code)
+	if !ok {
+		return ErrNotFound
+	}
+	var showtimeID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		reservation, err := s.getGuestReservationTx(tx, reservationID)
+		if err != nil {
+			return err
+		}
+		showtimeID = reservation.ShowtimeID
+		return s.repo.WithTx(tx).DeleteByID(reservationID)
+	})
+	if err != nil {
+		return err
+	}
+	publishSeatEvents(s.events, showtimeID)
+	return nil
+}
+
+// ClaimGuestReservations moves the guest reservations made with the user's email
+// into the account and returns how many were claimed. The email has to be verified,
+// otherwise anyone could register with someone else's address and take their bookings,
+// guest reservations with only a phone number can't be claimed
+func (s *reservationService) ClaimGuestReservations(actor Actor, userID uint) (int, error) {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return 0, err
+	}
+	var claimed int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.userRepo.WithTx(tx).GetByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if user.Email == "" || user.EmailVerifiedAt == nil {
+			return ErrUserNotVerified
+		}
+		claimed, err = s.repo.WithTx(tx).ClaimGuestByEmail(user.Email, userID)
+		return err
 	})
+	return claimed, err
+}
+
+// validateGuestContact trims the contact and validates it with the limits of a user profile
+func validateGuestContact(v *validator, guest *model.GuestContact) {
+	guest.Name = strings.TrimSpace(guest.Name)
+	guest.Email = strings.ToLower(strings.TrimSpace(guest.Email))
+	guest.Phone = strings.TrimSpace(guest.Phone)
+
+	if guest.Email == "" && guest.Phone == "" {
+		v.add("guest", RuleRequired, "needs an email or a phone number")
+		return
+	}
+	if guest.Email != "" {
+		v.maxLen("guest.email", guest.Email, maxUserEmailLen)
+		if address, err := mail.ParseAddress(guest.Email); err != nil || address.Address != guest.Email {
+			v.add("guest.email", RuleInvalid, "must be a valid email address")
+		}
+	}
+	v.maxLen("guest.name", guest.Name, maxUserDisplayNameLen)
+	v.maxLen("guest.phone", guest.Phone, maxUserPhoneLen)
+}
+
+func (s *reservationService) checkUserActiveTx(tx *gorm.DB, userID uint) error {
+	user, err := s.userRepo.WithTx(tx).GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	switch user.Status {
+	case model.UserActive:
+		return nil
+	case model.UserSuspended:
+		return ErrUserSuspended
+	default:
+		return ErrUserNotVerified
+	}
+}
+
+// checkBookingCaptcha verifies the captcha once the user booked captchaBookingThreshold
+// times within captchaBookingWindow, staff booking for others are trusted
+func (s *reservationService) checkBookingCaptcha(actor Actor, userID uint, captcha CaptchaSolution) error {
+	if s.captcha == nil || actor.Can(model.PermReserveForOthers) {
+		return nil
+	}
+	count, err := s.repo.CountByUserIDSince(userID, time.Now().Add(-captchaBookingWindow))
+	if err != nil {
+		return err
+	}
+	if count < captchaBookingThreshold {
+		return nil
+	}
+	return s.captcha.Verify(captcha)
 }
 
-func (s *reservationService) CancelReservation(reservationID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		_, err := s.repo.WithTx(tx).GetByID(reservationID)
+// CancelReservation cancels one of the actor's reservations,
+// cancelling the reservation of another user is a refund and requires PermRefund
+func (s *reservationService) CancelReservation(actor Actor, reservationID uint) error {
+	var showtimeID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		reservation, err := s.repo.WithTx(tx).GetByID(reservationID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return ErrNotFound
 			}
 			return err
 		}
+		if err := authorizeSelfOr(actor, reservation.OwnerID(), model.PermRefund); err != nil {
+			return err
+		}
+		showtimeID = reservation.ShowtimeID
 		return s.repo.WithTx(tx).DeleteByID(reservationID)
 	})
+	if err != nil {
+		return err
+	}
+	publishSeatEvents(s.events, showtimeID)
+	return nil
+}
+
+// drainSeatEvents discards the events waiting in the channel
+func drainSeatEvents(events <-chan SeatEvent) {
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// GetSeatAvailability returns the status of every bookable seat of the showtime,
+// ordered by seat ID
+func (s *reservationService) GetSeatAvailability(showtimeID uint) ([]SeatState, error) {
+	var seats []SeatState
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		showtime, err := s.showtimeRepo.WithTx(tx).GetByID(showtimeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrShowtimeNotExist
+			}
+			return err
+		}
+		hall, err := s.hallRepo.WithTx(tx).GetByID(showtime.HallID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		underMaintenance := false
+		blockedSeats, err := s.blockedSeatsTx(tx, showtime)
+		if errors.Is(err, ErrHallUnderMaintenance) {
+			underMaintenance = true
+		} else if err != nil {
+			return err
+		}
+		reservations, err := s.repo.WithTx(tx).GetByShowtimeID(showtime.ID)
+		if err != nil {
+			return err
+		}
+		reservedSeats := make(map[uint]bool, len(reservations))
+		for _, reservation := range reservations {
+			reservedSeats[reservation.SeatID] = true
+		}
+
+		seats = make([]SeatState, 0, hall.SeatCount)
+		for seatID := uint(1); seatID <= uint(hall.SeatCount); seatID++ {
+			state := SeatState{SeatID: seatID, Status: SeatAvailable}
+			switch {
+			case reservedSeats[seatID]:
+				state.Status = SeatReserved
+			case underMaintenance || blockedSeats[seatID]:
+				state.Status = SeatBlocked
+			}
+			seats = append(seats, state)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seats, nil
 }
 
+// WatchSeatAvailability sends every seat of the showtime, then the seats whose status changed,
+// until ctx is done or send fails
+func (s *reservationService) WatchSeatAvailability(ctx context.Context, showtimeID uint,
+	send func(changes []SeatState) error) error {
+	var last map[uint]SeatStatus
+	sendChanges := func(seats []SeatState) error {
+		var changes []SeatState
+		current := make(map[uint]SeatStatus, len(seats))
+		for _, seat := range seats {
+			current[seat.SeatID] = seat.Status
+			if status, ok := last[seat.SeatID]; !ok || status != seat.Status {
+				changes = append(changes, seat)
+			}
+		}
+		first := last == nil
+		last = current
+		if !first && len(changes) == 0 {
+			return nil
+		}
+		return send(changes)
+	}
+
+	if s.feeds == nil {
+		seats, err := s.GetSeatAvailability(showtimeID)
+		if err != nil {
+			return err
+		}
+		if err := sendChanges(seats); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return nil
+	}
+	snapshots, stop := s.feeds.watch(showtimeID)
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case snapshot := <-snapshots:
+			if snapshot.err != nil {
+				return snapshot.err
+			}
+			if err := sendChanges(snapshot.seats); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetRemainingTicketsTx counts the seats neither reserved nor blocked,
+// it returns ErrHallUnderMaintenance if the whole hall is unavailable
 func (s *reservationService) GetRemainingTicketsTx(tx *gorm.DB, showtime *model.Showtime) (int, error) {
 	var remainingTickets int
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err := tx.Transaction(func(tx *gorm.DB) error {
 		reservations, err := s.repo.WithTx(tx).GetByShowtimeID(showtime.ID)
 		if err != nil {
 			return err
@@ -100,7 +556,14 @@ func (s *reservationService) GetRemainingTicketsTx(tx *gorm.DB, showtime *model.
 			}
 			return err
 		}
-		remainingTickets = hall.SeatCount - len(reservations)
+		unavailableSeats, err := s.blockedSeatsTx(tx, showtime)
+		if err != nil {
+			return err
+		}
+		for _, reservation := range reservations {
+			unavailableSeats[reservation.SeatID] = true
+		}
+		remainingTickets = hall.SeatCount - len(unavailableSeats)
 		if remainingTickets <= 0 {
 			return ErrNoTicketsAvailable
 		}
@@ -110,14 +573,39 @@ func (s *reservationService) GetRemainingTicketsTx(tx *gorm.DB, showtime *model.
 	return remainingTickets, err
 }
 
-func (s *reservationService) GetReservationsByUserID(userID uint) ([]model.Reservation, error) {
+// blockedSeatsTx returns the set of seats blocked for the showtime,
+// or ErrHallUnderMaintenance if a maintenance window of the hall overlaps it
+func (s *reservationService) blockedSeatsTx(tx *gorm.DB, showtime *model.Showtime) (map[uint]bool, error) {
+	maintenances, err := s.maintenanceRepo.WithTx(tx).GetOverlapping(showtime.HallID, showtime.StartAt, showtime.EndAt())
+	if err != nil {
+		return nil, err
+	}
+	if len(maintenances) != 0 {
+		return nil, ErrHallUnderMaintenance
+	}
+
+	blocks, err := s.seatBlockRepo.WithTx(tx).GetActive(showtime.HallID, showtime.ID, showtime.StartAt)
+	if err != nil {
+		return nil, err
+	}
+	blockedSeats := make(map[uint]bool, len(blocks))
+	for _, block := range blocks {
+		blockedSeats[block.SeatID] = true
+	}
+	return blockedSeats, nil
+}
+
+func (s *reservationService) GetReservationsByUserID(actor Actor, userID uint) ([]model.Reservation, error) {
+	if err := authorizeSelfOr(actor, userID, model.PermViewReservations); err != nil {
+		return nil, err
+	}
 	return s.GetReservationsByUserIDTx(s.db, userID)
 }
 
 func (s *reservationService) GetReservationsByUserIDTx(tx *gorm.DB, userID uint) ([]model.Reservation, error) {
 	return s.repo.WithTx(tx).GetByUserID(userID)
 }
-func (s *reservationService) GetReservationByID(reservationID uint) (*model.Reservation, error) {
+func (s *reservationService) GetReservationByID(actor Actor, reservationID uint) (*model.Reservation, error) {
 	reservation, err := s.repo.GetByID(reservationID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -125,5 +613,8 @@ func (s *reservationService) GetReservationByID(reservationID uint) (*model.Rese
 		}
 		return nil, err
 	}
+	if err := authorizeSelfOr(actor, reservation.OwnerID(), model.PermViewReservations); err != nil {
+		return nil, err
+	}
 	return reservation, nil
 }
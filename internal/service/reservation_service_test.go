@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestReserveRefusesATakenSeat(t *testing.T) {
+	env := newTestEnv(t)
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+
+	_, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	require.NoError(t, err)
+
+	_, err = reservations.Reserve(ActorOf(bob), bob.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrSeatTaken)
+	_, _, err = reservations.ReserveAsGuest(Actor{}, model.GuestContact{Email: "carol@example.com"},
+		showtime.ID, 1, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrSeatTaken)
+
+	// one reservation a showtime per user
+	_, err = reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 2, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrAlreadyReserved)
+	_, err = reservations.Reserve(ActorOf(bob), bob.ID, showtime.ID, 2, CaptchaSolution{}, "")
+	assert.NoError(t, err)
+}
+
+func TestReserveRefusesBlockedSeatsAndMissingSeats(t *testing.T) {
+	env := newTestEnv(t)
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	require.NoError(t, env.db.Create(&model.SeatBlock{
+		HallID: showtime.HallID, SeatID: 3, ShowtimeID: &showtime.ID, Reason: "broken",
+	}).Error)
+
+	_, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 3, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrSeatBlocked)
+	_, err = reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 7, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrSeatNotExist)
+}
+
+func TestReserveForAnotherUserNeedsThePermission(t *testing.T) {
+	env := newTestEnv(t)
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+	boxOffice := env.createUser(t, "clerk", model.RoleBoxOffice)
+
+	_, err := reservations.Reserve(ActorOf(bob), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+	reservation, err := reservations.Reserve(ActorOf(boxOffice), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, reservation.OwnerID())
+}
+
+func TestClaimGuestReservationsNeedsAVerifiedEmail(t *testing.T) {
+	env := newTestEnv(t)
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	guest := model.GuestContact{Name: "Alice", Email: "Alice@Example.com"}
+	_, code, err := reservations.ReserveAsGuest(Actor{}, guest, showtime.ID, 1, CaptchaSolution{}, "")
+	require.NoError(t, err)
+	found, err := reservations.GetGuestReservation(code)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", found.Guest.Email)
+
+	alice := env.createUser(t, "alice", model.RoleUser)
+	require.NoError(t, env.db.Model(alice).Update("email_verified_at", nil).Error)
+	_, err = reservations.ClaimGuestReservations(ActorOf(alice), alice.ID)
+	assert.ErrorIs(t, err, ErrUserNotVerified)
+
+	bob := env.createUser(t, "bob", model.RoleUser)
+	_, err = reservations.ClaimGuestReservations(ActorOf(bob), alice.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	require.NoError(t, env.db.Model(alice).Update("email_verified_at", bob.EmailVerifiedAt).Error)
+	claimed, err := reservations.ClaimGuestReservations(ActorOf(alice), alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	claimed, err = reservations.ClaimGuestReservations(ActorOf(alice), alice.ID)
+	require.NoError(t, err)
+	assert.Zero(t, claimed)
+
+	// the claimed reservation belongs to the account, the code doesn't find it anymore
+	owned, err := reservations.GetReservationsByUserID(ActorOf(alice), alice.ID)
+	require.NoError(t, err)
+	require.Len(t, owned, 1)
+	assert.Equal(t, showtime.ID, owned[0].ShowtimeID)
+	_, err = reservations.GetGuestReservation(code)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// SeatEvent tells that the availability of seats of the showtime changed,
+// subscribers read the new availability from ReservationService
+type SeatEvent struct {
+	ShowtimeID uint `json:"showtime_id"`
+}
+
+// SeatEvents carries SeatEvents from the services changing availability
+// to the clients watching it
+type SeatEvents interface {
+	// Publish is called after the change is committed, it doesn't fail the change
+	Publish(event SeatEvent)
+	// Subscribe returns the events of the showtime until ctx is done,
+	// the channel is also closed when the subscriber falls behind
+	// and has to read the availability again
+	Subscribe(ctx context.Context, showtimeID uint) (<-chan SeatEvent, error)
+}
+
+// seatEventBuffer is how many events a subscriber can fall behind
+const seatEventBuffer = 16
+
+// memorySeatEvents only reaches the subscribers of this process
+type memorySeatEvents struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan SeatEvent]struct{}
+}
+
+var _ SeatEvents = (*memorySeatEvents)(nil)
+
+func NewMemorySeatEvents() *memorySeatEvents {
+	return &memorySeatEvents{
+		subscribers: make(map[uint]map[chan SeatEvent]struct{}),
+	}
+}
+
+func (e *memorySeatEvents) Publish(event SeatEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subscribers[event.ShowtimeID] {
+		select {
+		case ch <- event:
+		default:
+			// too slow, it reads the availability again after resubscribing
+			e.removeLocked(event.ShowtimeID, ch)
+		}
+	}
+}
+
+func (e *memorySeatEvents) Subscribe(ctx context.Context, showtimeID uint) (<-chan SeatEvent, error) {
+	ch := make(chan SeatEvent, seatEventBuffer)
+	e.mu.Lock()
+	if e.subscribers[showtimeID] == nil {
+		e.subscribers[showtimeID] = make(map[chan SeatEvent]struct{})
+	}
+	e.subscribers[showtimeID][ch] = struct{}{}
+	e.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.removeLocked(showtimeID, ch)
+	}()
+	return ch, nil
+}
+
+// removeLocked closes the channel of a subscriber that is still subscribed
+func (e *memorySeatEvents) removeLocked(showtimeID uint, ch chan SeatEvent) {
+	subscribers := e.subscribers[showtimeID]
+	if _, ok := subscribers[ch]; !ok {
+		return
+	}
+	delete(subscribers, ch)
+	close(ch)
+	if len(subscribers) == 0 {
+		delete(e.subscribers, showtimeID)
+	}
+}
+
+type redisSeatEvents struct {
+	cache cache.PubSub
+	local *memorySeatEvents
+}
+
+var _ SeatEvents = (*redisSeatEvents)(nil)
+
+// NewRedisSeatEvents reaches the subscribers of every API instance through redis pub/sub.
+// When redis fails, the events still reach the subscribers of this instance
+func NewRedisSeatEvents(cache cache.PubSub) *redisSeatEvents {
+	return &redisSeatEvents{
+		cache: cache,
+		local: NewMemorySeatEvents(),
+	}
+}
+
+func seatEventChannel(showtimeID uint) string {
+	return fmt.Sprintf("seat-events:%d", showtimeID)
+}
+
+func (e *redisSeatEvents) Publish(event SeatEvent) {
+	if err := e.cache.Publish(seatEventChannel(event.ShowtimeID), event); err != nil {
+		e.local.Publish(event)
+	}
+}
+
+// Subscribe merges the events of redis and those published locally while redis failed,
+// it falls back to the local events when redis can't be subscribed to
+func (e *redisSeatEvents) Subscribe(ctx context.Context, showtimeID uint) (<-chan SeatEvent, error) {
+	// both subscriptions end with the merged one, whichever source ends it
+	mergedCtx, cancel := context.WithCancel(ctx)
+	local, err := e.local.Subscribe(mergedCtx, showtimeID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	messages, err := e.cache.Subscribe(mergedCtx, seatEventChannel(showtimeID))
+	if err != nil {
+		cancel()
+		return e.local.Subscribe(ctx, showtimeID)
+	}
+
+	events := make(chan SeatEvent, seatEventBuffer)
+	go func() {
+		// closing events makes the subscriber read the availability again,
+		// so it is closed as soon as either source ends
+		defer close(events)
+		defer cancel()
+		for {
+			var event SeatEvent
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				if err := json.Unmarshal(message, &event); err != nil {
+					continue
+				}
+			case localEvent, ok := <-local:
+				if !ok {
+					return
+				}
+				event = localEvent
+			}
+			select {
+			case events <- event:
+			default:
+				// too slow, it reads the availability again after resubscribing
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// publishSeatEvents tells the subscribers of each showtime, events may be nil
+func publishSeatEvents(events SeatEvents, showtimeIDs ...uint) {
+	if events == nil {
+		return
+	}
+	for _, showtimeID := range showtimeIDs {
+		events.Publish(SeatEvent{ShowtimeID: showtimeID})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func newRedisSeatEvents(t *testing.T) (*redisSeatEvents, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	redis := cache.NewRedisCache(server.Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+	return NewRedisSeatEvents(redis), server
+}
+
+func (e *memorySeatEvents) subscriberCount(showtimeID uint) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.subscribers[showtimeID])
+}
+
+func TestRedisSeatEventsEndTheLocalSubscriptionWithTheMergedOne(t *testing.T) {
+	events, server := newRedisSeatEvents(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscription, err := events.Subscribe(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, events.local.subscriberCount(1))
+
+	// a subscriber that doesn't read falls behind, which ends both sources
+	for range seatEventBuffer + 1 {
+		events.Publish(SeatEvent{ShowtimeID: 1})
+	}
+	channel := seatEventChannel(1)
+	require.Eventually(t, func() bool {
+		return events.local.subscriberCount(1) == 0 && server.PubSubNumSub(channel)[channel] == 0
+	}, time.Second, 5*time.Millisecond)
+	received := 0
+	for range subscription {
+		received++
+	}
+	assert.Equal(t, seatEventBuffer, received)
+}
+
+func TestRedisSeatEventsFallBackToLocalEvents(t *testing.T) {
+	events, server := newRedisSeatEvents(t)
+	server.SetError("redis is down")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	subscription, err := events.Subscribe(ctx, 1)
+	require.NoError(t, err)
+	events.Publish(SeatEvent{ShowtimeID: 1})
+	assert.Equal(t, SeatEvent{ShowtimeID: 1}, <-subscription)
+
+	cancel()
+	_, ok := <-subscription
+	assert.False(t, ok)
+	assert.Zero(t, events.local.subscriberCount(1))
+}
+
+func TestSeatWatchersShareOneSubscriptionAndRead(t *testing.T) {
+	env := newTestEnv(t)
+	events, server := newRedisSeatEvents(t)
+	reservations := NewReservationService(env.db, env.reservations, env.showtimes, env.halls, env.seatBlocks,
+		env.maintenances, env.users, nil, NewLookupCodes("lookup-secret"), events, nil)
+	var reads atomic.Int32
+	reservations.feeds.read = func(showtimeID uint) ([]SeatState, error) {
+		reads.Add(1)
+		return reservations.GetSeatAvailability(showtimeID)
+	}
+	alice := env.createUser(t, "alice", model.RoleUser)
+	showtime := env.createShowtime(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch := func() <-chan []SeatState {
+		changes := make(chan []SeatState, 8)
+		go func() {
+			_ = reservations.WatchSeatAvailability(ctx, showtime.ID, func(seats []SeatState) error {
+				changes <- seats
+				return nil
+			})
+		}()
+		return changes
+	}
+	first, second := watch(), watch()
+	assert.Len(t, <-first, 6)
+	assert.Len(t, <-second, 6)
+	channel := seatEventChannel(showtime.ID)
+	assert.Equal(t, 1, server.PubSubNumSub(channel)[channel])
+	assert.Equal(t, int32(1), reads.Load())
+
+	_, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 4, CaptchaSolution{}, "")
+	require.NoError(t, err)
+	want := []SeatState{{SeatID: 4, Status: SeatReserved}}
+	assert.Equal(t, want, <-first)
+	assert.Equal(t, want, <-second)
+	assert.Equal(t, int32(2), reads.Load())
+
+	// the feed ends with its last watcher
+	cancel()
+	require.Eventually(t, func() bool {
+		reservations.feeds.mu.Lock()
+		defer reservations.feeds.mu.Unlock()
+		return len(reservations.feeds.feeds) == 0 && server.PubSubNumSub(channel)[channel] == 0 &&
+			events.local.subscriberCount(showtime.ID) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSeatWatcherOfAMissingShowtimeFails(t *testing.T) {
+	env := newTestEnv(t)
+	reservations := NewReservationService(env.db, env.reservations, env.showtimes, env.halls, env.seatBlocks,
+		env.maintenances, env.users, nil, NewLookupCodes("lookup-secret"), NewMemorySeatEvents(), nil)
+
+	err := reservations.WatchSeatAvailability(context.Background(), 42, func([]SeatState) error {
+		t.Fatal("nothing to send")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrShowtimeNotExist)
+	assert.Empty(t, reservations.feeds.feeds)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// seatSnapshot is the availability of every seat of a showtime,
+// or the error that ended its feed
+type seatSnapshot struct {
+	seats []SeatState
+	err   error
+}
+
+// seatFeeds reads the availability of a watched showtime once per seat event
+// and hands it to every watcher of the showtime in this process, so a showtime
+// has one event subscription per instance however many clients watch it
+type seatFeeds struct {
+	events SeatEvents
+	read   func(showtimeID uint) ([]SeatState, error)
+
+	mu    sync.Mutex
+	feeds map[uint]*seatFeed
+}
+
+type seatFeed struct {
+	cancel   context.CancelFunc
+	watchers map[chan seatSnapshot]struct{}
+	// latest is handed to the watchers joining the running feed
+	latest *seatSnapshot
+}
+
+func newSeatFeeds(events SeatEvents, read func(showtimeID uint) ([]SeatState, error)) *seatFeeds {
+	return &seatFeeds{
+		events: events,
+		read:   read,
+		feeds:  make(map[uint]*seatFeed),
+	}
+}
+
+// watch returns the snapshots of the showtime, starting with the current one, until stop is called.
+// A watcher that falls behind only gets the latest snapshot,
+// the channel is never closed, a snapshot with an error is the last one
+func (f *seatFeeds) watch(showtimeID uint) (snapshots <-chan seatSnapshot, stop func()) {
+	ch := make(chan seatSnapshot, 1)
+	f.mu.Lock()
+	feed, ok := f.feeds[showtimeID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		feed = &seatFeed{cancel: cancel, watchers: make(map[chan seatSnapshot]struct{})}
+		f.feeds[showtimeID] = feed
+		go f.run(ctx, showtimeID, feed)
+	}
+	feed.watchers[ch] = struct{}{}
+	if feed.latest != nil {
+		ch <- *feed.latest
+	}
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(feed.watchers, ch)
+		if len(feed.watchers) == 0 && f.feeds[showtimeID] == feed {
+			feed.cancel()
+			delete(f.feeds, showtimeID)
+		}
+	}
+}
+
+// run reads the availability after subscribing and after each event, until ctx is done
+func (f *seatFeeds) run(ctx context.Context, showtimeID uint, feed *seatFeed) {
+	for {
+		events, err := f.events.Subscribe(ctx, showtimeID)
+		if err != nil {
+			f.end(showtimeID, feed, err)
+			return
+		}
+		// read after subscribing, so no change falls in between
+		if !f.update(showtimeID, feed) {
+			return
+		}
+		for range events {
+			// the events that came in the meantime are covered by the same read
+			drainSeatEvents(events)
+			if !f.update(showtimeID, feed) {
+				return
+			}
+		}
+		// closed because the last watcher left, or because the feed fell behind
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// update reads the availability and hands it to the watchers, it returns false once the feed ended
+func (f *seatFeeds) update(showtimeID uint, feed *seatFeed) bool {
+	seats, err := f.read(showtimeID)
+	if err != nil {
+		f.end(showtimeID, feed, err)
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broadcastLocked(feed, seatSnapshot{seats: seats})
+	return true
+}
+
+// end hands the error to the watchers, later watchers of the showtime start a new feed
+func (f *seatFeeds) end(showtimeID uint, feed *seatFeed, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	feed.cancel()
+	if f.feeds[showtimeID] == feed {
+		delete(f.feeds, showtimeID)
+	}
+	f.broadcastLocked(feed, seatSnapshot{err: err})
+}
+
+func (f *seatFeeds) broadcastLocked(feed *seatFeed, snapshot seatSnapshot) {
+	feed.latest = &snapshot
+	for ch := range feed.watchers {
+		// only the latest snapshot matters, the watcher compares it with the last one it sent.
+		// The feed is the only sender, so there is room once the stale one is dropped
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snapshot
+	}
+}
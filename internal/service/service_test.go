@@ -0,0 +1,155 @@
+package service
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+const testPassword = "Correct-Horse-9"
+
+// memorySessions counts the session generations of the users,
+// a bumped generation means every token of the user was revoked
+type memorySessions struct {
+	mu          sync.Mutex
+	generations map[uint]int64
+}
+
+var _ SessionRevoker = (*memorySessions)(nil)
+
+func (s *memorySessions) BumpGeneration(userID uint) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generations[userID]++
+	return s.generations[userID], nil
+}
+
+func (s *memorySessions) generation(userID uint) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[userID]
+}
+
+// testEnv is a migrated sqlite database with the repositories,
+// and an in-memory redis
+type testEnv struct {
+	db       *gorm.DB
+	redis    *cache.RedisCache
+	sessions *memorySessions
+
+	users        repository.UserRepo
+	cinemas      repository.CinemaRepo
+	halls        repository.HallRepo
+	movies       repository.MovieRepo
+	showtimes    repository.ShowtimeRepo
+	reservations repository.ReservationRepo
+	seatBlocks   repository.SeatBlockRepo
+	maintenances repository.HallMaintenanceRepo
+	waitingRooms repository.WaitingRoomRepo
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	redis := cache.NewRedisCache(miniredis.RunT(t).Addr())
+	t.Cleanup(func() { _ = redis.Close() })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&model.User{},
+		&model.TwoFactor{},
+		&model.RecoveryCode{},
+		&model.Cinema{},
+		&model.Hall{},
+		&model.Movie{},
+		&model.Showtime{},
+		&model.Reservation{},
+		&model.SeatBlock{},
+		&model.HallMaintenance{},
+		&model.WaitingRoom{},
+		&model.AdmissionUse{},
+		&model.UserToken{},
+		&model.APIKey{},
+	))
+
+	return &testEnv{
+		db:           db,
+		redis:        redis,
+		sessions:     &memorySessions{generations: make(map[uint]int64)},
+		users:        repository.NewUserRepoGorm(db),
+		cinemas:      repository.NewCinemaRepoGorm(db),
+		halls:        repository.NewHallRepoGorm(db),
+		movies:       repository.NewMovieRepoGorm(db),
+		showtimes:    repository.NewShowtimeRepoGorm(db),
+		reservations: repository.NewReservationRepoGorm(db),
+		seatBlocks:   repository.NewSeatBlockRepoGorm(db),
+		maintenances: repository.NewHallMaintenanceRepoGorm(db),
+		waitingRooms: repository.NewWaitingRoomRepoGorm(db),
+	}
+}
+
+func (e *testEnv) userService() *userService {
+	return NewUserService(e.db, e.users, e.reservations, NewLoginThrottle(NewMemoryAttemptStore()), nil, e.sessions, nil)
+}
+
+// reservationService books without captchas and waiting rooms unless one is given
+func (e *testEnv) reservationService(waitingRooms WaitingRoomService) *reservationService {
+	return NewReservationService(e.db, e.reservations, e.showtimes, e.halls, e.seatBlocks, e.maintenances,
+		e.users, nil, NewLookupCodes("lookup-secret"), nil, waitingRooms)
+}
+
+// createUser registers an active user with testPassword and a verified email
+func (e *testEnv) createUser(t *testing.T, name string, role model.UserRole) *model.User {
+	t.Helper()
+	user, err := e.userService().Register(name, testPassword, model.UserProfile{Email: name + "@example.com"},
+		CaptchaSolution{})
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, e.db.Model(user).Updates(map[string]any{
+		"role": role, "status": model.UserActive, "email_verified_at": now,
+	}).Error)
+	user.Role, user.Status, user.EmailVerifiedAt = role, model.UserActive, &now
+	return user
+}
+
+// createShowtime creates a showtime tomorrow in a hall of 2 x 3 seats
+func (e *testEnv) createShowtime(t *testing.T) *model.Showtime {
+	t.Helper()
+	cinema := &model.Cinema{Name: "Odeon", Timezone: "Europe/Berlin"}
+	require.NoError(t, e.db.Create(cinema).Error)
+	hall := &model.Hall{CinemaID: cinema.ID, Name: "Hall 1", Layout: model.RectangularLayout(2, 3)}
+	hall.ApplyLayout()
+	require.NoError(t, e.db.Create(hall).Error)
+	movie := &model.Movie{Title: "Dune", RuntimeMinutes: 155}
+	require.NoError(t, e.db.Create(movie).Error)
+	showtime := &model.Showtime{MovieID: movie.ID, HallID: hall.ID, StartAt: time.Now().Add(24 * time.Hour)}
+	require.NoError(t, e.db.Create(showtime).Error)
+	return showtime
+}
+
+func (e *testEnv) showtimeService() *showtimeService {
+	return NewShowtimeService(e.db, e.showtimes, e.movies, e.halls, e.maintenances, nil)
+}
+
+// movieService invalidates catalog, which may be nil
+func (e *testEnv) movieService(catalog CatalogCache) *movieService {
+	return NewMovieService(e.db, e.movies, e.showtimeService(), catalog)
+}
+
+func (e *testEnv) hallService() *hallService {
+	return NewHallService(e.db, e.halls, e.cinemas, e.showtimeService(), nil)
+}
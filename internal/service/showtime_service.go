@@ -11,38 +11,109 @@ import (
 )
 
 type ShowtimeService interface {
-	CreateShowtime(movieID uint, startTime time.Time, hallID uint) error
+	CreateShowtime(actor Actor, movieID uint, startTime time.Time, hallID uint) (*model.Showtime, error)
 	GetShowtimeByID(showtimeID uint) (*model.Showtime, error)
 	GetShowtimesByMovieID(movieID uint) ([]model.Showtime, error)
 	GetShowtimesByMovieIDTx(tx *gorm.DB, movieID uint) ([]model.Showtime, error)
 	GetShowtimesByHallID(hallID uint) ([]model.Showtime, error)
 	GetShowtimesByHallIDTx(tx *gorm.DB, hallID uint) ([]model.Showtime, error)
+	GetShowtimesByCinemaID(cinemaID uint) ([]model.Showtime, error)
 	GetAllShowtimes() ([]model.Showtime, error)
 }
 
 type showtimeService struct {
-	db   *gorm.DB
-	repo repository.ShowtimeRepo
+	db              *gorm.DB
+	repo            repository.ShowtimeRepo
+	movieRepo       repository.MovieRepo
+	hallRepo        repository.HallRepo
+	maintenanceRepo repository.HallMaintenanceRepo
+	catalog         CatalogCache
 }
 
 var _ ShowtimeService = (*showtimeService)(nil)
 
-func NewShowtimeService(db *gorm.DB, showtimeRepo repository.ShowtimeRepo) *showtimeService {
+func NewShowtimeService(db *gorm.DB, showtimeRepo repository.ShowtimeRepo, movieRepo repository.MovieRepo,
+	hallRepo repository.HallRepo, maintenanceRepo repository.HallMaintenanceRepo, catalog CatalogCache) *showtimeService {
 	return &showtimeService{
-		db:   db,
-		repo: showtimeRepo,
+		db:              db,
+		repo:            showtimeRepo,
+		movieRepo:       movieRepo,
+		hallRepo:        hallRepo,
+		maintenanceRepo: maintenanceRepo,
+		catalog:         catalog,
 	}
 }
 
-func (s *showtimeService) CreateShowtime(movieID uint, startTime time.Time, hallID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		showtime := &model.Showtime{
-			MovieID: uint(movieID),
-			StartAt: startTime,
-			HallID:  uint(hallID),
+// CreateShowtime reports invalid input as *ValidationError, including a movie
+// or hall that doesn't exist, the start time has to be in the future
+// and the showtime, as long as the runtime of the movie, must not overlap
+// the maintenance windows of the hall
+func (s *showtimeService) CreateShowtime(actor Actor, movieID uint, startTime time.Time, hallID uint) (*model.Showtime, error) {
+	if err := authorize(actor, model.PermManageShowtimes); err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.requiredID("movie_id", movieID)
+	v.requiredID("hall_id", hallID)
+	if startTime.IsZero() {
+		v.add("start_at", RuleRequired, "is required")
+	} else if !startTime.After(time.Now()) {
+		v.add("start_at", RuleFuture, "must be in the future")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	showtime := &model.Showtime{
+		MovieID: movieID,
+		StartAt: startTime,
+		HallID:  hallID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		movie, err := s.checkReferencesTx(tx, movieID, hallID)
+		if err != nil {
+			return err
+		}
+
+		maintenances, err := s.maintenanceRepo.WithTx(tx).GetOverlapping(hallID, startTime, startTime.Add(movie.Runtime()))
+		if err != nil {
+			return err
+		}
+		if len(maintenances) != 0 {
+			return ErrHallUnderMaintenance
 		}
+
 		return s.repo.WithTx(tx).Create(showtime)
 	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateCatalog(s.catalog, CatalogShowtimes)
+	return showtime, nil
+}
+
+// checkReferencesTx reports a movie or hall that doesn't exist as a field error,
+// rather than leaving it to the foreign keys, and returns the movie
+func (s *showtimeService) checkReferencesTx(tx *gorm.DB, movieID, hallID uint) (*model.Movie, error) {
+	v := &validator{}
+	movie, err := s.movieRepo.WithTx(tx).GetByID(movieID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		v.add("movie_id", RuleInvalid, "must be an existing movie")
+	}
+	if _, err := s.hallRepo.WithTx(tx).GetByID(hallID); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		v.add("hall_id", RuleInvalid, "must be an existing hall")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return movie, nil
 }
 
 func (s *showtimeService) GetShowtimeByID(showtimeID uint) (*model.Showtime, error) {
@@ -70,6 +141,10 @@ func (s *showtimeService) GetShowtimesByHallIDTx(tx *gorm.DB, hallID uint) ([]mo
 	return s.repo.WithTx(tx).GetByHallID(hallID)
 }
 
+func (s *showtimeService) GetShowtimesByCinemaID(cinemaID uint) ([]model.Showtime, error) {
+	return s.repo.GetByCinemaID(cinemaID)
+}
+
 func (s *showtimeService) GetAllShowtimes() ([]model.Showtime, error) {
 	return s.repo.ListAll()
 }
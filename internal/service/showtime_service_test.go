@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateShowtimeReportsInvalidInputPerField(t *testing.T) {
+	env := newTestEnv(t)
+	showtimes := env.showtimeService()
+	existing := env.createShowtime(t)
+
+	_, err := showtimes.CreateShowtime(SystemActor, 0, time.Time{}, 0)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "movie_id", Rule: RuleRequired, Message: "is required"},
+		{Field: "hall_id", Rule: RuleRequired, Message: "is required"},
+		{Field: "start_at", Rule: RuleRequired, Message: "is required"},
+	}, validationErr.Fields)
+
+	_, err = showtimes.CreateShowtime(SystemActor, existing.MovieID, time.Now().Add(-time.Hour), existing.HallID)
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{{Field: "start_at", Rule: RuleFuture, Message: "must be in the future"}},
+		validationErr.Fields)
+
+	// references that don't exist are invalid input, not missing resources
+	_, err = showtimes.CreateShowtime(SystemActor, existing.MovieID+1, time.Now().Add(time.Hour), existing.HallID+1)
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "movie_id", Rule: RuleInvalid, Message: "must be an existing movie"},
+		{Field: "hall_id", Rule: RuleInvalid, Message: "must be an existing hall"},
+	}, validationErr.Fields)
+}
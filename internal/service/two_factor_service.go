@@ -0,0 +1,372 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// two-factor policy
+const (
+	totpIssuer = "Movie Reservation"
+	totpPeriod = 30 * time.Second
+	// codes of the previous and next period are accepted for clock drift
+	totpSkew          = 1
+	recoveryCodeCount = 10
+	// failed codes of a user before verification is refused for twoFactorLockout
+	twoFactorAttemptThreshold = 5
+	twoFactorLockout          = 15 * time.Minute
+)
+
+var totpOpts = totp.ValidateOpts{
+	Period:    uint(totpPeriod / time.Second),
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// TwoFactorEnrollment is shown once to set up the authenticator app,
+// URL is the otpauth:// URI usually rendered as a QR code
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// TwoFactorService manages TOTP two-factor authentication. Users of the roles
+// it requires two factors for only get the permissions of their role
+// in sessions started with a verified code
+type TwoFactorService interface {
+	Required(role model.UserRole) bool
+	IsEnabled(userID uint) (bool, error)
+	// BeginEnrollment creates a secret, it is enforced after ConfirmEnrollment
+	BeginEnrollment(actor Actor, userID uint) (*TwoFactorEnrollment, error)
+	// ConfirmEnrollment checks a code of the authenticator, enables two factors
+	// and returns the recovery codes, which are not shown again
+	ConfirmEnrollment(actor Actor, userID uint, code string) ([]string, error)
+	// Verify accepts a TOTP code or an unused recovery code,
+	// it returns ErrInvalidTwoFactorCode or a *RetryAfterError after repeated failures
+	Verify(userID uint, code string) error
+	RegenerateRecoveryCodes(actor Actor, userID uint, code string) ([]string, error)
+	Disable(actor Actor, userID uint, code string) error
+	// Reset removes the enrollment of a user who lost the authenticator and the recovery codes
+	Reset(actor Actor, userID uint) error
+}
+
+type twoFactorService struct {
+	db            *gorm.DB
+	repo          repository.TwoFactorRepo
+	recoveryRepo  repository.RecoveryCodeRepo
+	userRepo      repository.UserRepo
+	box           *SecretBox
+	attempts      AttemptStore
+	requiredRoles map[model.UserRole]bool
+	now           func() time.Time
+}
+
+var _ TwoFactorService = (*twoFactorService)(nil)
+
+func NewTwoFactorService(db *gorm.DB, twoFactorRepo repository.TwoFactorRepo, recoveryCodeRepo repository.RecoveryCodeRepo,
+	userRepo repository.UserRepo, box *SecretBox, attempts AttemptStore, requiredRoles []model.UserRole) *twoFactorService {
+	required := make(map[model.UserRole]bool, len(requiredRoles))
+	for _, role := range requiredRoles {
+		required[role] = true
+	}
+	return &twoFactorService{
+		db:            db,
+		repo:          twoFactorRepo,
+		recoveryRepo:  recoveryCodeRepo,
+		userRepo:      userRepo,
+		box:           box,
+		attempts:      attempts,
+		requiredRoles: required,
+		now:           time.Now,
+	}
+}
+
+func (s *twoFactorService) Required(role model.UserRole) bool {
+	return s.requiredRoles[role]
+}
+
+func (s *twoFactorService) IsEnabled(userID uint) (bool, error) {
+	twoFactor, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.EnabledAt != nil, nil
+}
+
+func (s *twoFactorService) BeginEnrollment(actor Actor, userID uint) (*TwoFactorEnrollment, error) {
+	if err := authorizeSelf(actor, userID); err != nil {
+		return nil, err
+	}
+	var enrollment *TwoFactorEnrollment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.userRepo.WithTx(tx).GetByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		existing, err := s.repo.WithTx(tx).GetByUserID(userID)
+		if err == nil && existing.EnabledAt != nil {
+			return ErrAlreadyExists
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// an unconfirmed enrollment is replaced
+		if err := s.repo.WithTx(tx).DeleteByUserID(userID); err != nil {
+			return err
+		}
+
+		key, err := totp.Generate(totp.GenerateOpts{
+			Issuer:      totpIssuer,
+			AccountName: user.Name,
+			Period:      totpOpts.Period,
+			Digits:      totpOpts.Digits,
+			Algorithm:   totpOpts.Algorithm,
+		})
+		if err != nil {
+			return err
+		}
+		encryptedSecret, err := s.box.Seal(key.Secret())
+		if err != nil {
+			return err
+		}
+		if err := s.repo.WithTx(tx).Create(&model.TwoFactor{
+			UserID:          userID,
+			EncryptedSecret: encryptedSecret,
+		}); err != nil {
+			return err
+		}
+		enrollment = &TwoFactorEnrollment{
+			Secret: key.Secret(),
+			URL:    key.URL(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (s *twoFactorService) ConfirmEnrollment(actor Actor, userID uint, code string) ([]string, error) {
+	if err := authorizeSelf(actor, userID); err != nil {
+		return nil, err
+	}
+	var recoveryCodes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		twoFactor, err := s.repo.WithTx(tx).GetByUserID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTwoFactorNotEnabled
+			}
+			return err
+		}
+		if twoFactor.EnabledAt != nil {
+			return ErrAlreadyExists
+		}
+		ok, err := s.checkTOTPTx(tx, twoFactor, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+
+		enabledAt := s.now()
+		twoFactor.EnabledAt = &enabledAt
+		if err := s.repo.WithTx(tx).Update(twoFactor); err != nil {
+			return err
+		}
+		recoveryCodes, err = s.replaceRecoveryCodesTx(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (s *twoFactorService) Verify(userID uint, code string) error {
+	key := "2fa:failures:" + strconv.FormatUint(uint64(userID), 10)
+	record, err := s.attempts.Get(key)
+	if err != nil {
+		return err
+	}
+	if record.Failures >= twoFactorAttemptThreshold {
+		if wait := record.LastFailure.Add(twoFactorLockout).Sub(s.now()); wait > 0 {
+			return &RetryAfterError{Err: ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return s.verifyTx(tx, userID, code)
+	})
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		if _, err := s.attempts.RecordFailure(key, s.now(), twoFactorLockout); err != nil {
+			return err
+		}
+		return ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		return err
+	}
+	return s.attempts.Reset(key)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(actor Actor, userID uint, code string) ([]string, error) {
+	if err := authorizeSelf(actor, userID); err != nil {
+		return nil, err
+	}
+	var recoveryCodes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.verifyTx(tx, userID, code); err != nil {
+			return err
+		}
+		var err error
+		recoveryCodes, err = s.replaceRecoveryCodesTx(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+func (s *twoFactorService) Disable(actor Actor, userID uint, code string) error {
+	if err := authorizeSelf(actor, userID); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.verifyTx(tx, userID, code); err != nil {
+			return err
+		}
+		return s.deleteTx(tx, userID)
+	})
+}
+
+func (s *twoFactorService) Reset(actor Actor, userID uint) error {
+	if err := authorize(actor, model.PermManageUsers); err != nil {
+		return err
+	}
+	// an admin locked out of the second factor needs another admin
+	if actor.UserID == userID {
+		return ErrForbidden
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.deleteTx(tx, userID)
+	})
+}
+
+// verifyTx accepts a TOTP code or a recovery code of an enabled enrollment
+func (s *twoFactorService) verifyTx(tx *gorm.DB, userID uint, code string) error {
+	twoFactor, err := s.repo.WithTx(tx).GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if twoFactor.EnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.checkTOTPTx(tx, twoFactor, code)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	err = s.recoveryRepo.WithTx(tx).Use(userID, hashToken(normalizeRecoveryCode(code)), s.now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// checkTOTPTx reports whether the code is valid for a time step within the skew
+// that was not used before, and records the step
+func (s *twoFactorService) checkTOTPTx(tx *gorm.DB, twoFactor *model.TwoFactor, code string) (bool, error) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpOpts.Digits.Length() {
+		return false, nil
+	}
+	secret, err := s.box.Open(twoFactor.EncryptedSecret)
+	if err != nil {
+		return false, err
+	}
+
+	current := s.now().Unix() / int64(totpOpts.Period)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*int64(totpOpts.Period), 0), totpOpts)
+		if err != nil {
+			return false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+		if err := s.repo.WithTx(tx).UseStep(twoFactor.UserID, step); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// the code was already used
+				return false, nil
+			}
+			return false, err
+		}
+		twoFactor.LastUsedStep = step
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *twoFactorService) replaceRecoveryCodesTx(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := s.recoveryRepo.WithTx(tx).DeleteByUserID(userID); err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(b)[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		records = append(records, model.RecoveryCode{UserID: userID, CodeHash: hashToken(code)})
+	}
+	if err := s.recoveryRepo.WithTx(tx).CreateBatch(records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) deleteTx(tx *gorm.DB, userID uint) error {
+	if err := s.recoveryRepo.WithTx(tx).DeleteByUserID(userID); err != nil {
+		return err
+	}
+	return s.repo.WithTx(tx).DeleteByUserID(userID)
+}
+
+// normalizeRecoveryCode accepts codes typed with or without the dash and in any case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// twoFactorService requires two factors for admins
+func (e *testEnv) twoFactorService(t *testing.T) *twoFactorService {
+	t.Helper()
+	box, err := NewSecretBox(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	require.NoError(t, err)
+	return NewTwoFactorService(e.db, repository.NewTwoFactorRepoGorm(e.db), repository.NewRecoveryCodeRepoGorm(e.db),
+		e.users, box, NewMemoryAttemptStore(), []model.UserRole{model.RoleAdmin})
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := totp.GenerateCodeCustom(secret, at, totpOpts)
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorIsEnforcedAfterTheConfirmedEnrollment(t *testing.T) {
+	env := newTestEnv(t)
+	twoFactor := env.twoFactorService(t)
+	clock := time.Now()
+	twoFactor.now = func() time.Time { return clock }
+	admin := env.createUser(t, "root", model.RoleAdmin)
+	alice := env.createUser(t, "alice", model.RoleUser)
+
+	assert.True(t, twoFactor.Required(model.RoleAdmin))
+	assert.False(t, twoFactor.Required(model.RoleUser))
+
+	_, err := twoFactor.BeginEnrollment(ActorOf(alice), admin.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	enrollment, err := twoFactor.BeginEnrollment(ActorOf(admin), admin.ID)
+	require.NoError(t, err)
+	// not enforced before it is confirmed
+	enabled, err := twoFactor.IsEnabled(admin.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	assert.ErrorIs(t, twoFactor.Verify(admin.ID, totpCode(t, enrollment.Secret, clock)), ErrTwoFactorNotEnabled)
+
+	_, err = twoFactor.ConfirmEnrollment(ActorOf(admin), admin.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	recoveryCodes, err := twoFactor.ConfirmEnrollment(ActorOf(admin), admin.ID, totpCode(t, enrollment.Secret, clock))
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	enabled, err = twoFactor.IsEnabled(admin.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	_, err = twoFactor.BeginEnrollment(ActorOf(admin), admin.ID)
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+
+	// each code is used once, the next period brings a new one
+	assert.ErrorIs(t, twoFactor.Verify(admin.ID, totpCode(t, enrollment.Secret, clock)), ErrInvalidTwoFactorCode)
+	clock = clock.Add(totpPeriod)
+	assert.NoError(t, twoFactor.Verify(admin.ID, totpCode(t, enrollment.Secret, clock)))
+
+	// so is each recovery code, typed in any case
+	assert.NoError(t, twoFactor.Verify(admin.ID, " "+recoveryCodes[0]+" "))
+	assert.ErrorIs(t, twoFactor.Verify(admin.ID, recoveryCodes[0]), ErrInvalidTwoFactorCode)
+	assert.NoError(t, twoFactor.Verify(admin.ID, strings.ToUpper(recoveryCodes[1])))
+}
+
+func TestTwoFactorVerifyLocksAfterRepeatedFailures(t *testing.T) {
+	env := newTestEnv(t)
+	twoFactor := env.twoFactorService(t)
+	clock := time.Now()
+	twoFactor.now = func() time.Time { return clock }
+	admin := env.createUser(t, "root", model.RoleAdmin)
+	enrollment, err := twoFactor.BeginEnrollment(ActorOf(admin), admin.ID)
+	require.NoError(t, err)
+	_, err = twoFactor.ConfirmEnrollment(ActorOf(admin), admin.ID, totpCode(t, enrollment.Secret, clock))
+	require.NoError(t, err)
+
+	for range twoFactorAttemptThreshold {
+		assert.ErrorIs(t, twoFactor.Verify(admin.ID, "000000"), ErrInvalidTwoFactorCode)
+	}
+	clock = clock.Add(totpPeriod)
+	err = twoFactor.Verify(admin.ID, totpCode(t, enrollment.Secret, clock))
+	var retryAfter *RetryAfterError
+	require.ErrorAs(t, err, &retryAfter)
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+
+	clock = clock.Add(twoFactorLockout)
+	assert.NoError(t, twoFactor.Verify(admin.ID, totpCode(t, enrollment.Secret, clock)))
+}
+
+func TestTwoFactorResetNeedsAnotherAdmin(t *testing.T) {
+	env := newTestEnv(t)
+	twoFactor := env.twoFactorService(t)
+	admin := env.createUser(t, "root", model.RoleAdmin)
+	other := env.createUser(t, "ops", model.RoleAdmin)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	enrollment, err := twoFactor.BeginEnrollment(ActorOf(admin), admin.ID)
+	require.NoError(t, err)
+	_, err = twoFactor.ConfirmEnrollment(ActorOf(admin), admin.ID, totpCode(t, enrollment.Secret, time.Now()))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, twoFactor.Reset(ActorOf(alice), admin.ID), ErrForbidden)
+	assert.ErrorIs(t, twoFactor.Reset(ActorOf(admin), admin.ID), ErrForbidden)
+	require.NoError(t, twoFactor.Reset(ActorOf(other), admin.ID))
+	enabled, err := twoFactor.IsEnabled(admin.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+type UserService interface {
+	Register(name, password string, profile model.UserProfile, captcha CaptchaSolution) (*model.User, error)
+	Authenticate(name, password, clientIP string, captcha CaptchaSolution) (*model.User, error)
+	ChangePassword(actor Actor, userID uint, oldPassword, newPassword string) error
+	ResetPassword(actor Actor, userID uint, newPassword string) error
+	UpdateProfile(actor Actor, userID uint, profile model.UserProfile) (*model.User, error)
+	GetUserByID(id uint) (*model.User, error)
+	GetUserByName(name string) (*model.User, error)
+	DeleteUser(actor Actor, userID uint) error
+	AssignRole(actor Actor, userID uint, role model.UserRole) error
+	SetUserStatus(actor Actor, userID uint, status model.UserStatus) error
+	BootstrapAdmin(name, email string) (bool, error)
+	UnlockAccount(actor Actor, userID uint) error
+}
+
+// SessionRevoker invalidates every access and refresh token issued to a user
+// before, auth.SessionStore implements it
+type SessionRevoker interface {
+	BumpGeneration(userID uint) (int64, error)
+}
+
+type userService struct {
+	db              *gorm.DB
+	repo            repository.UserRepo
+	reservationRepo repository.ReservationRepo
+	loginThrottle   LoginThrottle
+	captcha         CaptchaService
+	sessions        SessionRevoker
+	events          SeatEvents
+}
+
+var _ UserService = (*userService)(nil)
+
+func NewUserService(db *gorm.DB, userRepo repository.UserRepo, reservationRepo repository.ReservationRepo,
+	loginThrottle LoginThrottle, captchaService CaptchaService, sessions SessionRevoker,
+	seatEvents SeatEvents) *userService {
+	return &userService{
+		db:              db,
+		repo:            userRepo,
+		reservationRepo: reservationRepo,
+		loginThrottle:   loginThrottle,
+		captcha:         captchaService,
+		sessions:        sessions,
+		events:          seatEvents,
+	}
+}
+
+// limits of a user
+const (
+	minUserNameLen        = 3
+	maxUserNameLen        = 64
+	minPasswordLen        = 8
+	maxPasswordBytes      = 72 // bcrypt ignores the bytes after 72
+	maxUserEmailLen       = 255
+	maxUserDisplayNameLen = 100
+	maxUserPhoneLen       = 32
+)
+
+// deletedUserNamePrefix names the deleted accounts, it can't be registered
+const deletedUserNamePrefix = "deleted-user-"
+
+// dummyPasswordHash is compared for unknown names, so they take as long as wrong passwords
+const dummyPasswordHash = "$2a$10$iou8HbiEWbu6sJPv9rxOWOeCaiTGuZC6lguqqQVzLM3JL4m3Cd75K"
+
+// Register creates an unverified user with RoleUser, the email is required
+// so the user can verify it and become active,
+// it requires a solved captcha when the service has a CaptchaService
+func (s *userService) Register(name, password string, profile model.UserProfile, captcha CaptchaSolution) (*model.User, error) {
+	name = strings.TrimSpace(name)
+	v := &validator{}
+	validateUserName(v, name)
+	validatePassword(v, "password", name, password)
+	validateProfile(v, &profile)
+	if profile.Email == "" {
+		v.add("email", RuleRequired, "must not be empty")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if s.captcha != nil {
+		if err := s.captcha.Verify(captcha); err != nil {
+			return nil, err
+		}
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	user := &model.User{
+		Name:           name,
+		HashedPassword: hashedPassword,
+		Role:           model.RoleUser,
+		Status:         model.UserUnverified,
+		Email:          profile.Email,
+		DisplayName:    profile.DisplayName,
+		Phone:          profile.Phone,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkNameUnusedTx(tx, name); err != nil {
+			return err
+		}
+		if err := s.checkEmailUnusedTx(tx, 0, user.Email); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).Create(user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Authenticate returns ErrInvalidCredential for an unknown name or a wrong password,
+// ErrUserSuspended for a suspended user, and a *RetryAfterError matching ErrAccountLocked or ErrTooManyAttempts
+// while the account or the client IP is throttled,
+// after a few failures of the account it also requires a solved captcha
+func (s *userService) Authenticate(name, password, clientIP string, captcha CaptchaSolution) (*model.User, error) {
+	name = strings.TrimSpace(name)
+	if err := s.loginThrottle.Check(name, clientIP); err != nil {
+		return nil, err
+	}
+	if s.captcha != nil {
+		required, err := s.loginThrottle.RequiresCaptcha(name)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			if err := s.captcha.Verify(captcha); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	user, err := s.repo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// unknown names are counted and hashed too, so they look like wrong passwords
+			_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+			return nil, s.loginFailed(name, clientIP)
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(password)); err != nil {
+		return nil, s.loginFailed(name, clientIP)
+	}
+	// only told once the password is right, so the status can't be probed
+	if user.Status == model.UserSuspended {
+		return nil, ErrUserSuspended
+	}
+
+	if err := s.loginThrottle.Reset(name); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) loginFailed(name, clientIP string) error {
+	if err := s.loginThrottle.RecordFailure(name, clientIP); err != nil {
+		return err
+	}
+	return ErrInvalidCredential
+}
+
+// UnlockAccount lifts the lockout and backoff of the account
+func (s *userService) UnlockAccount(actor Actor, userID uint) error {
+	if err := authorize(actor, model.PermManageUsers); err != nil {
+		return err
+	}
+	user, err := s.getUserTx(s.db, userID)
+	if err != nil {
+		return err
+	}
+	return s.loginThrottle.Reset(user.Name)
+}
+
+// ChangePassword needs the current password, even from admins,
+// every session of the user ends, whoever knew the old password is signed out.
+// Users may change their own password, others' require PermManageUsers
+func (s *userService) ChangePassword(actor Actor, userID uint, oldPassword, newPassword string) error {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(oldPassword)); err != nil {
+			return ErrInvalidCredential
+		}
+		if oldPassword == newPassword {
+			v := &validator{}
+			v.add("new_password", RuleInvalid, "must differ from the current password")
+			return v.err()
+		}
+		return s.setPasswordTx(tx, user, "new_password", newPassword)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.sessions.BumpGeneration(userID)
+	return err
+}
+
+// ResetPassword sets a new password without the old one and ends every session of the user,
+// callers are responsible for proving the user's identity.
+// Users may reset their own password, others' require PermManageUsers
+func (s *userService) ResetPassword(actor Actor, userID uint, newPassword string) error {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		return s.setPasswordTx(tx, user, "new_password", newPassword)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.sessions.BumpGeneration(userID)
+	return err
+}
+
+// UpdateProfile replaces the profile, a changed email has to be verified again.
+// Users may update their own profile, others' require PermManageUsers
+func (s *userService) UpdateProfile(actor Actor, userID uint, profile model.UserProfile) (*model.User, error) {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return nil, err
+	}
+	v := &validator{}
+	validateProfile(v, &profile)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		if profile.Email != user.Email {
+			if err := s.checkEmailUnusedTx(tx, userID, profile.Email); err != nil {
+				return err
+			}
+			// the new address has to be verified again
+			user.EmailVerifiedAt = nil
+		}
+		user.Email = profile.Email
+		user.DisplayName = profile.DisplayName
+		user.Phone = profile.Phone
+		return s.repo.WithTx(tx).Update(user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) GetUserByID(id uint) (*model.User, error) {
+	return s.getUserTx(s.db, id)
+}
+
+func (s *userService) GetUserByName(name string) (*model.User, error) {
+	user, err := s.repo.GetByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser cancels the user's reservations for upcoming showtimes,
+// then anonymizes and soft deletes the user and ends its sessions,
+// reservations of past showtimes are kept for the records.
+// Users may delete themselves, deleting others requires PermManageUsers
+func (s *userService) DeleteUser(actor Actor, userID uint) error {
+	if err := authorizeSelfOr(actor, userID, model.PermManageUsers); err != nil {
+		return err
+	}
+	var showtimeIDs []uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		upcoming, err := s.reservationRepo.WithTx(tx).GetUpcomingByUserID(userID, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, reservation := range upcoming {
+			if err := s.reservationRepo.WithTx(tx).DeleteByID(reservation.ID); err != nil {
+				return err
+			}
+			showtimeIDs = append(showtimeIDs, reservation.ShowtimeID)
+		}
+
+		// free the unique name and email for new accounts
+		user.Name = fmt.Sprintf("%s%d", deletedUserNamePrefix, user.ID)
+		user.HashedPassword = "!"
+		user.Email = ""
+		user.DisplayName = ""
+		user.Phone = ""
+		if err := s.repo.WithTx(tx).Update(user); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).DeleteByID(userID)
+	})
+	if err != nil {
+		return err
+	}
+	// watchers see the seats of the cancelled reservations free up
+	publishSeatEvents(s.events, showtimeIDs...)
+	_, err = s.sessions.BumpGeneration(userID)
+	return err
+}
+
+// AssignRole changes the role of a user and ends its sessions, actors can't change
+// their own role so the last admin can't lock everybody out by accident
+func (s *userService) AssignRole(actor Actor, userID uint, role model.UserRole) error {
+	if err := authorize(actor, model.PermManageUsers); err != nil {
+		return err
+	}
+	if !role.IsValid() {
+		v := &validator{}
+		v.add("role", RuleOneOf, "must be one of %v", model.Roles())
+		return v.err()
+	}
+	if actor.UserID == userID {
+		return ErrForbidden
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		user.Role = role
+		return s.repo.WithTx(tx).Update(user)
+	})
+	if err != nil {
+		return err
+	}
+	// the tokens carry the role, the user signs in again to get the new one
+	_, err = s.sessions.BumpGeneration(userID)
+	return err
+}
+
+// SetUserStatus suspends or reinstates a user and ends its sessions,
+// like roles actors can't change their own status
+func (s *userService) SetUserStatus(actor Actor, userID uint, status model.UserStatus) error {
+	if err := authorize(actor, model.PermManageUsers); err != nil {
+		return err
+	}
+	if !status.IsValid() {
+		v := &validator{}
+		v.add("status", RuleOneOf, "must be one of %v",
+			[]model.UserStatus{model.UserUnverified, model.UserActive, model.UserSuspended})
+		return v.err()
+	}
+	if actor.UserID == userID {
+		return ErrForbidden
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		user.Status = status
+		return s.repo.WithTx(tx).Update(user)
+	})
+	if err != nil {
+		return err
+	}
+	// tokens are not checked against the status, a suspended user is signed out
+	_, err = s.sessions.BumpGeneration(userID)
+	return err
+}
+
+// BootstrapAdmin promotes the named user to admin as long as no admin exists,
+// it is run at startup with the name and email set by the operator in the server config,
+// once there is an admin it does nothing and admins grant roles with AssignRole.
+// The user must have verified the email, names are freed when accounts are deleted
+// and anyone could register one, the operator's mailbox can't be taken that way.
+// It reports whether the user was promoted, ErrNotFound if there is no such user yet
+// and ErrUserNotVerified if the user hasn't verified the email
+func (s *userService) BootstrapAdmin(name, email string) (bool, error) {
+	promoted := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		admins, err := s.repo.WithTx(tx).CountByRole(model.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if admins != 0 {
+			return nil
+		}
+
+		user, err := s.repo.WithTx(tx).GetByName(name)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if user.EmailVerifiedAt == nil || !strings.EqualFold(user.Email, email) {
+			return ErrUserNotVerified
+		}
+		user.Role = model.RoleAdmin
+		if err := s.repo.WithTx(tx).Update(user); err != nil {
+			return err
+		}
+		promoted = true
+		return nil
+	})
+	return promoted, err
+}
+
+func (s *userService) getUserTx(tx *gorm.DB, userID uint) (*model.User, error) {
+	user, err := s.repo.WithTx(tx).GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) setPasswordTx(tx *gorm.DB, user *model.User, field, password string) error {
+	return setPasswordTx(s.repo.WithTx(tx), user, field, password)
+}
+
+// setPasswordTx validates the password against the policy, hashes and stores it
+func setPasswordTx(repo repository.UserRepo, user *model.User, field, password string) error {
+	v := &validator{}
+	validatePassword(v, field, user.Name, password)
+	if err := v.err(); err != nil {
+		return err
+	}
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.HashedPassword = hashedPassword
+	return repo.Update(user)
+}
+
+func (s *userService) checkNameUnusedTx(tx *gorm.DB, name string) error {
+	_, err := s.repo.WithTx(tx).GetByName(name)
+	if err == nil {
+		return ErrAlreadyExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// checkEmailUnusedTx returns ErrAlreadyExists if another user has the email
+func (s *userService) checkEmailUnusedTx(tx *gorm.DB, id uint, email string) error {
+	if email == "" {
+		return nil
+	}
+	anotherUser, err := s.repo.WithTx(tx).GetByEmail(email)
+	if err == nil && anotherUser != nil && anotherUser.ID != id {
+		return ErrAlreadyExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func validateUserName(v *validator, name string) {
+	if name == "" {
+		v.add("name", RuleRequired, "must not be empty")
+		return
+	}
+	if len(name) < minUserNameLen || len(name) > maxUserNameLen {
+		v.add("name", RuleRange, "must be between %d and %d characters", minUserNameLen, maxUserNameLen)
+	}
+	if strings.HasPrefix(strings.ToLower(name), deletedUserNamePrefix) {
+		v.add("name", RuleInvalid, "must not start with %q", deletedUserNamePrefix)
+	}
+	for _, r := range name {
+		if !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-", r))) {
+			v.add("name", RuleInvalid, "may only contain letters, digits, '_', '.' and '-'")
+			return
+		}
+	}
+}
+
+// validatePassword applies the password policy:
+// 8 to 72 bytes, at least one letter and one digit, different from the user name
+func validatePassword(v *validator, field, name, password string) {
+	if len(password) < minPasswordLen || len(password) > maxPasswordBytes {
+		v.add(field, RuleRange, "must be between %d and %d bytes", minPasswordLen, maxPasswordBytes)
+		return
+	}
+	hasLetter := strings.IndexFunc(password, unicode.IsLetter) >= 0
+	hasDigit := strings.IndexFunc(password, unicode.IsDigit) >= 0
+	if !hasLetter || !hasDigit {
+		v.add(field, RulePasswordPolicy, "must contain at least one letter and one digit")
+	}
+	if strings.EqualFold(password, name) {
+		v.add(field, RulePasswordPolicy, "must differ from the user name")
+	}
+}
+
+// validateProfile trims the profile and validates it, every field is optional
+func validateProfile(v *validator, profile *model.UserProfile) {
+	profile.Email = strings.ToLower(strings.TrimSpace(profile.Email))
+	profile.DisplayName = strings.TrimSpace(profile.DisplayName)
+	profile.Phone = strings.TrimSpace(profile.Phone)
+
+	if profile.Email != "" {
+		v.maxLen("email", profile.Email, maxUserEmailLen)
+		if address, err := mail.ParseAddress(profile.Email); err != nil || address.Address != profile.Email {
+			v.add("email", RuleInvalid, "must be a valid email address")
+		}
+	}
+	v.maxLen("display_name", profile.DisplayName, maxUserDisplayNameLen)
+	if profile.Phone != "" {
+		v.maxLen("phone", profile.Phone, maxUserPhoneLen)
+		if strings.IndexFunc(profile.Phone, func(r rune) bool {
+			return !unicode.IsDigit(r) && !strings.ContainsRune("+-() ", r)
+		}) >= 0 {
+			v.add("phone", RuleInvalid, "may only contain digits, spaces and '+-()'")
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func TestAuthenticateLocksTheAccountAfterRepeatedFailures(t *testing.T) {
+	env := newTestEnv(t)
+	throttle := NewLoginThrottle(NewMemoryAttemptStore())
+	clock := time.Now()
+	throttle.now = func() time.Time { return clock }
+	users := NewUserService(env.db, env.users, env.reservations, throttle, nil, env.sessions, nil)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+
+	// lock fails logins until the account is locked, waiting out each backoff
+	lock := func() {
+		t.Helper()
+		for range loginLockoutThreshold {
+			clock = clock.Add(loginMaxBackoff)
+			_, err := users.Authenticate("alice", "wrong", "192.0.2.1", CaptchaSolution{})
+			require.ErrorIs(t, err, ErrInvalidCredential)
+		}
+	}
+
+	for range loginBackoffThreshold {
+		_, err := users.Authenticate("alice", "wrong", "192.0.2.1", CaptchaSolution{})
+		require.ErrorIs(t, err, ErrInvalidCredential)
+	}
+	// too soon after the last failure, even with the right password
+	_, err := users.Authenticate("alice", testPassword, "192.0.2.1", CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+	require.NoError(t, throttle.Reset("alice"))
+
+	lock()
+	clock = clock.Add(loginMaxBackoff)
+	_, err = users.Authenticate("Alice ", testPassword, "192.0.2.2", CaptchaSolution{})
+	var retryAfter *RetryAfterError
+	require.ErrorAs(t, err, &retryAfter)
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.Equal(t, loginLockoutDuration-loginMaxBackoff, retryAfter.RetryAfter)
+	// other accounts are not affected
+	_, err = users.Authenticate("bob", testPassword, "192.0.2.1", CaptchaSolution{})
+	assert.NoError(t, err)
+
+	// an admin unlocks it
+	assert.ErrorIs(t, users.UnlockAccount(ActorOf(bob), alice.ID), ErrForbidden)
+	require.NoError(t, users.UnlockAccount(SystemActor, alice.ID))
+	user, err := users.Authenticate("alice", testPassword, "192.0.2.1", CaptchaSolution{})
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, user.ID)
+
+	// or the lockout expires
+	lock()
+	clock = clock.Add(loginLockoutDuration)
+	_, err = users.Authenticate("alice", testPassword, "192.0.2.1", CaptchaSolution{})
+	assert.NoError(t, err)
+}
+
+func TestPasswordChangesEndEverySession(t *testing.T) {
+	env := newTestEnv(t)
+	users := env.userService()
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+
+	assert.ErrorIs(t, users.ChangePassword(ActorOf(alice), alice.ID, "wrong", "New-Password-1"), ErrInvalidCredential)
+	assert.ErrorIs(t, users.ChangePassword(ActorOf(bob), alice.ID, testPassword, "New-Password-1"), ErrForbidden)
+	assert.Zero(t, env.sessions.generation(alice.ID))
+
+	require.NoError(t, users.ChangePassword(ActorOf(alice), alice.ID, testPassword, "New-Password-1"))
+	assert.EqualValues(t, 1, env.sessions.generation(alice.ID))
+	_, err := users.Authenticate("alice", "New-Password-1", "192.0.2.1", CaptchaSolution{})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, users.ResetPassword(ActorOf(bob), alice.ID, "New-Password-2"), ErrForbidden)
+	require.NoError(t, users.ResetPassword(SystemActor, alice.ID, "New-Password-2"))
+	assert.EqualValues(t, 2, env.sessions.generation(alice.ID))
+	_, err = users.Authenticate("alice", "New-Password-1", "192.0.2.1", CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	assert.Zero(t, env.sessions.generation(bob.ID))
+}
+
+func TestDeleteUserFreesTheUpcomingSeatsAndTheName(t *testing.T) {
+	env := newTestEnv(t)
+	events := NewMemorySeatEvents()
+	users := NewUserService(env.db, env.users, env.reservations, NewLoginThrottle(NewMemoryAttemptStore()), nil,
+		env.sessions, events)
+	reservations := env.reservationService(nil)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+	_, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watched, err := events.Subscribe(ctx, showtime.ID)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, users.DeleteUser(ActorOf(bob), alice.ID), ErrForbidden)
+	require.NoError(t, users.DeleteUser(ActorOf(alice), alice.ID))
+	assert.Equal(t, SeatEvent{ShowtimeID: showtime.ID}, <-watched)
+	assert.EqualValues(t, 1, env.sessions.generation(alice.ID))
+	owned, err := reservations.GetReservationsByUserID(SystemActor, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, owned)
+
+	// the name of the deleted account can't be registered, so the next deletion can use its own
+	_, err = users.Register(fmt.Sprintf("Deleted-User-%d", bob.ID), testPassword,
+		model.UserProfile{Email: "mallory@example.com"}, CaptchaSolution{})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "name", validationErr.Fields[0].Field)
+	require.NoError(t, users.DeleteUser(SystemActor, bob.ID))
+
+	// the old name and email are free again
+	_, err = users.Register("alice", testPassword, model.UserProfile{Email: "alice@example.com"}, CaptchaSolution{})
+	assert.NoError(t, err)
+}
+
+func TestAuthenticateHashesForUnknownNamesToo(t *testing.T) {
+	// an unknown name costs as much as a wrong password
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+
+	env := newTestEnv(t)
+	_, err = env.userService().Authenticate("nobody", testPassword, "192.0.2.1", CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrInvalidCredential)
+	// and the identity service doesn't hand out the reserved names
+	assert.Equal(t, "userdeleted-user-3", sanitizeUserName("deleted-user-3"))
+}
+
+func TestRoleAndStatusChangesEndEverySession(t *testing.T) {
+	env := newTestEnv(t)
+	users := env.userService()
+	admin := env.createUser(t, "root", model.RoleAdmin)
+	alice := env.createUser(t, "alice", model.RoleAdmin)
+
+	assert.ErrorIs(t, users.AssignRole(ActorOf(admin), admin.ID, model.RoleUser), ErrForbidden)
+	assert.ErrorIs(t, users.AssignRole(ActorOf(admin), alice.ID, "admins"), ErrInvalidInput)
+	assert.Zero(t, env.sessions.generation(alice.ID))
+
+	// a demoted admin keeps no token with the old role
+	require.NoError(t, users.AssignRole(ActorOf(admin), alice.ID, model.RoleUser))
+	assert.EqualValues(t, 1, env.sessions.generation(alice.ID))
+	// a suspended user keeps no token at all
+	require.NoError(t, users.SetUserStatus(ActorOf(admin), alice.ID, model.UserSuspended))
+	assert.EqualValues(t, 2, env.sessions.generation(alice.ID))
+	_, err := users.Authenticate("alice", testPassword, "192.0.2.1", CaptchaSolution{})
+	assert.ErrorIs(t, err, ErrUserSuspended)
+	assert.Zero(t, env.sessions.generation(admin.ID))
+}
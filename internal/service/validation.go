@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidInput is matched by every *ValidationError,
+// so callers can use errors.Is(err, ErrInvalidInput)
+var ErrInvalidInput = NewError(CodeInvalidInput, "invalid input")
+
+// rules reported in FieldError.Rule
+const (
+	RuleRequired = "required"
+	RuleMaxLen   = "max_len"
+	RuleRange    = "range"
+	RuleMismatch = "mismatch"
+	RuleUnique   = "unique"
+	RuleOneOf    = "one_of"
+	RuleFuture   = "future"
+	RuleInvalid  = "invalid"
+
+	RulePasswordPolicy = "password_policy"
+)
+
+// FieldError describes why one input field is rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries every FieldError found in one input
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// validator collects field errors, err() returns nil if there are none
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field, rule, format string, args ...any) {
+	v.fields = append(v.fields, FieldError{
+		Field:   field,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// text checks a trimmed string is non-empty and at most maxLen characters
+func (v *validator) text(field, value string, maxLen int) {
+	if value == "" {
+		v.add(field, RuleRequired, "must not be empty")
+		return
+	}
+	v.maxLen(field, value, maxLen)
+}
+
+func (v *validator) maxLen(field, value string, maxLen int) {
+	if utf8.RuneCountInString(value) > maxLen {
+		v.add(field, RuleMaxLen, "must be at most %d characters", maxLen)
+	}
+}
+
+func (v *validator) between(field string, value, min, max int) {
+	if value < min || value > max {
+		v.add(field, RuleRange, "must be between %d and %d", min, max)
+	}
+}
+
+func (v *validator) requiredID(field string, id uint) {
+	if id == 0 {
+		v.add(field, RuleRequired, "is required")
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorCollectsEveryFieldError(t *testing.T) {
+	v := &validator{}
+	require.NoError(t, v.err())
+
+	v.text("title", "", 10)
+	v.text("name", "ok", 10)
+	v.maxLen("description", strings.Repeat("é", 11), 10)
+	v.maxLen("note", strings.Repeat("é", 10), 10)
+	v.between("runtime_minutes", 601, 0, 600)
+	v.between("rows", 1, 1, 100)
+	v.requiredID("hall_id", 0)
+	v.requiredID("movie_id", 7)
+
+	err := v.err()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "title", Rule: RuleRequired, Message: "must not be empty"},
+		{Field: "description", Rule: RuleMaxLen, Message: "must be at most 10 characters"},
+		{Field: "runtime_minutes", Rule: RuleRange, Message: "must be between 0 and 600"},
+		{Field: "hall_id", Rule: RuleRequired, Message: "is required"},
+	}, validationErr.Fields)
+	assert.Equal(t, "invalid input: title: must not be empty; description: must be at most 10 characters; "+
+		"runtime_minutes: must be between 0 and 600; hall_id: is required", err.Error())
+}
+
+func TestValidationErrorMatchesErrInvalidInput(t *testing.T) {
+	var err error = &ValidationError{Fields: []FieldError{{Field: "name", Rule: RuleRequired, Message: "x"}}}
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorIs(t, fmt.Errorf("create hall: %w", err), ErrInvalidInput)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
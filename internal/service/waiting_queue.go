@@ -0,0 +1,260 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qs-lzh/movie-reservation/internal/cache"
+)
+
+// QueueStatus is the place of a member in the queue of a waiting room
+type QueueStatus struct {
+	Admitted bool
+	// Position counts from 1 at the head of the queue, 0 once admitted
+	Position int
+	Length   int
+	// AdmittedAt is when the member was admitted, its admission ends rate.Admission later
+	AdmittedAt time.Time
+}
+
+// QueueRate admits Burst members at once, and PerSecond more every second
+type QueueRate struct {
+	PerSecond float64
+	Burst     int
+	// Admission is how long an admitted member stays admitted
+	Admission time.Duration
+}
+
+// queueStaleAfter drops the members that stopped polling, they lose their place
+const queueStaleAfter = time.Minute
+
+// WaitingQueue keeps the queues of the waiting rooms in the order members joined
+type WaitingQueue interface {
+	// Poll adds member at the end of the queue unless it is queued or admitted already,
+	// admits the members at the head of the queue as far as rate allows
+	// and returns the status of member
+	Poll(room string, member string, rate QueueRate) (QueueStatus, error)
+	// Delete drops the queue of a closed room
+	Delete(room string) error
+}
+
+type memoryWaitingQueue struct {
+	mu     sync.Mutex
+	queues map[string]*memoryQueue
+}
+
+type memoryQueue struct {
+	// members in the order they joined
+	members   []string
+	seen      map[string]time.Time
+	admitted  map[string]time.Time
+	tokens    float64
+	updatedAt time.Time
+}
+
+var _ WaitingQueue = (*memoryWaitingQueue)(nil)
+
+// NewMemoryWaitingQueue keeps the queues in process,
+// for single node deployments, tests and as fallback of the redis queue
+func NewMemoryWaitingQueue() *memoryWaitingQueue {
+	return &memoryWaitingQueue{
+		queues: make(map[string]*memoryQueue),
+	}
+}
+
+func (q *memoryWaitingQueue) Poll(room string, member string, rate QueueRate) (QueueStatus, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	queue, ok := q.queues[room]
+	if !ok {
+		queue = &memoryQueue{
+			seen:      make(map[string]time.Time),
+			admitted:  make(map[string]time.Time),
+			tokens:    float64(rate.Burst),
+			updatedAt: now,
+		}
+		q.queues[room] = queue
+	}
+
+	queue.members = slices.DeleteFunc(queue.members, func(m string) bool {
+		if now.Sub(queue.seen[m]) < queueStaleAfter {
+			return false
+		}
+		delete(queue.seen, m)
+		return true
+	})
+	for m, at := range queue.admitted {
+		if now.Sub(at) >= rate.Admission {
+			delete(queue.admitted, m)
+		}
+	}
+	if _, ok := queue.admitted[member]; !ok {
+		if _, ok := queue.seen[member]; !ok {
+			queue.members = append(queue.members, member)
+		}
+		queue.seen[member] = now
+	}
+
+	queue.tokens = math.Min(float64(rate.Burst), queue.tokens+now.Sub(queue.updatedAt).Seconds()*rate.PerSecond)
+	queue.updatedAt = now
+	for queue.tokens >= 1 && len(queue.members) > 0 {
+		head := queue.members[0]
+		queue.members = queue.members[1:]
+		delete(queue.seen, head)
+		queue.admitted[head] = now
+		queue.tokens--
+	}
+
+	if admittedAt, ok := queue.admitted[member]; ok {
+		return QueueStatus{Admitted: true, Length: len(queue.members), AdmittedAt: admittedAt}, nil
+	}
+	return QueueStatus{
+		Position: slices.Index(queue.members, member) + 1,
+		Length:   len(queue.members),
+	}, nil
+}
+
+func (q *memoryWaitingQueue) Delete(room string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.queues, room)
+	return nil
+}
+
+// waitingQueueScript is Poll of memoryWaitingQueue on redis. KEYS are the queue, ordered by
+// the sequence of joining, the last poll of the queued members, the admission time
+// of the admitted members and the token bucket of the room. It reads the clock of redis,
+// so every API instance sees the same time
+var waitingQueueScript = cache.NewScript(`
+local member = ARGV[1]
+local rate = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local stale = tonumber(ARGV[4])
+local admission = tonumber(ARGV[5])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+for _, gone in ipairs(redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", now - stale)) do
+	redis.call("ZREM", KEYS[1], gone)
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now - stale)
+redis.call("ZREMRANGEBYSCORE", KEYS[3], "-inf", now - admission)
+if not redis.call("ZSCORE", KEYS[3], member) then
+	if not redis.call("ZSCORE", KEYS[1], member) then
+		redis.call("ZADD", KEYS[1], redis.call("HINCRBY", KEYS[4], "sequence", 1), member)
+	end
+	redis.call("ZADD", KEYS[2], now, member)
+end
+
+local bucket = redis.call("HMGET", KEYS[4], "tokens", "updated_at")
+local tokens = tonumber(bucket[1])
+local updated_at = tonumber(bucket[2])
+if tokens == nil or updated_at == nil then
+	tokens = burst
+	updated_at = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - updated_at) * rate)
+while tokens >= 1 do
+	local head = redis.call("ZPOPMIN", KEYS[1])
+	if #head == 0 then
+		break
+	end
+	redis.call("ZREM", KEYS[2], head[1])
+	redis.call("ZADD", KEYS[3], now, head[1])
+	tokens = tokens - 1
+end
+redis.call("HSET", KEYS[4], "tokens", tostring(tokens), "updated_at", tostring(now))
+
+-- the queue of a room nobody polls anymore expires
+local ttl = math.ceil(math.max(stale, admission)) + 60
+for _, key in ipairs(KEYS) do
+	redis.call("EXPIRE", key, ttl)
+end
+
+local length = redis.call("ZCARD", KEYS[1])
+local admitted_at = redis.call("ZSCORE", KEYS[3], member)
+if admitted_at then
+	return {1, 0, length, admitted_at}
+end
+return {0, redis.call("ZRANK", KEYS[1], member) + 1, length, "0"}
+`)
+
+type redisWaitingQueue struct {
+	cache    cache.Store
+	fallback WaitingQueue
+}
+
+var _ WaitingQueue = (*redisWaitingQueue)(nil)
+
+// NewRedisWaitingQueue shares the queues between API instances in sorted sets,
+// when redis fails the fallback queue is used so each instance still admits at the rate
+func NewRedisWaitingQueue(cache cache.Store, fallback WaitingQueue) *redisWaitingQueue {
+	return &redisWaitingQueue{
+		cache:    cache,
+		fallback: fallback,
+	}
+}
+
+// waitingQueueKeys are the keys of the script, the hash tag keeps them
+// in the same slot of a redis cluster
+func waitingQueueKeys(room string) []string {
+	prefix := "{waiting-room:" + room + "}:"
+	return []string{prefix + "queue", prefix + "seen", prefix + "admitted", prefix + "state"}
+}
+
+func (q *redisWaitingQueue) Poll(room string, member string, rate QueueRate) (QueueStatus, error) {
+	result, err := q.cache.Run(waitingQueueScript, waitingQueueKeys(room), member,
+		strconv.FormatFloat(rate.PerSecond, 'f', -1, 64), rate.Burst,
+		queueStaleAfter.Seconds(), rate.Admission.Seconds())
+	if err != nil {
+		return q.fallback.Poll(room, member, rate)
+	}
+	return parseWaitingQueueResult(result)
+}
+
+func parseWaitingQueueResult(result any) (QueueStatus, error) {
+	errUnexpected := fmt.Errorf("unexpected result of the waiting queue script: %v", result)
+	values, ok := result.([]any)
+	if !ok || len(values) != 4 {
+		return QueueStatus{}, errUnexpected
+	}
+	var numbers [3]int64
+	for i := range numbers {
+		if numbers[i], ok = values[i].(int64); !ok {
+			return QueueStatus{}, errUnexpected
+		}
+	}
+	admittedAt, ok := values[3].(string)
+	if !ok {
+		return QueueStatus{}, errUnexpected
+	}
+	seconds, err := strconv.ParseFloat(admittedAt, 64)
+	if err != nil {
+		return QueueStatus{}, errUnexpected
+	}
+	status := QueueStatus{
+		Admitted: numbers[0] == 1,
+		Position: int(numbers[1]),
+		Length:   int(numbers[2]),
+	}
+	if status.Admitted {
+		status.AdmittedAt = time.Unix(0, int64(seconds*float64(time.Second)))
+	}
+	return status, nil
+}
+
+func (q *redisWaitingQueue) Delete(room string) error {
+	if err := q.fallback.Delete(room); err != nil {
+		return err
+	}
+	// the keys expire by themselves once nobody polls them
+	for _, key := range waitingQueueKeys(room) {
+		_, _ = q.cache.Delete(key)
+	}
+	return nil
+}
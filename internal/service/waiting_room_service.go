@@ -0,0 +1,309 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+	"github.com/qs-lzh/movie-reservation/internal/repository"
+)
+
+// WaitingRoomService queues the customers of high-demand showtimes and admits them
+// at the rate of the room, ReservationService only books the showtimes of an open room
+// with an admission token
+type WaitingRoomService interface {
+	OpenWaitingRoom(actor Actor, room *model.WaitingRoom) error
+	CloseWaitingRoom(actor Actor, roomID uint) error
+	GetWaitingRooms() ([]model.WaitingRoom, error)
+	GetWaitingRoomByID(roomID uint) (*model.WaitingRoom, error)
+	// Join queues the actor in the room, signed-in users always get the same ticket,
+	// anonymous callers a new one they poll with, a few per room and client IP,
+	// then a *RetryAfterError matching ErrRateLimited
+	Join(actor Actor, roomID uint, clientIP string) (*QueueTicket, error)
+	// Poll keeps the place of the ticket in the queue and issues
+	// the admission token once it is admitted, tickets are dropped when not polled
+	Poll(actor Actor, roomID uint, ticket string) (*QueueTicket, error)
+	// CheckAdmission returns nil if the showtime has no open room,
+	// otherwise ErrAdmissionRequired or ErrAdmissionInvalid unless the token admits
+	// the ticket of userID, or the ticket it was issued to for a guest booking with userID 0.
+	// It is checked before the booking starts, UseAdmissionTx books with it
+	CheckAdmission(showtimeID, userID uint, admissionToken string) error
+	// UseAdmissionTx checks the admission again within the booking transaction
+	// and uses it up, an admission books once, later bookings with it get ErrAdmissionInvalid
+	UseAdmissionTx(tx *gorm.DB, showtimeID, userID uint, admissionToken string) error
+}
+
+// QueueTicket is the place of a customer in a waiting room
+type QueueTicket struct {
+	RoomID uint
+	Ticket string
+	// Position counts from 1 at the head of the queue, 0 once admitted
+	Position    int
+	QueueLength int
+	// AdmissionToken is set once the ticket is admitted, it is valid until AdmissionExpiresAt
+	AdmissionToken     string
+	AdmissionExpiresAt time.Time
+}
+
+const (
+	defaultAdmissionMinutes = 10
+	maxAdmissionMinutes     = 120
+	maxAdmitPerMinute       = 100000
+	userTicketPrefix        = "user-"
+	guestTicketPrefix       = "guest-"
+)
+
+// guestTicketLimit bounds the anonymous tickets of a client IP in a room,
+// so one client can't fill the queue ahead of everyone else
+var guestTicketLimit = RateLimit{Requests: 5, Per: 10 * time.Minute, Burst: 5}
+
+type waitingRoomService struct {
+	db           *gorm.DB
+	repo         repository.WaitingRoomRepo
+	showtimeRepo repository.ShowtimeRepo
+	movieRepo    repository.MovieRepo
+	queue        WaitingQueue
+	tokens       *AdmissionTokens
+	limiter      RateLimiter
+}
+
+var _ WaitingRoomService = (*waitingRoomService)(nil)
+
+func NewWaitingRoomService(db *gorm.DB, waitingRoomRepo repository.WaitingRoomRepo,
+	showtimeRepo repository.ShowtimeRepo, movieRepo repository.MovieRepo,
+	queue WaitingQueue, tokens *AdmissionTokens, limiter RateLimiter) *waitingRoomService {
+	return &waitingRoomService{
+		db:           db,
+		repo:         waitingRoomRepo,
+		showtimeRepo: showtimeRepo,
+		movieRepo:    movieRepo,
+		queue:        queue,
+		tokens:       tokens,
+		limiter:      limiter,
+	}
+}
+
+// OpenWaitingRoom opens a room for room.ShowtimeID or room.MovieID,
+// a showtime or movie has at most one room
+func (s *waitingRoomService) OpenWaitingRoom(actor Actor, room *model.WaitingRoom) error {
+	if err := authorize(actor, model.PermManageShowtimes); err != nil {
+		return err
+	}
+	if room.AdmissionMinutes == 0 {
+		room.AdmissionMinutes = defaultAdmissionMinutes
+	}
+	v := &validator{}
+	if (room.ShowtimeID == nil) == (room.MovieID == nil) {
+		v.add("showtime_id", RuleInvalid, "exactly one of showtime_id and movie_id is required")
+	}
+	v.between("admit_per_minute", room.AdmitPerMinute, 1, maxAdmitPerMinute)
+	v.between("admission_minutes", room.AdmissionMinutes, 1, maxAdmissionMinutes)
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var rooms []model.WaitingRoom
+		if room.ShowtimeID != nil {
+			if _, err := s.showtimeRepo.WithTx(tx).GetByID(*room.ShowtimeID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrShowtimeNotExist
+				}
+				return err
+			}
+			found, err := s.repo.WithTx(tx).GetForShowtime(*room.ShowtimeID, 0)
+			if err != nil {
+				return err
+			}
+			rooms = found
+		} else {
+			if _, err := s.movieRepo.WithTx(tx).GetByID(*room.MovieID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrNotFound
+				}
+				return err
+			}
+			found, err := s.repo.WithTx(tx).GetForShowtime(0, *room.MovieID)
+			if err != nil {
+				return err
+			}
+			rooms = found
+		}
+		if len(rooms) > 0 {
+			return ErrAlreadyExists.WithDetail("id", rooms[0].ID)
+		}
+		return s.repo.WithTx(tx).Create(room)
+	})
+}
+
+func (s *waitingRoomService) CloseWaitingRoom(actor Actor, roomID uint) error {
+	if err := authorize(actor, model.PermManageShowtimes); err != nil {
+		return err
+	}
+	if _, err := s.GetWaitingRoomByID(roomID); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).DeleteUses(roomID); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).DeleteByID(roomID)
+	})
+	if err != nil {
+		return err
+	}
+	return s.queue.Delete(roomKey(roomID))
+}
+
+func (s *waitingRoomService) GetWaitingRooms() ([]model.WaitingRoom, error) {
+	return s.repo.GetAll()
+}
+
+func (s *waitingRoomService) GetWaitingRoomByID(roomID uint) (*model.WaitingRoom, error) {
+	room, err := s.repo.GetByID(roomID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return room, nil
+}
+
+func (s *waitingRoomService) Join(actor Actor, roomID uint, clientIP string) (*QueueTicket, error) {
+	ticket := userTicketPrefix + strconv.FormatUint(uint64(actor.UserID), 10)
+	if actor.UserID == 0 {
+		decision, err := s.limiter.Allow(fmt.Sprintf("waiting-room-tickets:%d:%s", roomID, clientIP), guestTicketLimit)
+		if err != nil {
+			return nil, err
+		}
+		if !decision.Allowed {
+			return nil, &RetryAfterError{Err: ErrRateLimited, RetryAfter: decision.RetryAfter}
+		}
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		ticket = guestTicketPrefix + hex.EncodeToString(random)
+	}
+	return s.poll(roomID, ticket)
+}
+
+func (s *waitingRoomService) Poll(actor Actor, roomID uint, ticket string) (*QueueTicket, error) {
+	if userID, ok := strings.CutPrefix(ticket, userTicketPrefix); ok {
+		// the ticket of a user is predictable, only the user may poll it
+		if actor.UserID == 0 || userID != strconv.FormatUint(uint64(actor.UserID), 10) {
+			return nil, ErrForbidden
+		}
+	} else if !strings.HasPrefix(ticket, guestTicketPrefix) {
+		return nil, ErrNotFound
+	}
+	return s.poll(roomID, ticket)
+}
+
+func (s *waitingRoomService) poll(roomID uint, ticket string) (*QueueTicket, error) {
+	room, err := s.GetWaitingRoomByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	admission := time.Duration(room.AdmissionMinutes) * time.Minute
+	status, err := s.queue.Poll(roomKey(roomID), ticket, QueueRate{
+		PerSecond: float64(room.AdmitPerMinute) / 60,
+		Burst:     room.AdmitPerMinute,
+		Admission: admission,
+	})
+	if err != nil {
+		return nil, err
+	}
+	queueTicket := &QueueTicket{
+		RoomID:      roomID,
+		Ticket:      ticket,
+		Position:    status.Position,
+		QueueLength: status.Length,
+	}
+	if status.Admitted {
+		queueTicket.AdmissionExpiresAt = status.AdmittedAt.Add(admission)
+		queueTicket.AdmissionToken = s.tokens.Sign(AdmissionClaims{
+			RoomID:    roomID,
+			Subject:   ticket,
+			ExpiresAt: queueTicket.AdmissionExpiresAt,
+		})
+	}
+	return queueTicket, nil
+}
+
+func (s *waitingRoomService) CheckAdmission(showtimeID, userID uint, admissionToken string) error {
+	_, err := s.checkAdmissionTx(s.db, showtimeID, userID, admissionToken)
+	return err
+}
+
+func (s *waitingRoomService) UseAdmissionTx(tx *gorm.DB, showtimeID, userID uint, admissionToken string) error {
+	claims, err := s.checkAdmissionTx(tx, showtimeID, userID, admissionToken)
+	if err != nil || claims == nil {
+		return err
+	}
+	created, err := s.repo.WithTx(tx).CreateUse(&model.AdmissionUse{
+		WaitingRoomID: claims.RoomID,
+		Ticket:        claims.Subject,
+		ExpiresAt:     claims.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrAdmissionInvalid.WithDetail("waiting_room_id", claims.RoomID)
+	}
+	return nil
+}
+
+// checkAdmissionTx returns the claims of the token, or nil claims if the showtime
+// has no open room and needs no admission
+func (s *waitingRoomService) checkAdmissionTx(tx *gorm.DB, showtimeID, userID uint,
+	admissionToken string) (*AdmissionClaims, error) {
+	showtime, err := s.showtimeRepo.WithTx(tx).GetByID(showtimeID)
+	if err != nil {
+		// booking reports the missing showtime
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rooms, err := s.repo.WithTx(tx).GetForShowtime(showtime.ID, showtime.MovieID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rooms) == 0 {
+		return nil, nil
+	}
+	if admissionToken == "" {
+		return nil, ErrAdmissionRequired.WithDetail("waiting_room_id", rooms[0].ID)
+	}
+	claims, ok := s.tokens.Verify(admissionToken)
+	if !ok {
+		return nil, ErrAdmissionInvalid.WithDetail("waiting_room_id", rooms[0].ID)
+	}
+	// the token admits the ticket it was issued to, users book with their own ticket
+	if userID != 0 && claims.Subject != userTicketPrefix+strconv.FormatUint(uint64(userID), 10) {
+		return nil, ErrAdmissionInvalid.WithDetail("waiting_room_id", rooms[0].ID)
+	}
+	if userID == 0 && !strings.HasPrefix(claims.Subject, guestTicketPrefix) {
+		return nil, ErrAdmissionInvalid.WithDetail("waiting_room_id", rooms[0].ID)
+	}
+	for _, room := range rooms {
+		if room.ID == claims.RoomID {
+			return &claims, nil
+		}
+	}
+	return nil, ErrAdmissionInvalid.WithDetail("waiting_room_id", rooms[0].ID)
+}
+
+func roomKey(roomID uint) string {
+	return strconv.FormatUint(uint64(roomID), 10)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qs-lzh/movie-reservation/internal/model"
+)
+
+func (e *testEnv) waitingRoomService() *waitingRoomService {
+	return NewWaitingRoomService(e.db, e.waitingRooms, e.showtimes, e.movies,
+		NewRedisWaitingQueue(e.redis, NewMemoryWaitingQueue()), NewAdmissionTokens("admission-secret"),
+		NewRedisRateLimiter(e.redis, NewMemoryRateLimiter()))
+}
+
+func TestAdmissionTokensBookOnceForTheirTicket(t *testing.T) {
+	env := newTestEnv(t)
+	rooms := env.waitingRoomService()
+	reservations := env.reservationService(rooms)
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+	room := &model.WaitingRoom{ShowtimeID: &showtime.ID, AdmitPerMinute: 2}
+	require.NoError(t, rooms.OpenWaitingRoom(SystemActor, room))
+
+	// the room admits two at once, the third waits
+	aliceTicket, err := rooms.Join(ActorOf(alice), room.ID, "192.0.2.1")
+	require.NoError(t, err)
+	require.NotEmpty(t, aliceTicket.AdmissionToken)
+	guestTicket, err := rooms.Join(Actor{}, room.ID, "192.0.2.2")
+	require.NoError(t, err)
+	require.NotEmpty(t, guestTicket.AdmissionToken)
+	bobTicket, err := rooms.Join(ActorOf(bob), room.ID, "192.0.2.3")
+	require.NoError(t, err)
+	assert.Empty(t, bobTicket.AdmissionToken)
+	assert.Equal(t, 1, bobTicket.Position)
+
+	_, err = reservations.Reserve(ActorOf(bob), bob.ID, showtime.ID, 1, CaptchaSolution{}, "")
+	assert.ErrorIs(t, err, ErrAdmissionRequired)
+	_, err = reservations.Reserve(ActorOf(bob), bob.ID, showtime.ID, 1, CaptchaSolution{}, aliceTicket.AdmissionToken)
+	assert.ErrorIs(t, err, ErrAdmissionInvalid)
+	_, _, err = reservations.ReserveAsGuest(Actor{}, model.GuestContact{Email: "carol@example.com"}, showtime.ID, 1,
+		CaptchaSolution{}, aliceTicket.AdmissionToken)
+	assert.ErrorIs(t, err, ErrAdmissionInvalid)
+
+	reservation, err := reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{},
+		aliceTicket.AdmissionToken)
+	require.NoError(t, err)
+	require.NoError(t, reservations.CancelReservation(ActorOf(alice), reservation.ID))
+	_, err = reservations.Reserve(ActorOf(alice), alice.ID, showtime.ID, 1, CaptchaSolution{},
+		aliceTicket.AdmissionToken)
+	assert.ErrorIs(t, err, ErrAdmissionInvalid)
+
+	// a failed booking leaves the admission usable
+	_, _, err = reservations.ReserveAsGuest(Actor{}, model.GuestContact{Email: "carol@example.com"}, showtime.ID, 9,
+		CaptchaSolution{}, guestTicket.AdmissionToken)
+	assert.ErrorIs(t, err, ErrSeatNotExist)
+	_, _, err = reservations.ReserveAsGuest(Actor{}, model.GuestContact{Email: "carol@example.com"}, showtime.ID, 2,
+		CaptchaSolution{}, guestTicket.AdmissionToken)
+	assert.NoError(t, err)
+
+	// staff booking for others skips the room
+	boxOffice := env.createUser(t, "clerk", model.RoleBoxOffice)
+	_, err = reservations.Reserve(ActorOf(boxOffice), bob.ID, showtime.ID, 3, CaptchaSolution{}, "")
+	assert.NoError(t, err)
+}
+
+func TestWaitingRoomLimitsGuestTicketsPerClientIP(t *testing.T) {
+	env := newTestEnv(t)
+	rooms := env.waitingRoomService()
+	showtime := env.createShowtime(t)
+	room := &model.WaitingRoom{ShowtimeID: &showtime.ID, AdmitPerMinute: 60}
+	require.NoError(t, rooms.OpenWaitingRoom(SystemActor, room))
+
+	for range guestTicketLimit.Burst {
+		_, err := rooms.Join(Actor{}, room.ID, "198.51.100.7")
+		require.NoError(t, err)
+	}
+	_, err := rooms.Join(Actor{}, room.ID, "198.51.100.7")
+	var retryAfter *RetryAfterError
+	require.ErrorAs(t, err, &retryAfter)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Positive(t, retryAfter.RetryAfter)
+
+	_, err = rooms.Join(Actor{}, room.ID, "198.51.100.8")
+	assert.NoError(t, err)
+	// signed-in users keep their one ticket
+	alice := env.createUser(t, "alice", model.RoleUser)
+	for range guestTicketLimit.Burst + 1 {
+		_, err := rooms.Join(ActorOf(alice), room.ID, "198.51.100.7")
+		require.NoError(t, err)
+	}
+}
+
+func TestPollingTheTicketOfAnotherUserIsForbidden(t *testing.T) {
+	env := newTestEnv(t)
+	rooms := env.waitingRoomService()
+	showtime := env.createShowtime(t)
+	alice := env.createUser(t, "alice", model.RoleUser)
+	bob := env.createUser(t, "bob", model.RoleUser)
+	room := &model.WaitingRoom{ShowtimeID: &showtime.ID, AdmitPerMinute: 60}
+	require.NoError(t, rooms.OpenWaitingRoom(SystemActor, room))
+
+	ticket, err := rooms.Join(ActorOf(alice), room.ID, "192.0.2.1")
+	require.NoError(t, err)
+	_, err = rooms.Poll(ActorOf(bob), room.ID, ticket.Ticket)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = rooms.Poll(Actor{}, room.ID, ticket.Ticket)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = rooms.Poll(Actor{}, room.ID, "forged")
+	assert.ErrorIs(t, err, ErrNotFound)
+	polled, err := rooms.Poll(ActorOf(alice), room.ID, ticket.Ticket)
+	require.NoError(t, err)
+	assert.NotEmpty(t, polled.AdmissionToken)
+}